@@ -141,13 +141,33 @@ const (
 	// ConditionTypeAuthError describes an error condition related to Auth.
 	ConditionTypeAuthError = "AuthError"
 
+	// ConditionTypeBasicAuthError describes an error condition
+	// related to HTTP Basic authentication.
+	ConditionTypeBasicAuthError = "BasicAuthError"
+
 	// ConditionTypeCORSError describes an error condition related to CORS.
 	ConditionTypeCORSError = "CORSError"
 
+	// ConditionTypeHSTSError describes an error condition
+	// related to HTTP Strict Transport Security policies.
+	ConditionTypeHSTSError = "HSTSError"
+
 	// ConditionTypeIncludeError describes an error condition with
 	// inclusion of another HTTPProxy resource.
 	ConditionTypeIncludeError = "IncludeError"
 
+	// ConditionTypeIPFilterError describes an error condition
+	// related to IP filter policies.
+	ConditionTypeIPFilterError = "IPFilterError"
+
+	// ConditionTypeJWTVerificationError describes an error condition
+	// related to JWT verification.
+	ConditionTypeJWTVerificationError = "JWTVerificationError"
+
+	// ConditionTypeOAuth2Error describes an error condition
+	// related to the OAuth2 login flow.
+	ConditionTypeOAuth2Error = "OAuth2Error"
+
 	// ConditionTypeOrphanedError describes an error condition
 	// with an HTTPProxy resource which is not part of a delegation chain.
 	ConditionTypeOrphanedError = "Orphaned"
@@ -184,6 +204,10 @@ const (
 	// to TLS configuration.
 	ConditionTypeTLSError = "TLSError"
 
+	// ConditionTypeUDPProxyError describes an error condition relating
+	// to a UDP Proxy HTTPProxy resource.
+	ConditionTypeUDPProxyError = "UDPProxyError"
+
 	// ConditionTypeVirtualHostError describes an error condition relating
 	// to the VirtualHost configuration section of an HTTPProxy resource.
 	ConditionTypeVirtualHostError = "VirtualHostError"
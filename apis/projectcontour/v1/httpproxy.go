@@ -30,6 +30,10 @@ type HTTPProxySpec struct {
 	// TCPProxy holds TCP proxy information.
 	// +optional
 	TCPProxy *TCPProxy `json:"tcpproxy,omitempty"`
+	// UDPProxy holds UDP proxy information. It can only be used
+	// with a virtual host that binds to a UDP listener.
+	// +optional
+	UDPProxy *UDPProxy `json:"udpproxy,omitempty"`
 	// Includes allow for specific routing configuration to be included from another HTTPProxy,
 	// possibly in another namespace.
 	// +optional
@@ -37,12 +41,32 @@ type HTTPProxySpec struct {
 }
 
 // Include describes a set of policies that can be applied to an HTTPProxy in a namespace.
+// Exactly one of Name or Selector must be specified.
 type Include struct {
 	// Name of the HTTPProxy
-	Name string `json:"name"`
+	// +optional
+	Name string `json:"name,omitempty"`
 	// Namespace of the HTTPProxy to include. Defaults to the current namespace if not supplied.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
+	// Selector includes every HTTPProxy whose labels match the selector.
+	// Matching HTTPProxies are searched for in Namespace, or the
+	// namespaces matched by NamespaceSelector, and are included in
+	// order of their namespace and name. HTTPProxies that define a
+	// virtual host are never selected.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// NamespaceSelector limits the HTTPProxies matched by Selector to
+	// namespaces whose labels match. It cannot be combined with Namespace.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// PriorityRange limits the route priorities of the included
+	// HTTPProxies, and any HTTPProxies they include in turn. Route
+	// priorities outside the range are clamped to the nearest bound.
+	// Routes that do not set a priority are treated as having priority
+	// 0, and are also assigned the nearest bound if 0 is outside it.
+	// +optional
+	PriorityRange *RoutePriorityRange `json:"priorityRange,omitempty"`
 	// Conditions are a set of rules that are applied to included HTTPProxies.
 	// In effect, they are added onto the Conditions of included HTTPProxy Route
 	// structs.
@@ -165,6 +189,48 @@ type AuthorizationServer struct {
 	//
 	// +optional
 	FailOpen bool `json:"failOpen,omitempty"`
+
+	// WithRequestBody configures the client request body that is
+	// sent to the authorization server. If not specified, the
+	// request body is not sent.
+	//
+	// +optional
+	WithRequestBody *AuthorizationServerBufferSettings `json:"withRequestBody,omitempty"`
+
+	// AllowedHeaders is the list of client request headers that
+	// are sent to an HTTP authorization server. If not specified,
+	// all request headers are sent. The ":method", ":path", "host"
+	// and "content-length" headers are always sent. GRPC
+	// authorization servers are always sent all request headers.
+	//
+	// +optional
+	AllowedHeaders []string `json:"allowedHeaders,omitempty"`
+}
+
+// AuthorizationServerBufferSettings enables sending the request
+// body to the authorization server.
+type AuthorizationServerBufferSettings struct {
+	// MaxRequestBytes sets the maximum size of the request body
+	// that is buffered and sent to the authorization server.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1024
+	MaxRequestBytes uint32 `json:"maxRequestBytes,omitempty"`
+
+	// AllowPartialMessage sends the first MaxRequestBytes of the
+	// request body to the authorization server when the body is
+	// larger than MaxRequestBytes. If false, requests with larger
+	// bodies are rejected with a 413 response.
+	//
+	// +optional
+	AllowPartialMessage bool `json:"allowPartialMessage,omitempty"`
+
+	// PackAsBytes sends the request body as raw bytes instead of
+	// as a UTF-8 string, so that binary bodies can be verified.
+	//
+	// +optional
+	PackAsBytes bool `json:"packAsBytes,omitempty"`
 }
 
 // AuthorizationPolicy modifies how client requests are authenticated.
@@ -185,13 +251,261 @@ type AuthorizationPolicy struct {
 	Context map[string]string `json:"context,omitempty"`
 }
 
+// JWTProvider defines how to verify JWTs on requests.
+type JWTProvider struct {
+	// Unique name for the provider.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Issuer that JWTs are required to have in the "iss" field.
+	// If not provided, JWT issuers are not checked.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Audiences that JWTs are allowed to have in the "aud" field.
+	// If not provided, JWT audiences are not checked.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+
+	// RemoteJWKS fetches the JSON Web Key Set used to verify JWTs
+	// from a remote URI. Exactly one of RemoteJWKS or LocalJWKS
+	// must be specified.
+	// +optional
+	RemoteJWKS *RemoteJWKS `json:"remoteJWKS,omitempty"`
+
+	// LocalJWKS configures the JSON Web Key Set used to verify
+	// JWTs locally. Exactly one of RemoteJWKS or LocalJWKS must
+	// be specified.
+	// +optional
+	LocalJWKS *LocalJWKS `json:"localJWKS,omitempty"`
+
+	// Whether the JWT should be forwarded to the backend
+	// service after successful verification. By default,
+	// the JWT is not forwarded.
+	// +optional
+	ForwardJWT bool `json:"forwardJWT,omitempty"`
+
+	// ClaimsToHeaders copies claims from verified JWTs into
+	// request headers that are forwarded to the backend service.
+	// Any existing values of these headers are removed from the
+	// client request.
+	// +optional
+	ClaimsToHeaders []JWTClaimToHeader `json:"claimsToHeaders,omitempty"`
+
+	// Whether the provider should apply to all routes in the
+	// HTTPProxy/its includes by default. At most one provider can
+	// be marked as the default. If no provider is marked as the
+	// default, routes must explicitly identify the provider they
+	// require.
+	// +optional
+	Default bool `json:"default,omitempty"`
+}
+
+// RemoteJWKS defines how to fetch a JWKS from an HTTP endpoint.
+type RemoteJWKS struct {
+	// The URI for the JWKS.
+	// +kubebuilder:validation:MinLength=1
+	URI string `json:"uri"`
+
+	// ExtensionServiceRef specifies the extension service that
+	// is used as the upstream cluster to fetch the JWKS from.
+	// +required
+	ExtensionServiceRef ExtensionServiceReference `json:"extensionRef"`
+
+	// How long to wait for a response from the URI.
+	// If not specified, a default of 1s applies.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	Timeout string `json:"timeout,omitempty"`
+
+	// How long to cache the JWKS locally. If not specified,
+	// Envoy's default of 5m applies.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	CacheDuration string `json:"cacheDuration,omitempty"`
+}
+
+// LocalJWKS defines a JWKS that is provided to Envoy directly.
+// Exactly one of Inline, SecretName or ConfigMapName must be specified.
+type LocalJWKS struct {
+	// Inline is the JWKS as a JSON document.
+	// +optional
+	Inline string `json:"inline,omitempty"`
+
+	// SecretName is the name of a Secret in the current namespace
+	// that holds the JWKS in its "jwks" key.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// ConfigMapName is the name of a ConfigMap in the current
+	// namespace that holds the JWKS in its "jwks" key.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// JWTClaimToHeader copies a JWT claim into a request header.
+type JWTClaimToHeader struct {
+	// Claim is the name of the top level JWT claim to copy.
+	// +kubebuilder:validation:MinLength=1
+	Claim string `json:"claim"`
+
+	// Header is the name of the request header to set.
+	// +kubebuilder:validation:MinLength=1
+	Header string `json:"header"`
+}
+
+// JWTVerificationPolicy defines how JWTs are verified for a route.
+type JWTVerificationPolicy struct {
+	// Mode sets whether a valid JWT is required, optional, or
+	// not verified for requests to this route. An optional JWT
+	// is verified if it is present, but requests without a JWT
+	// are allowed.
+	// +kubebuilder:validation:Enum=required;optional;disabled
+	Mode string `json:"mode"`
+
+	// Provider names the JWT provider, defined on the root
+	// HTTPProxy, that verifies JWTs for this route. If not
+	// specified, the default provider is used.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+}
+
+// BasicAuth configures HTTP Basic authentication.
+type BasicAuth struct {
+	// SecretName is the name of a Secret in the current namespace
+	// that holds htpasswd formatted credentials in its "auth" key.
+	// Only bcrypt, apr1 and SHA1 ("{SHA}") password hashes are
+	// supported, and Secrets with other hashes are rejected.
+	// When set on a route, it overrides the credentials of the
+	// virtual host.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Realm is the authentication realm that is sent to clients.
+	// If not specified, the realm defaults to the virtual host fqdn.
+	// +optional
+	Realm string `json:"realm,omitempty"`
+
+	// Disabled turns off basic authentication for a route.
+	// It cannot be set on a virtual host.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// IPFilterPolicy restricts access by the IP address of the client.
+// For HTTP requests, the client address is derived from the
+// X-Forwarded-For header when Contour is configured with
+// num-trusted-hops, otherwise it is the address of the downstream
+// connection. A policy must have at least one allow or deny entry.
+type IPFilterPolicy struct {
+	// Allow is a list of CIDR ranges (e.g. "10.0.0.0/8") or
+	// IP addresses. If present, only clients whose address is
+	// in one of these ranges are permitted.
+	// +optional
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny is a list of CIDR ranges or IP addresses. Clients
+	// whose address is in one of these ranges are rejected,
+	// even if they are also in an allowed range.
+	// +optional
+	Deny []string `json:"deny,omitempty"`
+}
+
+// OAuth2 configures an OAuth2 login flow for browser clients.
+// Requests without a valid session cookie are redirected to the
+// authorization endpoint of the identity provider. When the
+// identity provider redirects back, Envoy exchanges the
+// authorization code for an access token and sets session
+// cookies that are signed with the HMAC secret.
+type OAuth2 struct {
+	// TokenEndpoint is the endpoint of the identity provider
+	// that issues access tokens.
+	TokenEndpoint OAuth2TokenEndpoint `json:"tokenEndpoint"`
+
+	// AuthorizationEndpoint is the URI of the identity provider
+	// that clients are redirected to in order to log in.
+	// +kubebuilder:validation:MinLength=1
+	AuthorizationEndpoint string `json:"authorizationEndpoint"`
+
+	// ClientID is the OAuth2 client ID of the virtual host.
+	// +kubebuilder:validation:MinLength=1
+	ClientID string `json:"clientID"`
+
+	// ClientSecretName is the name of a Secret in the current
+	// namespace that holds the OAuth2 client secret in its
+	// "client-secret" key.
+	// +kubebuilder:validation:MinLength=1
+	ClientSecretName string `json:"clientSecretName"`
+
+	// HMACSecretName is the name of a Secret in the current
+	// namespace that holds the key used to sign session cookies
+	// in its "hmac-secret" key.
+	// +kubebuilder:validation:MinLength=1
+	HMACSecretName string `json:"hmacSecretName"`
+
+	// RedirectPath is the path that the identity provider
+	// redirects clients to after they log in. It must be
+	// registered with the identity provider. If not specified,
+	// it defaults to "/oauth2/callback".
+	// +optional
+	RedirectPath string `json:"redirectPath,omitempty"`
+
+	// SignoutPath is the path that clears the session cookies.
+	// If not specified, it defaults to "/oauth2/signout".
+	// +optional
+	SignoutPath string `json:"signoutPath,omitempty"`
+
+	// ForwardBearerToken forwards the access token to the
+	// backend service in the Authorization header.
+	// +optional
+	ForwardBearerToken bool `json:"forwardBearerToken,omitempty"`
+
+	// PassThroughMatchers are header match conditions for
+	// requests that do not require a login. A request that
+	// matches any of the conditions is passed through to the
+	// backend service. The ":path" pseudo-header can be used
+	// to match request paths.
+	// +optional
+	PassThroughMatchers []HeaderMatchCondition `json:"passThroughMatchers,omitempty"`
+}
+
+// OAuth2TokenEndpoint defines how to reach the token endpoint of
+// an identity provider.
+type OAuth2TokenEndpoint struct {
+	// The URI of the token endpoint.
+	// +kubebuilder:validation:MinLength=1
+	URI string `json:"uri"`
+
+	// ExtensionServiceRef specifies the extension service that
+	// is used as the upstream cluster for the token endpoint.
+	// +required
+	ExtensionServiceRef ExtensionServiceReference `json:"extensionRef"`
+
+	// How long to wait for a response from the token endpoint.
+	// If not specified, a default of 3s applies.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	Timeout string `json:"timeout,omitempty"`
+}
+
 // VirtualHost appears at most once. If it is present, the object is considered
 // to be a "root".
 type VirtualHost struct {
 	// The fully qualified domain name of the root of the ingress tree
 	// all leaves of the DAG rooted at this object relate to the fqdn.
+	// The first DNS label may be a wildcard (e.g. "*.example.com"),
+	// which matches any single DNS label under the remaining domain.
 	Fqdn string `json:"fqdn"`
 
+	// Aliases are additional fully qualified domain names that are
+	// served by this virtual host. Each alias shares the routes,
+	// includes, TLS configuration and policies of the fqdn. If TLS
+	// is configured with a secret, the certificate must be valid for
+	// every alias.
+	//
+	// +optional
+	Aliases []string `json:"aliases,omitempty"`
+
 	// If present the fields describes TLS properties of the virtual
 	// host. The SNI names that will be matched on are described in fqdn,
 	// the tls.secretName secret must contain a certificate that itself
@@ -200,6 +514,19 @@ type VirtualHost struct {
 	// +optional
 	TLS *TLS `json:"tls,omitempty"`
 
+	// Listener is the name of the additional listener, defined in
+	// the Contour configuration file, that the virtual host binds
+	// to. A virtual host that binds to an HTTPS listener must
+	// configure TLS, and one that binds to an HTTP listener must
+	// not. One that binds to a TCP listener must configure a
+	// TCPProxy, which all connections to the listener are proxied
+	// to without TLS, and one that binds to a UDP listener must
+	// configure a UDPProxy. If empty, the virtual host binds to
+	// the default HTTP and HTTPS listeners.
+	//
+	// +optional
+	Listener string `json:"listener,omitempty"`
+
 	// This field configures an extension service to perform
 	// authorization for this virtual host. Authorization can
 	// only be configured on virtual hosts that have TLS enabled.
@@ -209,12 +536,74 @@ type VirtualHost struct {
 	//
 	// +optional
 	Authorization *AuthorizationServer `json:"authorization,omitempty"`
+
+	// Providers to use for verifying JSON Web Tokens (JWTs) on
+	// the virtual host. JWT verification can only be configured
+	// on virtual hosts that terminate TLS.
+	//
+	// +optional
+	JWTProviders []JWTProvider `json:"jwtProviders,omitempty"`
+
+	// BasicAuth requires HTTP Basic authentication for requests
+	// to the virtual host. Basic authentication can only be
+	// configured on virtual hosts that terminate TLS, and cannot
+	// be combined with an authorization server.
+	//
+	// +optional
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+
+	// OAuth2 requires browser clients to log in with an OAuth2
+	// identity provider. OAuth2 is not supported yet, because
+	// Envoy cannot configure the scopes that it requests. An
+	// HTTPProxy that sets OAuth2 has an OAuth2NotSupported error.
+	//
+	// +optional
+	OAuth2 *OAuth2 `json:"oauth2,omitempty"`
+
+	// IPFilterPolicy restricts the clients that can connect to
+	// the virtual host by their IP address. It applies to every
+	// route of the virtual host, and to its TCPProxy.
+	//
+	// +optional
+	IPFilterPolicy *IPFilterPolicy `json:"ipFilterPolicy,omitempty"`
 	// Specifies the cross-origin policy to apply to the VirtualHost.
 	// +optional
 	CORSPolicy *CORSPolicy `json:"corsPolicy,omitempty"`
 	// The policy for rate limiting on the virtual host.
 	// +optional
 	RateLimitPolicy *RateLimitPolicy `json:"rateLimitPolicy,omitempty"`
+	// HSTSPolicy adds a Strict-Transport-Security header to the
+	// responses of the virtual host that are served over TLS. It
+	// overrides the global HSTS policy, and requires TLS to be
+	// terminated by Envoy.
+	// +optional
+	HSTSPolicy *HSTSPolicy `json:"hstsPolicy,omitempty"`
+	// CSRFPolicy enables cross-site request forgery (CSRF) protection
+	// for the routes of the virtual host. Routes may override it
+	// with their own policy.
+	// +optional
+	CSRFPolicy *CSRFPolicy `json:"csrfPolicy,omitempty"`
+}
+
+// HSTSPolicy defines the HTTP Strict Transport Security (HSTS)
+// policy of a virtual host.
+type HSTSPolicy struct {
+	// MaxAge is how long browsers should only access the virtual
+	// host over TLS, e.g. "8760h" for one year. It is sent in whole
+	// seconds, and "0s" tells browsers to forget the policy.
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	MaxAge string `json:"maxAge"`
+
+	// IncludeSubDomains applies the policy to all subdomains of
+	// the virtual host.
+	// +optional
+	IncludeSubDomains bool `json:"includeSubDomains,omitempty"`
+
+	// Preload allows the virtual host to be included in browser
+	// HSTS preload lists. It requires IncludeSubDomains and a
+	// MaxAge of at least one year.
+	// +optional
+	Preload bool `json:"preload,omitempty"`
 }
 
 // TLS describes tls properties. The SNI names that will be matched on
@@ -225,11 +614,56 @@ type TLS struct {
 	// If specified, the named secret must contain a matching certificate
 	// for the virtual host's FQDN.
 	SecretName string `json:"secretName,omitempty"`
+	// AdditionalSecretNames are the names of further TLS secrets
+	// that are served alongside SecretName, e.g. an ECDSA certificate
+	// alongside an RSA certificate. Envoy selects the certificate
+	// based on the algorithms the client supports. At most one RSA
+	// and one ECDSA (P-256) certificate may be configured in total.
+	// Secrets in other namespaces must be delegated in the same way
+	// as SecretName.
+	// +optional
+	AdditionalSecretNames []string `json:"additionalSecretNames,omitempty"`
 	// MinimumProtocolVersion is the minimum TLS version this vhost should
 	// negotiate. Valid options are `1.2` (default) and `1.3`. Any other value
 	// defaults to TLS 1.2.
 	// +optional
 	MinimumProtocolVersion string `json:"minimumProtocolVersion,omitempty"`
+	// MaximumProtocolVersion is the maximum TLS version this vhost should
+	// negotiate. Valid options are `1.2` and `1.3` (default). It must not
+	// be lower than MinimumProtocolVersion.
+	// +optional
+	MaximumProtocolVersion string `json:"maximumProtocolVersion,omitempty"`
+	// CipherSuites are the TLS 1.2 cipher suites this vhost should
+	// offer, overriding the cipher suites in the Contour configuration.
+	// The supported values are the same as the Contour configuration's
+	// `tls.cipher-suites`. TLS 1.3 cipher suites are not configurable.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+	// OCSPStaplePolicy is how the OCSP responses in the
+	// "tls.ocsp-staple" keys of the TLS secrets are stapled to the
+	// TLS handshake. With `lenient` (default), a certificate is used
+	// without stapling if its response is missing or expired. With
+	// `strict`, a certificate whose response has expired is not used.
+	// With `must-staple`, every secret must have a response, and a
+	// certificate whose response has expired is not used.
+	// +kubebuilder:validation:Enum=lenient;strict;must-staple
+	// +optional
+	OCSPStaplePolicy string `json:"ocspStaplePolicy,omitempty"`
+	// SessionTicketKeysSecretName is the name of a secret whose
+	// "session-ticket-keys" key holds the keys used to encrypt and
+	// decrypt TLS session tickets, overriding the session ticket
+	// keys in the Contour configuration. Sharing the keys lets
+	// clients resume TLS sessions on any Envoy. Secrets in other
+	// namespaces must be delegated in the same way as SecretName.
+	// +optional
+	SessionTicketKeysSecretName string `json:"sessionTicketKeysSecretName,omitempty"`
+	// ACMEChallenge routes ACME HTTP-01 challenges for the fqdn and
+	// aliases to a solver over plain HTTP. Challenges are never
+	// upgraded to HTTPS, even if PermitInsecure is false, so that
+	// certificates can be issued and renewed. It cannot be combined
+	// with Passthrough.
+	// +optional
+	ACMEChallenge *ACMEChallenge `json:"acmeChallenge,omitempty"`
 	// Passthrough defines whether the encrypted TLS handshake will be
 	// passed through to the backing cluster. Either Passthrough or
 	// SecretName must be specified, but not both.
@@ -257,6 +691,69 @@ type TLS struct {
 	EnableFallbackCertificate bool `json:"enableFallbackCertificate,omitempty"`
 }
 
+// ACMEChallenge defines where ACME HTTP-01 challenges are routed.
+// If ServiceName is not set, the challenges are routed according to
+// the Ingresses that cert-manager creates for its HTTP-01 solvers in
+// the HTTPProxy's namespace.
+type ACMEChallenge struct {
+	// ServiceName is the name of the Service in the HTTPProxy's
+	// namespace that solves the challenges. All requests with the
+	// "/.well-known/acme-challenge/" path prefix are routed to it.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+	// Port is the port of the Service. It must be specified with
+	// ServiceName.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port,omitempty"`
+}
+
+// CSRFPolicy defines the cross-site request forgery (CSRF) protection
+// of a virtual host or route. Requests with mutating methods (e.g. POST,
+// PUT and DELETE) are rejected unless their Origin header, or their
+// Referer header if there is no Origin, matches the destination host
+// or one of the additional origins.
+type CSRFPolicy struct {
+	// EnforcementPercentage is the percentage of requests whose origin
+	// is enforced. Defaults to 100, or to 0 in shadow mode. Setting it
+	// to 0 without shadow mode disables CSRF protection.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	EnforcementPercentage *uint32 `json:"enforcementPercentage,omitempty"`
+
+	// Shadow evaluates the origin of requests that are not enforced,
+	// and records the result in Envoy statistics without rejecting
+	// them.
+	// +optional
+	Shadow bool `json:"shadow,omitempty"`
+
+	// AdditionalOrigins are allowed in addition to the destination
+	// host. Origins are matched by their host and optional port,
+	// without the scheme, e.g. "login.example.com".
+	// +optional
+	AdditionalOrigins []CSRFOriginMatch `json:"additionalOrigins,omitempty"`
+}
+
+// CSRFOriginMatch specifies how to match the origin of a request.
+// Exactly one field must be provided.
+type CSRFOriginMatch struct {
+	// Exact specifies a string that the origin must be equal to.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Suffix specifies a string that the origin must end with,
+	// e.g. ".example.com".
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+
+	// Regex specifies an RE2 regular expression that the whole
+	// origin must match.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+}
+
 // CORSHeaderValue specifies the value of the string headers returned by a cross-domain request.
 // +kubebuilder:validation:Pattern="^[a-zA-Z0-9!#$%&'*+.^_`|~-]+$"
 type CORSHeaderValue string
@@ -313,6 +810,28 @@ type Route struct {
 	// match this route.
 	// +optional
 	AuthPolicy *AuthorizationPolicy `json:"authPolicy,omitempty"`
+	// Authorization overrides the authorization server that was
+	// set on the root HTTPProxy object for client requests that
+	// match this route. The root HTTPProxy must configure an
+	// authorization server. The authPolicy field is not permitted
+	// here; use the authPolicy field of the route instead.
+	// +optional
+	Authorization *AuthorizationServer `json:"authorization,omitempty"`
+	// The policy for verifying JWTs for requests to this route.
+	// If not specified, JWTs are verified by the default JWT
+	// provider of the virtual host, if one is set.
+	// +optional
+	JWTVerificationPolicy *JWTVerificationPolicy `json:"jwtVerificationPolicy,omitempty"`
+	// The HTTP Basic authentication policy for this route.
+	// If not specified, the basic authentication policy of
+	// the virtual host applies.
+	// +optional
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+	// The IP filter policy for this route. It is combined with
+	// the IP filter policy of the virtual host, so it can only
+	// further restrict the clients that are permitted.
+	// +optional
+	IPFilterPolicy *IPFilterPolicy `json:"ipFilterPolicy,omitempty"`
 	// The timeout policy for this route.
 	// +optional
 	TimeoutPolicy *TimeoutPolicy `json:"timeoutPolicy,omitempty"`
@@ -340,6 +859,25 @@ type Route struct {
 	// The policy for rate limiting on the route.
 	// +optional
 	RateLimitPolicy *RateLimitPolicy `json:"rateLimitPolicy,omitempty"`
+	// CSRFPolicy enables cross-site request forgery (CSRF) protection
+	// for the route, replacing the policy of the virtual host.
+	// +optional
+	CSRFPolicy *CSRFPolicy `json:"csrfPolicy,omitempty"`
+	// Priority orders this route relative to the other routes of the
+	// virtual host. Routes with a higher priority are matched first.
+	// Routes with equal priority, including the default of 0, are
+	// ordered by their match conditions, most specific first.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// RoutePriorityRange limits the route priorities that may be used by
+// included HTTPProxies.
+type RoutePriorityRange struct {
+	// Min is the lowest route priority that may be used.
+	Min int32 `json:"min"`
+	// Max is the highest route priority that may be used.
+	Max int32 `json:"max"`
 }
 
 // RateLimitPolicy defines rate limiting parameters.
@@ -513,6 +1051,38 @@ type TCPProxy struct {
 	// The health check policy for this tcp proxy
 	// +optional
 	HealthCheckPolicy *TCPHealthCheckPolicy `json:"healthCheckPolicy,omitempty"`
+	// The IP filter policy for this tcp proxy. It is combined
+	// with the policy of the including tcp proxy or of the virtual
+	// host, so it can only further restrict the clients that are
+	// permitted. The client address is the address of the
+	// downstream connection.
+	// +optional
+	IPFilterPolicy *IPFilterPolicy `json:"ipFilterPolicy,omitempty"`
+}
+
+// UDPProxy forwards the datagrams received on a UDP listener to a
+// Kubernetes Service port. Each client address and port is a session
+// that is forwarded to one endpoint of the Service.
+type UDPProxy struct {
+	// ServiceName is the name of the Service in the HTTPProxy's
+	// namespace that datagrams are forwarded to.
+	ServiceName string `json:"serviceName"`
+	// Port is the UDP port of the Service.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port"`
+	// SessionIdleTimeout is how long a session is kept without
+	// sending or receiving datagrams, e.g. "30s". Defaults to one
+	// minute.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(((\d*(\.\d*)?h)|(\d*(\.\d*)?m)|(\d*(\.\d*)?s)|(\d*(\.\d*)?ms)|(\d*(\.\d*)?us)|(\d*(\.\d*)?µs)|(\d*(\.\d*)?ns))+)$`
+	SessionIdleTimeout string `json:"sessionIdleTimeout,omitempty"`
+	// HashSourceIP selects the endpoint of each session by a hash
+	// of the client's IP address, so that all sessions of a client
+	// are forwarded to the same endpoint. Otherwise, endpoints are
+	// selected round robin.
+	// +optional
+	HashSourceIP bool `json:"hashSourceIP,omitempty"`
 }
 
 // TCPProxyInclude describes a target HTTPProxy document which contains the TCPProxy details.
@@ -783,8 +1353,34 @@ type HeaderValue struct {
 type UpstreamValidation struct {
 	// Name of the Kubernetes secret be used to validate the certificate presented by the backend
 	CACertificate string `json:"caSecret"`
-	// Key which is expected to be present in the 'subjectAltName' of the presented certificate
-	SubjectName string `json:"subjectName"`
+	// Key which is expected to be present in the 'subjectAltName' of the presented certificate.
+	// At least one of SubjectName or SubjectNames must be specified.
+	// +optional
+	SubjectName string `json:"subjectName,omitempty"`
+	// SubjectNames is a list of acceptable 'subjectAltName' entries for the
+	// presented certificate. DNS and URI (e.g. SPIFFE ID) names are matched.
+	// The certificate is accepted if any of SubjectName or SubjectNames
+	// match one of its subject alternative names.
+	// +optional
+	SubjectNames []SubjectNameMatch `json:"subjectNames,omitempty"`
+}
+
+// SubjectNameMatch specifies how to match a subject alternative name
+// presented by a peer certificate. Exactly one field must be provided.
+type SubjectNameMatch struct {
+	// Exact specifies a string that the subject alternative name must be equal to.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Prefix specifies a string that the subject alternative name must start with,
+	// for example "spiffe://cluster.local/ns/backend/".
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Regex specifies an RE2 regular expression that the whole subject
+	// alternative name must match.
+	// +optional
+	Regex string `json:"regex,omitempty"`
 }
 
 // DownstreamValidation defines how to verify the client certificate.
@@ -804,6 +1400,81 @@ type DownstreamValidation struct {
 	// external authorization server.
 	// +optional
 	SkipClientCertValidation bool `json:"skipClientCertValidation"`
+
+	// OptionalClientCertificate requests a client certificate, but
+	// admits clients that do not present one. Certificates that
+	// are presented are still validated. Defaults to false.
+	// +optional
+	OptionalClientCertificate bool `json:"optionalClientCertificate,omitempty"`
+
+	// ForwardClientCertificate configures how the client certificate
+	// is forwarded to backend services in the x-forwarded-client-cert
+	// (XFCC) header. If not specified, the XFCC header is removed from
+	// client requests.
+	// +optional
+	ForwardClientCertificate *ForwardClientCertificate `json:"forwardClientCertificate,omitempty"`
+
+	// CertificateRevocationList configures a certificate revocation
+	// list (CRL) that client certificates are checked against.
+	// Requires CACertificate.
+	// +optional
+	CertificateRevocationList *CertificateRevocationList `json:"crl,omitempty"`
+}
+
+// CertificateRevocationList defines a PEM encoded certificate revocation
+// list held in the "crl.pem" key of a Secret or ConfigMap in the current
+// namespace. Exactly one of SecretName or ConfigMapName must be specified.
+type CertificateRevocationList struct {
+	// SecretName is the name of a Secret that holds the CRL.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// ConfigMapName is the name of a ConfigMap that holds the CRL.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// ForwardClientCertificate configures the x-forwarded-client-cert
+// (XFCC) header that is sent to backend services.
+type ForwardClientCertificate struct {
+	// Mode sets how the XFCC header is handled. The header is only
+	// forwarded on connections that present a client certificate;
+	// it is always removed from other requests.
+	//
+	// "Sanitize" removes the XFCC header.
+	// "Forward" forwards the XFCC header of the client request.
+	// "Append" appends the client certificate details to the XFCC
+	// header of the client request.
+	// "SanitizeSet" replaces the XFCC header of the client request
+	// with the client certificate details.
+	//
+	// Defaults to "SanitizeSet".
+	// +optional
+	// +kubebuilder:validation:Enum=Sanitize;Forward;Append;SanitizeSet
+	Mode string `json:"mode,omitempty"`
+
+	// Subject adds the subject of the client certificate.
+	// +optional
+	Subject bool `json:"subject,omitempty"`
+
+	// URI adds the URI type subject alternative names of the
+	// client certificate.
+	// +optional
+	URI bool `json:"uri,omitempty"`
+
+	// DNS adds the DNS type subject alternative names of the
+	// client certificate.
+	// +optional
+	DNS bool `json:"dns,omitempty"`
+
+	// Cert adds the URL encoded PEM client certificate.
+	// +optional
+	Cert bool `json:"cert,omitempty"`
+
+	// Chain adds the URL encoded PEM client certificate chain,
+	// including the client certificate.
+	// +optional
+	Chain bool `json:"chain,omitempty"`
 }
 
 // HTTPProxyStatus reports the current state of the HTTPProxy.
@@ -833,6 +1504,10 @@ type HTTPProxyStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []DetailedCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// IncludedBy lists the root HTTPProxies, as "namespace/name",
+	// whose virtual hosts include the routes of this HTTPProxy.
+	// +optional
+	IncludedBy []string `json:"includedBy,omitempty"`
 }
 
 // +genclient
@@ -21,9 +21,25 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ACMEChallenge) DeepCopyInto(out *ACMEChallenge) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ACMEChallenge.
+func (in *ACMEChallenge) DeepCopy() *ACMEChallenge {
+	if in == nil {
+		return nil
+	}
+	out := new(ACMEChallenge)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationPolicy) DeepCopyInto(out *AuthorizationPolicy) {
 	*out = *in
@@ -55,6 +71,16 @@ func (in *AuthorizationServer) DeepCopyInto(out *AuthorizationServer) {
 		*out = new(AuthorizationPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.WithRequestBody != nil {
+		in, out := &in.WithRequestBody, &out.WithRequestBody
+		*out = new(AuthorizationServerBufferSettings)
+		**out = **in
+	}
+	if in.AllowedHeaders != nil {
+		in, out := &in.AllowedHeaders, &out.AllowedHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationServer.
@@ -67,6 +93,36 @@ func (in *AuthorizationServer) DeepCopy() *AuthorizationServer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationServerBufferSettings) DeepCopyInto(out *AuthorizationServerBufferSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationServerBufferSettings.
+func (in *AuthorizationServerBufferSettings) DeepCopy() *AuthorizationServerBufferSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationServerBufferSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BasicAuth) DeepCopyInto(out *BasicAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicAuth.
+func (in *BasicAuth) DeepCopy() *BasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(BasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CORSPolicy) DeepCopyInto(out *CORSPolicy) {
 	*out = *in
@@ -102,6 +158,46 @@ func (in *CORSPolicy) DeepCopy() *CORSPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSRFOriginMatch) DeepCopyInto(out *CSRFOriginMatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSRFOriginMatch.
+func (in *CSRFOriginMatch) DeepCopy() *CSRFOriginMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(CSRFOriginMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSRFPolicy) DeepCopyInto(out *CSRFPolicy) {
+	*out = *in
+	if in.EnforcementPercentage != nil {
+		in, out := &in.EnforcementPercentage, &out.EnforcementPercentage
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.AdditionalOrigins != nil {
+		in, out := &in.AdditionalOrigins, &out.AdditionalOrigins
+		*out = make([]CSRFOriginMatch, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSRFPolicy.
+func (in *CSRFPolicy) DeepCopy() *CSRFPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CSRFPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateDelegation) DeepCopyInto(out *CertificateDelegation) {
 	*out = *in
@@ -122,6 +218,21 @@ func (in *CertificateDelegation) DeepCopy() *CertificateDelegation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRevocationList) DeepCopyInto(out *CertificateRevocationList) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRevocationList.
+func (in *CertificateRevocationList) DeepCopy() *CertificateRevocationList {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRevocationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DetailedCondition) DeepCopyInto(out *DetailedCondition) {
 	*out = *in
@@ -151,6 +262,16 @@ func (in *DetailedCondition) DeepCopy() *DetailedCondition {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DownstreamValidation) DeepCopyInto(out *DownstreamValidation) {
 	*out = *in
+	if in.ForwardClientCertificate != nil {
+		in, out := &in.ForwardClientCertificate, &out.ForwardClientCertificate
+		*out = new(ForwardClientCertificate)
+		**out = **in
+	}
+	if in.CertificateRevocationList != nil {
+		in, out := &in.CertificateRevocationList, &out.CertificateRevocationList
+		*out = new(CertificateRevocationList)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DownstreamValidation.
@@ -178,6 +299,21 @@ func (in *ExtensionServiceReference) DeepCopy() *ExtensionServiceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForwardClientCertificate) DeepCopyInto(out *ForwardClientCertificate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForwardClientCertificate.
+func (in *ForwardClientCertificate) DeepCopy() *ForwardClientCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(ForwardClientCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GenericKeyDescriptor) DeepCopyInto(out *GenericKeyDescriptor) {
 	*out = *in
@@ -215,6 +351,21 @@ func (in *GlobalRateLimitPolicy) DeepCopy() *GlobalRateLimitPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HSTSPolicy) DeepCopyInto(out *HSTSPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HSTSPolicy.
+func (in *HSTSPolicy) DeepCopy() *HSTSPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HSTSPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPHealthCheckPolicy) DeepCopyInto(out *HTTPHealthCheckPolicy) {
 	*out = *in
@@ -309,6 +460,11 @@ func (in *HTTPProxySpec) DeepCopyInto(out *HTTPProxySpec) {
 		*out = new(TCPProxy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.UDPProxy != nil {
+		in, out := &in.UDPProxy, &out.UDPProxy
+		*out = new(UDPProxy)
+		**out = **in
+	}
 	if in.Includes != nil {
 		in, out := &in.Includes, &out.Includes
 		*out = make([]Include, len(*in))
@@ -339,6 +495,11 @@ func (in *HTTPProxyStatus) DeepCopyInto(out *HTTPProxyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.IncludedBy != nil {
+		in, out := &in.IncludedBy, &out.IncludedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProxyStatus.
@@ -421,9 +582,49 @@ func (in *HeadersPolicy) DeepCopy() *HeadersPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPFilterPolicy) DeepCopyInto(out *IPFilterPolicy) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPFilterPolicy.
+func (in *IPFilterPolicy) DeepCopy() *IPFilterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IPFilterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Include) DeepCopyInto(out *Include) {
 	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PriorityRange != nil {
+		in, out := &in.PriorityRange, &out.PriorityRange
+		*out = new(RoutePriorityRange)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]MatchCondition, len(*in))
@@ -443,6 +644,71 @@ func (in *Include) DeepCopy() *Include {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTClaimToHeader) DeepCopyInto(out *JWTClaimToHeader) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTClaimToHeader.
+func (in *JWTClaimToHeader) DeepCopy() *JWTClaimToHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTClaimToHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTProvider) DeepCopyInto(out *JWTProvider) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoteJWKS != nil {
+		in, out := &in.RemoteJWKS, &out.RemoteJWKS
+		*out = new(RemoteJWKS)
+		**out = **in
+	}
+	if in.LocalJWKS != nil {
+		in, out := &in.LocalJWKS, &out.LocalJWKS
+		*out = new(LocalJWKS)
+		**out = **in
+	}
+	if in.ClaimsToHeaders != nil {
+		in, out := &in.ClaimsToHeaders, &out.ClaimsToHeaders
+		*out = make([]JWTClaimToHeader, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTProvider.
+func (in *JWTProvider) DeepCopy() *JWTProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTVerificationPolicy) DeepCopyInto(out *JWTVerificationPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTVerificationPolicy.
+func (in *JWTVerificationPolicy) DeepCopy() *JWTVerificationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTVerificationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancerPolicy) DeepCopyInto(out *LoadBalancerPolicy) {
 	*out = *in
@@ -465,6 +731,21 @@ func (in *LoadBalancerPolicy) DeepCopy() *LoadBalancerPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalJWKS) DeepCopyInto(out *LocalJWKS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalJWKS.
+func (in *LocalJWKS) DeepCopy() *LocalJWKS {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalJWKS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalRateLimitPolicy) DeepCopyInto(out *LocalRateLimitPolicy) {
 	*out = *in
@@ -505,6 +786,43 @@ func (in *MatchCondition) DeepCopy() *MatchCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2) DeepCopyInto(out *OAuth2) {
+	*out = *in
+	out.TokenEndpoint = in.TokenEndpoint
+	if in.PassThroughMatchers != nil {
+		in, out := &in.PassThroughMatchers, &out.PassThroughMatchers
+		*out = make([]HeaderMatchCondition, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2.
+func (in *OAuth2) DeepCopy() *OAuth2 {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2TokenEndpoint) DeepCopyInto(out *OAuth2TokenEndpoint) {
+	*out = *in
+	out.ExtensionServiceRef = in.ExtensionServiceRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2TokenEndpoint.
+func (in *OAuth2TokenEndpoint) DeepCopy() *OAuth2TokenEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2TokenEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PathRewritePolicy) DeepCopyInto(out *PathRewritePolicy) {
 	*out = *in
@@ -622,6 +940,22 @@ func (in *RemoteAddressDescriptor) DeepCopy() *RemoteAddressDescriptor {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteJWKS) DeepCopyInto(out *RemoteJWKS) {
+	*out = *in
+	out.ExtensionServiceRef = in.ExtensionServiceRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteJWKS.
+func (in *RemoteJWKS) DeepCopy() *RemoteJWKS {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteJWKS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplacePrefix) DeepCopyInto(out *ReplacePrefix) {
 	*out = *in
@@ -739,6 +1073,26 @@ func (in *Route) DeepCopyInto(out *Route) {
 		*out = new(AuthorizationPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(AuthorizationServer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JWTVerificationPolicy != nil {
+		in, out := &in.JWTVerificationPolicy, &out.JWTVerificationPolicy
+		*out = new(JWTVerificationPolicy)
+		**out = **in
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuth)
+		**out = **in
+	}
+	if in.IPFilterPolicy != nil {
+		in, out := &in.IPFilterPolicy, &out.IPFilterPolicy
+		*out = new(IPFilterPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TimeoutPolicy != nil {
 		in, out := &in.TimeoutPolicy, &out.TimeoutPolicy
 		*out = new(TimeoutPolicy)
@@ -779,6 +1133,11 @@ func (in *Route) DeepCopyInto(out *Route) {
 		*out = new(RateLimitPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CSRFPolicy != nil {
+		in, out := &in.CSRFPolicy, &out.CSRFPolicy
+		*out = new(CSRFPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Route.
@@ -791,6 +1150,21 @@ func (in *Route) DeepCopy() *Route {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutePriorityRange) DeepCopyInto(out *RoutePriorityRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutePriorityRange.
+func (in *RoutePriorityRange) DeepCopy() *RoutePriorityRange {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutePriorityRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Service) DeepCopyInto(out *Service) {
 	*out = *in
@@ -802,7 +1176,7 @@ func (in *Service) DeepCopyInto(out *Service) {
 	if in.UpstreamValidation != nil {
 		in, out := &in.UpstreamValidation, &out.UpstreamValidation
 		*out = new(UpstreamValidation)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.RequestHeadersPolicy != nil {
 		in, out := &in.RequestHeadersPolicy, &out.RequestHeadersPolicy
@@ -841,6 +1215,21 @@ func (in *SubCondition) DeepCopy() *SubCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubjectNameMatch) DeepCopyInto(out *SubjectNameMatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubjectNameMatch.
+func (in *SubjectNameMatch) DeepCopy() *SubjectNameMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(SubjectNameMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPHealthCheckPolicy) DeepCopyInto(out *TCPHealthCheckPolicy) {
 	*out = *in
@@ -886,6 +1275,11 @@ func (in *TCPProxy) DeepCopyInto(out *TCPProxy) {
 		*out = new(TCPHealthCheckPolicy)
 		**out = **in
 	}
+	if in.IPFilterPolicy != nil {
+		in, out := &in.IPFilterPolicy, &out.IPFilterPolicy
+		*out = new(IPFilterPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPProxy.
@@ -916,10 +1310,25 @@ func (in *TCPProxyInclude) DeepCopy() *TCPProxyInclude {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
+	if in.AdditionalSecretNames != nil {
+		in, out := &in.AdditionalSecretNames, &out.AdditionalSecretNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ACMEChallenge != nil {
+		in, out := &in.ACMEChallenge, &out.ACMEChallenge
+		*out = new(ACMEChallenge)
+		**out = **in
+	}
 	if in.ClientValidation != nil {
 		in, out := &in.ClientValidation, &out.ClientValidation
 		*out = new(DownstreamValidation)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -1051,9 +1460,29 @@ func (in *TimeoutPolicy) DeepCopy() *TimeoutPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UDPProxy) DeepCopyInto(out *UDPProxy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UDPProxy.
+func (in *UDPProxy) DeepCopy() *UDPProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(UDPProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamValidation) DeepCopyInto(out *UpstreamValidation) {
 	*out = *in
+	if in.SubjectNames != nil {
+		in, out := &in.SubjectNames, &out.SubjectNames
+		*out = make([]SubjectNameMatch, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamValidation.
@@ -1069,6 +1498,11 @@ func (in *UpstreamValidation) DeepCopy() *UpstreamValidation {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualHost) DeepCopyInto(out *VirtualHost) {
 	*out = *in
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(TLS)
@@ -1079,6 +1513,28 @@ func (in *VirtualHost) DeepCopyInto(out *VirtualHost) {
 		*out = new(AuthorizationServer)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.JWTProviders != nil {
+		in, out := &in.JWTProviders, &out.JWTProviders
+		*out = make([]JWTProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuth)
+		**out = **in
+	}
+	if in.OAuth2 != nil {
+		in, out := &in.OAuth2, &out.OAuth2
+		*out = new(OAuth2)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPFilterPolicy != nil {
+		in, out := &in.IPFilterPolicy, &out.IPFilterPolicy
+		*out = new(IPFilterPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CORSPolicy != nil {
 		in, out := &in.CORSPolicy, &out.CORSPolicy
 		*out = new(CORSPolicy)
@@ -1089,6 +1545,16 @@ func (in *VirtualHost) DeepCopyInto(out *VirtualHost) {
 		*out = new(RateLimitPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HSTSPolicy != nil {
+		in, out := &in.HSTSPolicy, &out.HSTSPolicy
+		*out = new(HSTSPolicy)
+		**out = **in
+	}
+	if in.CSRFPolicy != nil {
+		in, out := &in.CSRFPolicy, &out.CSRFPolicy
+		*out = new(CSRFPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualHost.
@@ -58,6 +58,38 @@ type ExtensionServiceTarget struct {
 	Weight uint32 `json:"weight,omitempty"`
 }
 
+// HTTPAuthorizationService defines how authorization requests
+// are sent to services that implement the HTTP external
+// authorization protocol.
+type HTTPAuthorizationService struct {
+	// PathPrefix is prepended to the path of the client request
+	// to form the path of the authorization request.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
+	// HeadersToAdd are added to each authorization request.
+	//
+	// +optional
+	HeadersToAdd []contour_api_v1.HeaderValue `json:"headersToAdd,omitempty"`
+
+	// AllowedUpstreamHeaders are the headers of a response
+	// that allows the client request that are added to the
+	// client request before it is sent to the upstream service.
+	// If not specified, no headers are added.
+	//
+	// +optional
+	AllowedUpstreamHeaders []string `json:"allowedUpstreamHeaders,omitempty"`
+
+	// AllowedDownstreamHeaders are the headers of a response
+	// that denies the client request that are sent to the client.
+	// If not specified, all the headers of the response are sent.
+	//
+	// +optional
+	AllowedDownstreamHeaders []string `json:"allowedDownstreamHeaders,omitempty"`
+}
+
 // ExtensionServiceSpec defines the desired state of an ExtensionService resource.
 type ExtensionServiceSpec struct {
 	// Services specifies the set of Kubernetes Service resources that
@@ -77,12 +109,22 @@ type ExtensionServiceSpec struct {
 	UpstreamValidation *contour_api_v1.UpstreamValidation `json:"validation,omitempty"`
 
 	// Protocol may be used to specify (or override) the protocol used to reach this Service.
-	// Values may be h2 or h2c. If omitted, protocol-selection falls back on Service annotations.
+	// Values may be h2, h2c, http/1.1 or tls (HTTP/1.1 over TLS). GRPC extensions
+	// require h2 or h2c. If omitted, protocol-selection falls back on Service annotations.
 	//
 	// +optional
-	// +kubebuilder:validation:Enum=h2;h2c
+	// +kubebuilder:validation:Enum=h2;h2c;http/1.1;tls
 	Protocol *string `json:"protocol,omitempty"`
 
+	// HTTPAuthorization configures the services to be sent
+	// authorization requests with the HTTP external authorization
+	// protocol rather than the GRPC protocol. In the HTTP protocol,
+	// the services authorize a client request by responding to
+	// the authorization request with a 200 status.
+	//
+	// +optional
+	HTTPAuthorization *HTTPAuthorizationService `json:"httpAuthorization,omitempty"`
+
 	// The policy for load balancing GRPC service requests. Note that the
 	// `Cookie` and `RequestHash` load balancing strategies cannot be used
 	// here.
@@ -95,13 +95,18 @@ func (in *ExtensionServiceSpec) DeepCopyInto(out *ExtensionServiceSpec) {
 	if in.UpstreamValidation != nil {
 		in, out := &in.UpstreamValidation, &out.UpstreamValidation
 		*out = new(v1.UpstreamValidation)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Protocol != nil {
 		in, out := &in.Protocol, &out.Protocol
 		*out = new(string)
 		**out = **in
 	}
+	if in.HTTPAuthorization != nil {
+		in, out := &in.HTTPAuthorization, &out.HTTPAuthorization
+		*out = new(HTTPAuthorizationService)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.LoadBalancerPolicy != nil {
 		in, out := &in.LoadBalancerPolicy, &out.LoadBalancerPolicy
 		*out = new(v1.LoadBalancerPolicy)
@@ -160,3 +165,33 @@ func (in *ExtensionServiceTarget) DeepCopy() *ExtensionServiceTarget {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPAuthorizationService) DeepCopyInto(out *HTTPAuthorizationService) {
+	*out = *in
+	if in.HeadersToAdd != nil {
+		in, out := &in.HeadersToAdd, &out.HeadersToAdd
+		*out = make([]v1.HeaderValue, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedUpstreamHeaders != nil {
+		in, out := &in.AllowedUpstreamHeaders, &out.AllowedUpstreamHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedDownstreamHeaders != nil {
+		in, out := &in.AllowedDownstreamHeaders, &out.AllowedDownstreamHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPAuthorizationService.
+func (in *HTTPAuthorizationService) DeepCopy() *HTTPAuthorizationService {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPAuthorizationService)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -29,6 +29,7 @@ import (
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/annotation"
+	"github.com/projectcontour/contour/internal/basicauth"
 	"github.com/projectcontour/contour/internal/contour"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/debug"
@@ -203,6 +204,7 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 
 	fallbackCert := namespacedNameOf(ctx.Config.TLS.FallbackCertificate)
 	clientCert := namespacedNameOf(ctx.Config.TLS.ClientCertificate)
+	sessionTicketKeys := namespacedNameOf(ctx.Config.TLS.SessionTicketKeys)
 
 	if rootNamespaces := ctx.proxyRootNamespaces(); len(rootNamespaces) > 0 {
 		informerNamespaces = append(informerNamespaces, rootNamespaces...)
@@ -222,6 +224,14 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 				Infof("client certificate namespace %q not defined in 'root-namespaces', adding namespace to watch",
 					ctx.Config.TLS.ClientCertificate.Namespace)
 		}
+
+		// Add the session ticket keys namespace to informerNamespaces if it isn't present.
+		if !contains(informerNamespaces, ctx.Config.TLS.SessionTicketKeys.Namespace) && sessionTicketKeys != nil {
+			informerNamespaces = append(informerNamespaces, ctx.Config.TLS.SessionTicketKeys.Namespace)
+			log.WithField("context", "session-ticket-keys").
+				Infof("session ticket keys namespace %q not defined in 'root-namespaces', adding namespace to watch",
+					ctx.Config.TLS.SessionTicketKeys.Namespace)
+		}
 	}
 
 	// Set up Prometheus registry and register base metrics.
@@ -287,6 +297,8 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 				Port:    ctx.httpsPort,
 			},
 		},
+		TCPListeners:                  map[string]xdscache_v3.Listener{},
+		UDPListeners:                  map[string]xdscache_v3.Listener{},
 		HTTPAccessLog:                 ctx.httpAccessLog,
 		HTTPSAccessLog:                ctx.httpsAccessLog,
 		AccessLogType:                 ctx.Config.AccessLogFormat,
@@ -300,11 +312,59 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		MaxConnectionDuration:         maxConnectionDuration,
 		ConnectionShutdownGracePeriod: connectionShutdownGracePeriod,
 		DefaultHTTPVersions:           parseDefaultHTTPVersions(ctx.Config.DefaultHTTPVersions),
+		HTTP3:                         http3AdvertisedPort(&ctx.Config) != 0,
 		AllowChunkedLength:            !ctx.Config.DisableAllowChunkedLength,
 		XffNumTrustedHops:             ctx.Config.Network.XffNumTrustedHops,
 		ConnectionBalancer:            ctx.Config.Listener.ConnectionBalancer,
 	}
 
+	for _, l := range ctx.Config.Listener.Additional {
+		listener := xdscache_v3.Listener{
+			Name:          l.Name,
+			Address:       l.Address,
+			Port:          l.Port,
+			UseProxyProto: l.UseProxyProtocol,
+		}
+		if listener.Address == "" {
+			listener.Address = xdscache_v3.DEFAULT_HTTP_LISTENER_ADDRESS
+		}
+
+		defaultListeners := []xdscache_v3.Listener{
+			listenerConfig.HTTPListeners["ingress_http"],
+			listenerConfig.HTTPSListeners["ingress_https"],
+		}
+		// A UDP listener can only conflict with the HTTP/3
+		// listener, which binds to the UDP port of the HTTPS
+		// listener.
+		if l.Protocol == config.UDPListenerProtocol {
+			defaultListeners = nil
+			if listenerConfig.HTTP3 {
+				defaultListeners = append(defaultListeners, xdscache_v3.Listener{
+					Name:    xdscache_v3.ENVOY_HTTP3_LISTENER,
+					Address: listenerConfig.HTTPSListeners["ingress_https"].Address,
+					Port:    listenerConfig.HTTPSListeners["ingress_https"].Port,
+				})
+			}
+		}
+
+		for _, defaultListener := range defaultListeners {
+			if listener.Address == defaultListener.Address && listener.Port == defaultListener.Port {
+				return fmt.Errorf("listener %q binds to the same address as the %q listener", l.Name, defaultListener.Name)
+			}
+		}
+
+		switch l.Protocol {
+		case config.HTTPListenerProtocol:
+			listenerConfig.HTTPListeners[l.Name] = listener
+		case config.HTTPSListenerProtocol:
+			listenerConfig.HTTPSListeners[l.Name] = listener
+		case config.TCPListenerProtocol:
+			listenerConfig.TCPListeners[l.Name] = listener
+		case config.UDPListenerProtocol:
+			listenerConfig.UDPListeners[l.Name] = listener
+		}
+	}
+
 	if ctx.Config.RateLimitService.ExtensionService != "" {
 		namespacedName := k8s.NamespacedNameFrom(ctx.Config.RateLimitService.ExtensionService)
 		client := clients.DynamicClient().Resource(contour_api_v1alpha1.ExtensionServiceGVR).Namespace(namespacedName.Namespace)
@@ -345,7 +405,7 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 	resources := []xdscache.ResourceCache{
 		xdscache_v3.NewListenerCache(listenerConfig, ctx.statsAddr, ctx.statsPort),
 		&xdscache_v3.SecretCache{},
-		&xdscache_v3.RouteCache{},
+		&xdscache_v3.RouteCache{HTTP3AdvertisedPort: http3AdvertisedPort(&ctx.Config)},
 		&xdscache_v3.ClusterCache{},
 		endpointHandler,
 	}
@@ -364,12 +424,18 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		log.WithField("context", "envoy-client-certificate").Infof("enabled client certificate with secret: %q", clientCert)
 	}
 
+	// basicAuthServer verifies the credentials of requests to
+	// routes that require basic authentication on behalf of Envoy.
+	// It observes the DAG before the snapshotHandler, so that it
+	// knows the credentials of routes before Envoy does.
+	basicAuthServer := basicauth.NewServer(log.WithField("context", "basicauth"))
+
 	// Build the core Kubernetes event handler.
 	eventHandler := &contour.EventHandler{
 		HoldoffDelay:    100 * time.Millisecond,
 		HoldoffMaxDelay: 500 * time.Millisecond,
-		Observer:        dag.ComposeObservers(append(xdscache.ObserversOf(resources), snapshotHandler)...),
-		Builder:         getDAGBuilder(ctx, clients, clientCert, fallbackCert, log),
+		Observer:        dag.ComposeObservers(append(xdscache.ObserversOf(resources), basicAuthServer, snapshotHandler)...),
+		Builder:         getDAGBuilder(ctx, clients, clientCert, fallbackCert, sessionTicketKeys, log),
 		FieldLogger:     log.WithField("context", "contourEventHandler"),
 	}
 
@@ -421,15 +487,17 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 					log.WithError(err).WithField("resource", r).Fatal("failed to create informer")
 				}
 			}
-			// Inform on Namespaces.
-			if err := informOnResource(clients, k8s.NamespacesResource(), &dynamicHandler); err != nil {
-				log.WithError(err).WithField("resource", k8s.NamespacesResource()).Fatal("failed to create informer")
-			}
 		} else {
 			log.Fatalf("GatewayAPI Gateway configured but APIs not installed in cluster.")
 		}
 	}
 
+	// Inform on Namespaces, which are used by Gateway API route selection
+	// and HTTPProxy include namespace selectors.
+	if err := informOnResource(clients, k8s.NamespacesResource(), &dynamicHandler); err != nil {
+		log.WithError(err).WithField("resource", k8s.NamespacesResource()).Fatal("failed to create informer")
+	}
+
 	// Inform on secrets, filtering by root namespaces.
 	for _, r := range k8s.SecretsResources() {
 		var handler cache.ResourceEventHandler = &dynamicHandler
@@ -444,6 +512,20 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		}
 	}
 
+	// Inform on configmaps, filtering by root namespaces.
+	for _, r := range k8s.ConfigMapsResources() {
+		var handler cache.ResourceEventHandler = &dynamicHandler
+
+		// If root namespaces are defined, filter for configmaps in only those namespaces.
+		if len(informerNamespaces) > 0 {
+			handler = k8s.NewNamespaceFilter(informerNamespaces, &dynamicHandler)
+		}
+
+		if err := informOnResource(clients, r, handler); err != nil {
+			log.WithError(err).WithField("resource", r).Fatal("failed to create informer")
+		}
+	}
+
 	// Inform on endpoints.
 	for _, r := range k8s.EndpointsResources() {
 		if err := informOnResource(clients, r, &k8s.DynamicClientHandler{
@@ -616,6 +698,7 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 			// This can't happen due to config validation.
 			log.Fatalf("invalid xDS server type %q", ctx.Config.Server.XDSServerType)
 		}
+		basicAuthServer.Register(grpcServer)
 
 		addr := net.JoinHostPort(ctx.xdsAddr, strconv.Itoa(ctx.xdsPort))
 		l, err := net.Listen("tcp", addr)
@@ -661,7 +744,7 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 	return g.Run(context.Background())
 }
 
-func getDAGBuilder(ctx *serveContext, clients *k8s.Clients, clientCert, fallbackCert *types.NamespacedName, log logrus.FieldLogger) dag.Builder {
+func getDAGBuilder(ctx *serveContext, clients *k8s.Clients, clientCert, fallbackCert, sessionTicketKeys *types.NamespacedName, log logrus.FieldLogger) dag.Builder {
 	var requestHeadersPolicy dag.HeadersPolicy
 	if ctx.Config.Policy.RequestHeadersPolicy.Set != nil {
 		requestHeadersPolicy.Set = make(map[string]string)
@@ -686,6 +769,18 @@ func getDAGBuilder(ctx *serveContext, clients *k8s.Clients, clientCert, fallback
 		responseHeadersPolicy.Remove = append(responseHeadersPolicy.Remove, ctx.Config.Policy.ResponseHeadersPolicy.Remove...)
 	}
 
+	var hstsPolicy *dag.HSTSPolicy
+	if hsts := ctx.Config.Policy.HSTSPolicy; hsts != nil {
+		// The max-age has already been validated with the rest
+		// of the configuration.
+		maxAge, _ := time.ParseDuration(hsts.MaxAge)
+		hstsPolicy = &dag.HSTSPolicy{
+			MaxAge:            maxAge,
+			IncludeSubDomains: hsts.IncludeSubDomains,
+			Preload:           hsts.Preload,
+		}
+	}
+
 	// Get the appropriate DAG processors.
 	dagProcessors := []dag.Processor{
 		&dag.IngressProcessor{
@@ -697,12 +792,17 @@ func getDAGBuilder(ctx *serveContext, clients *k8s.Clients, clientCert, fallback
 			ClientCertificate: clientCert,
 		},
 		&dag.HTTPProxyProcessor{
-			DisablePermitInsecure: ctx.Config.DisablePermitInsecure,
-			FallbackCertificate:   fallbackCert,
-			DNSLookupFamily:       ctx.Config.Cluster.DNSLookupFamily,
-			ClientCertificate:     clientCert,
-			RequestHeadersPolicy:  &requestHeadersPolicy,
-			ResponseHeadersPolicy: &responseHeadersPolicy,
+			DisablePermitInsecure:    ctx.Config.DisablePermitInsecure,
+			FallbackCertificate:      fallbackCert,
+			DNSLookupFamily:          ctx.Config.Cluster.DNSLookupFamily,
+			ClientCertificate:        clientCert,
+			SessionTicketKeys:        sessionTicketKeys,
+			CertificateExpiryWarning: ctx.Config.TLS.CertificateExpiryWarning,
+			Clock:                    time.Now,
+			RequestHeadersPolicy:     &requestHeadersPolicy,
+			ResponseHeadersPolicy:    &responseHeadersPolicy,
+			HSTSPolicy:               hstsPolicy,
+			AdditionalListeners:      additionalListenerProtocols(&ctx.Config),
 		},
 	}
 
@@ -723,6 +823,9 @@ func getDAGBuilder(ctx *serveContext, clients *k8s.Clients, clientCert, fallback
 	if clientCert != nil {
 		configuredSecretRefs = append(configuredSecretRefs, clientCert)
 	}
+	if sessionTicketKeys != nil {
+		configuredSecretRefs = append(configuredSecretRefs, sessionTicketKeys)
+	}
 
 	builder := dag.Builder{
 		Source: dag.KubernetesCache{
@@ -15,8 +15,10 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/pkg/config"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,7 +39,7 @@ func TestGetDAGBuilder(t *testing.T) {
 	}
 
 	t.Run("all default options", func(t *testing.T) {
-		got := getDAGBuilder(newServeContext(), nil, nil, nil, logrus.StandardLogger())
+		got := getDAGBuilder(newServeContext(), nil, nil, nil, nil, logrus.StandardLogger())
 		commonAssertions(t, &got)
 		assert.Empty(t, got.Source.ConfiguredSecretRefs)
 	})
@@ -45,7 +47,7 @@ func TestGetDAGBuilder(t *testing.T) {
 	t.Run("client cert specified", func(t *testing.T) {
 		clientCert := &types.NamespacedName{Namespace: "client-ns", Name: "client-name"}
 
-		got := getDAGBuilder(newServeContext(), nil, clientCert, nil, logrus.StandardLogger())
+		got := getDAGBuilder(newServeContext(), nil, clientCert, nil, nil, logrus.StandardLogger())
 		commonAssertions(t, &got)
 		assert.ElementsMatch(t, got.Source.ConfiguredSecretRefs, []*types.NamespacedName{clientCert})
 	})
@@ -53,7 +55,7 @@ func TestGetDAGBuilder(t *testing.T) {
 	t.Run("fallback cert specified", func(t *testing.T) {
 		fallbackCert := &types.NamespacedName{Namespace: "fallback-ns", Name: "fallback-name"}
 
-		got := getDAGBuilder(newServeContext(), nil, nil, fallbackCert, logrus.StandardLogger())
+		got := getDAGBuilder(newServeContext(), nil, nil, fallbackCert, nil, logrus.StandardLogger())
 		commonAssertions(t, &got)
 		assert.ElementsMatch(t, got.Source.ConfiguredSecretRefs, []*types.NamespacedName{fallbackCert})
 	})
@@ -62,12 +64,24 @@ func TestGetDAGBuilder(t *testing.T) {
 		clientCert := &types.NamespacedName{Namespace: "client-ns", Name: "client-name"}
 		fallbackCert := &types.NamespacedName{Namespace: "fallback-ns", Name: "fallback-name"}
 
-		got := getDAGBuilder(newServeContext(), nil, clientCert, fallbackCert, logrus.StandardLogger())
+		got := getDAGBuilder(newServeContext(), nil, clientCert, fallbackCert, nil, logrus.StandardLogger())
 
 		commonAssertions(t, &got)
 		assert.ElementsMatch(t, got.Source.ConfiguredSecretRefs, []*types.NamespacedName{clientCert, fallbackCert})
 	})
 
+	t.Run("session ticket keys specified", func(t *testing.T) {
+		sessionTicketKeys := &types.NamespacedName{Namespace: "keys-ns", Name: "keys-name"}
+
+		got := getDAGBuilder(newServeContext(), nil, nil, nil, sessionTicketKeys, logrus.StandardLogger())
+
+		commonAssertions(t, &got)
+		assert.ElementsMatch(t, got.Source.ConfiguredSecretRefs, []*types.NamespacedName{sessionTicketKeys})
+
+		httpProxyProcessor := mustGetHTTPProxyProcessor(t, &got)
+		assert.Equal(t, sessionTicketKeys, httpProxyProcessor.SessionTicketKeys)
+	})
+
 	t.Run("request and response headers policy specified", func(t *testing.T) {
 		ctx := newServeContext()
 		ctx.Config.Policy.RequestHeadersPolicy.Set = map[string]string{
@@ -81,7 +95,7 @@ func TestGetDAGBuilder(t *testing.T) {
 		}
 		ctx.Config.Policy.ResponseHeadersPolicy.Remove = []string{"res-remove-key-1", "res-remove-key-2"}
 
-		got := getDAGBuilder(ctx, nil, nil, nil, logrus.StandardLogger())
+		got := getDAGBuilder(ctx, nil, nil, nil, nil, logrus.StandardLogger())
 		commonAssertions(t, &got)
 
 		httpProxyProcessor := mustGetHTTPProxyProcessor(t, &got)
@@ -91,6 +105,50 @@ func TestGetDAGBuilder(t *testing.T) {
 		assert.ElementsMatch(t, ctx.Config.Policy.ResponseHeadersPolicy.Remove, httpProxyProcessor.ResponseHeadersPolicy.Remove)
 	})
 
+	t.Run("HSTS policy specified", func(t *testing.T) {
+		ctx := newServeContext()
+		ctx.Config.Policy.HSTSPolicy = &config.HSTSParameters{
+			MaxAge:            "8760h",
+			IncludeSubDomains: true,
+		}
+
+		got := getDAGBuilder(ctx, nil, nil, nil, nil, logrus.StandardLogger())
+		commonAssertions(t, &got)
+
+		httpProxyProcessor := mustGetHTTPProxyProcessor(t, &got)
+		assert.Equal(t, &dag.HSTSPolicy{
+			MaxAge:            8760 * time.Hour,
+			IncludeSubDomains: true,
+		}, httpProxyProcessor.HSTSPolicy)
+	})
+
+	t.Run("additional listeners specified", func(t *testing.T) {
+		ctx := newServeContext()
+		ctx.Config.Listener.Additional = []config.AdditionalListener{{
+			Name:     "internal",
+			Port:     9080,
+			Protocol: config.HTTPListenerProtocol,
+		}, {
+			Name:     "public",
+			Port:     9443,
+			Protocol: config.HTTPSListenerProtocol,
+		}, {
+			Name:     "dns",
+			Port:     5353,
+			Protocol: config.UDPListenerProtocol,
+		}}
+
+		got := getDAGBuilder(ctx, nil, nil, nil, nil, logrus.StandardLogger())
+		commonAssertions(t, &got)
+
+		httpProxyProcessor := mustGetHTTPProxyProcessor(t, &got)
+		assert.Equal(t, map[string]config.ListenerProtocol{
+			"internal": config.HTTPListenerProtocol,
+			"public":   config.HTTPSListenerProtocol,
+			"dns":      config.UDPListenerProtocol,
+		}, httpProxyProcessor.AdditionalListeners)
+	})
+
 	// TODO(3453): test additional properties of the DAG builder (processor fields, cache fields, Gateway tests (requires a client fake))
 }
 
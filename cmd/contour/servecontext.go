@@ -230,6 +230,7 @@ func (ctx *serveContext) proxyRootNamespaces() []string {
 
 // parseDefaultHTTPVersions parses a list of supported HTTP versions
 //  (of the form "HTTP/xx") into a slice of unique version constants.
+// HTTP/3 is omitted, since it is served by a separate QUIC listener.
 func parseDefaultHTTPVersions(versions []config.HTTPVersionType) []envoy_v3.HTTPVersionType {
 	wanted := map[envoy_v3.HTTPVersionType]struct{}{}
 
@@ -251,6 +252,32 @@ func parseDefaultHTTPVersions(versions []config.HTTPVersionType) []envoy_v3.HTTP
 	return parsed
 }
 
+// http3AdvertisedPort returns the port that HTTP/3 is advertised on,
+// or zero if HTTP/3 is not one of the default HTTP versions.
+func http3AdvertisedPort(params *config.Parameters) int {
+	for _, v := range params.DefaultHTTPVersions {
+		if v == config.HTTPVersion3 {
+			if params.Listener.HTTP3AdvertisedPort != 0 {
+				return params.Listener.HTTP3AdvertisedPort
+			}
+			return 443
+		}
+	}
+
+	return 0
+}
+
+// additionalListenerProtocols returns the protocols of the
+// additional listeners in the configuration, keyed by name.
+func additionalListenerProtocols(params *config.Parameters) map[string]config.ListenerProtocol {
+	protocols := map[string]config.ListenerProtocol{}
+	for _, l := range params.Listener.Additional {
+		protocols[l.Name] = l.Protocol
+	}
+
+	return protocols
+}
+
 func namespacedNameOf(n config.NamespacedName) *types.NamespacedName {
 	if len(strings.TrimSpace(n.Name)) == 0 && len(strings.TrimSpace(n.Namespace)) == 0 {
 		return nil
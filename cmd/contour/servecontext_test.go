@@ -347,6 +347,10 @@ func TestParseHTTPVersions(t *testing.T) {
 				config.HTTPVersion1, config.HTTPVersion2},
 			parseVersions: []envoy_v3.HTTPVersionType{envoy_v3.HTTPVersion1, envoy_v3.HTTPVersion2},
 		},
+		"http/2+http/3": {
+			versions:      []config.HTTPVersionType{config.HTTPVersion2, config.HTTPVersion3},
+			parseVersions: []envoy_v3.HTTPVersionType{envoy_v3.HTTPVersion2},
+		},
 	}
 
 	for name, testcase := range cases {
@@ -364,3 +368,16 @@ func TestParseHTTPVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTP3AdvertisedPort(t *testing.T) {
+	assert.Equal(t, 0, http3AdvertisedPort(&config.Parameters{
+		DefaultHTTPVersions: []config.HTTPVersionType{config.HTTPVersion1, config.HTTPVersion2},
+	}))
+	assert.Equal(t, 443, http3AdvertisedPort(&config.Parameters{
+		DefaultHTTPVersions: []config.HTTPVersionType{config.HTTPVersion2, config.HTTPVersion3},
+	}))
+	assert.Equal(t, 8443, http3AdvertisedPort(&config.Parameters{
+		DefaultHTTPVersions: []config.HTTPVersionType{config.HTTPVersion2, config.HTTPVersion3},
+		Listener:            config.ListenerParameters{HTTP3AdvertisedPort: 8443},
+	}))
+}
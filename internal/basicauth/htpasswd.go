@@ -0,0 +1,128 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauth
+
+import (
+	"crypto/md5"  // nolint:gosec
+	"crypto/sha1" // nolint:gosec
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is verified in place of the hash of an unknown user, so
+// that response times do not reveal which users exist.
+const dummyHash = "$2a$05$HXuQCPJC8ldNxLLSahzbeuogzlxVPlKzu.NgFZb.ZpyjaZTftNWqq"
+
+// verifyPassword returns true if password matches the given htpasswd
+// password hash. Only bcrypt, apr1 and SHA1 hashes are supported.
+func verifyPassword(hash, password string) bool {
+	switch {
+	case strings.HasPrefix(hash, "{SHA}"):
+		digest := sha1.Sum([]byte(password)) // nolint:gosec
+		want := base64.StdEncoding.EncodeToString(digest[:])
+		return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(hash, "{SHA}")), []byte(want)) == 1
+	case strings.HasPrefix(hash, "$apr1$"):
+		parts := strings.Split(strings.TrimPrefix(hash, "$apr1$"), "$")
+		if len(parts) != 2 {
+			return false
+		}
+		return subtle.ConstantTimeCompare([]byte(hash), []byte(apr1(password, parts[0]))) == 1
+	default:
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+}
+
+// apr1Alphabet is the alphabet that apr1 hashes are encoded with.
+const apr1Alphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// apr1 returns the Apache variant of the MD5-based crypt(3) hash of
+// password with the given salt, as generated by `htpasswd -m`.
+func apr1(password string, salt string) string {
+	const magic = "$apr1$"
+
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+
+	pw := []byte(password)
+
+	alt := md5.New() // nolint:gosec
+	alt.Write(pw)
+	alt.Write([]byte(salt))
+	alt.Write(pw)
+	altSum := alt.Sum(nil)
+
+	d := md5.New() // nolint:gosec
+	d.Write(pw)
+	d.Write([]byte(magic))
+	d.Write([]byte(salt))
+	for i := len(pw); i > 0; i -= md5.Size {
+		n := i
+		if n > md5.Size {
+			n = md5.Size
+		}
+		d.Write(altSum[:n])
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			d.Write([]byte{0})
+		} else {
+			d.Write(pw[:1])
+		}
+	}
+	sum := d.Sum(nil)
+
+	// The hash is stretched with 1000 further rounds.
+	for i := 0; i < 1000; i++ {
+		r := md5.New() // nolint:gosec
+		if i&1 != 0 {
+			r.Write(pw)
+		} else {
+			r.Write(sum)
+		}
+		if i%3 != 0 {
+			r.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			r.Write(pw)
+		}
+		if i&1 != 0 {
+			r.Write(sum)
+		} else {
+			r.Write(pw)
+		}
+		sum = r.Sum(nil)
+	}
+
+	var out strings.Builder
+	out.WriteString(magic)
+	out.WriteString(salt)
+	out.WriteString("$")
+
+	encode := func(v uint32, n int) {
+		for ; n > 0; n-- {
+			out.WriteByte(apr1Alphabet[v&0x3f])
+			v >>= 6
+		}
+	}
+	for _, i := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		encode(uint32(sum[i[0]])<<16|uint32(sum[i[1]])<<8|uint32(sum[i[2]]), 4)
+	}
+	encode(uint32(sum[11]), 2)
+
+	return out.String()
+}
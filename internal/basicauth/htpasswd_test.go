@@ -0,0 +1,101 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPR1(t *testing.T) {
+	// Expected hashes are generated with `openssl passwd -apr1`.
+	tests := map[string]struct {
+		password string
+		salt     string
+		want     string
+	}{
+		"short password": {
+			password: "secret",
+			salt:     "yZ6z8qvd",
+			want:     "$apr1$yZ6z8qvd$dr/LHfYmwcWPaABsSd9iZ0",
+		},
+		"empty password": {
+			password: "",
+			salt:     "a",
+			want:     "$apr1$a$lsAcX0kKaMIVmrCtUuk5b0",
+		},
+		"long password": {
+			password: "a very long password that exceeds sixteen bytes",
+			salt:     "s0",
+			want:     "$apr1$s0$45EUw8lzpOPfjHeqSQu1D0",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, apr1(tc.password, tc.salt))
+		})
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	tests := map[string]struct {
+		hash     string
+		password string
+		want     bool
+	}{
+		"SHA1 match": {
+			hash:     "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=",
+			password: "password",
+			want:     true,
+		},
+		"SHA1 mismatch": {
+			hash:     "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=",
+			password: "Password",
+			want:     false,
+		},
+		"apr1 match": {
+			hash:     "$apr1$xxYYzz12$5fkORe2Wk90T9mxxflF9k0",
+			password: "password",
+			want:     true,
+		},
+		"apr1 mismatch": {
+			hash:     "$apr1$xxYYzz12$5fkORe2Wk90T9mxxflF9k0",
+			password: "secret",
+			want:     false,
+		},
+		"bcrypt match": {
+			hash:     "$2a$05$iZr6oayBB3UMUVS55k7hQu4c5Nd7ggcyk/ejnO7waIZ46AR5psq4G",
+			password: "secret",
+			want:     true,
+		},
+		"bcrypt mismatch": {
+			hash:     "$2a$05$iZr6oayBB3UMUVS55k7hQu4c5Nd7ggcyk/ejnO7waIZ46AR5psq4G",
+			password: "password",
+			want:     false,
+		},
+		"plain text": {
+			hash:     "password",
+			password: "password",
+			want:     false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, verifyPassword(tc.hash, tc.password))
+		})
+	}
+}
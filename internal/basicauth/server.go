@@ -0,0 +1,178 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package basicauth implements the authorization service that verifies
+// the HTTP Basic authentication credentials of requests to HTTPProxy
+// routes that require them.
+package basicauth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+)
+
+// Server is an Envoy authorization server that checks the credentials
+// of requests against the users of the basic authentication Secrets
+// in the DAG. Envoy sends it the requests to routes that require basic
+// authentication (see envoy_v3.FilterBasicAuth), so that the password
+// hashes are never part of the Envoy configuration.
+type Server struct {
+	logrus.FieldLogger
+
+	mu sync.RWMutex
+
+	// users maps the "namespace/name" of each basic
+	// authentication Secret to its users' password hashes.
+	users map[string]map[string]string
+}
+
+var _ dag.Observer = &Server{}
+var _ envoy_service_auth_v3.AuthorizationServer = &Server{}
+
+// NewServer returns a new Server that has no credentials until
+// the first DAG is observed.
+func NewServer(log logrus.FieldLogger) *Server {
+	return &Server{
+		FieldLogger: log,
+		users:       map[string]map[string]string{},
+	}
+}
+
+// Register registers the Server with the gRPC runtime.
+func (s *Server) Register(g *grpc.Server) {
+	envoy_service_auth_v3.RegisterAuthorizationServer(g, s)
+}
+
+// OnChange records the credentials of the routes in the DAG.
+func (s *Server) OnChange(d *dag.DAG) {
+	users := map[string]map[string]string{}
+
+	var visit func(dag.Vertex)
+	visit = func(v dag.Vertex) {
+		if r, ok := v.(*dag.Route); ok {
+			if r.BasicAuth != nil {
+				users[r.BasicAuth.Secret.String()] = r.BasicAuth.Users
+			}
+			return
+		}
+		v.Visit(visit)
+	}
+	d.Visit(visit)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = users
+}
+
+// Check verifies the credentials of the request against the users of
+// the Secret that is named in the request context. Requests with
+// missing or invalid credentials are denied with a 401 response that
+// challenges the client to authenticate.
+func (s *Server) Check(ctx context.Context, req *envoy_service_auth_v3.CheckRequest) (*envoy_service_auth_v3.CheckResponse, error) {
+	attrs := req.GetAttributes()
+	secret := attrs.GetContextExtensions()[envoy_v3.BasicAuthSecretKey]
+	realm := attrs.GetContextExtensions()[envoy_v3.BasicAuthRealmKey]
+
+	s.mu.RLock()
+	users, ok := s.users[secret]
+	s.mu.RUnlock()
+
+	if !ok {
+		// Envoy only sends requests for routes that
+		// require basic authentication, so the route
+		// configuration must be stale.
+		s.WithField("secret", secret).Debug("no basic authentication credentials for request")
+		return unauthorized(realm), nil
+	}
+
+	user, password, ok := parseBasicAuth(attrs.GetRequest().GetHttp().GetHeaders()["authorization"])
+	if !ok {
+		return unauthorized(realm), nil
+	}
+
+	hash, ok := users[user]
+	if !ok {
+		// Verify a password anyway, so that the
+		// response time does not reveal that the
+		// user does not exist.
+		verifyPassword(dummyHash, password)
+		return unauthorized(realm), nil
+	}
+
+	if !verifyPassword(hash, password) {
+		return unauthorized(realm), nil
+	}
+
+	return &envoy_service_auth_v3.CheckResponse{
+		Status: &status.Status{Code: int32(code.Code_OK)},
+		HttpResponse: &envoy_service_auth_v3.CheckResponse_OkResponse{
+			OkResponse: &envoy_service_auth_v3.OkHttpResponse{},
+		},
+	}, nil
+}
+
+// parseBasicAuth returns the user name and password of an HTTP Basic
+// Authorization header value.
+func parseBasicAuth(header string) (string, string, bool) {
+	const prefix = "basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(header[len(prefix):]))
+	if err != nil {
+		return "", "", false
+	}
+
+	colon := strings.IndexByte(string(decoded), ':')
+	if colon < 0 {
+		return "", "", false
+	}
+
+	return string(decoded[:colon]), string(decoded[colon+1:]), true
+}
+
+// unauthorized returns a response that denies the request and
+// challenges the client to authenticate in the given realm.
+func unauthorized(realm string) *envoy_service_auth_v3.CheckResponse {
+	return &envoy_service_auth_v3.CheckResponse{
+		Status: &status.Status{Code: int32(code.Code_UNAUTHENTICATED)},
+		HttpResponse: &envoy_service_auth_v3.CheckResponse_DeniedResponse{
+			DeniedResponse: &envoy_service_auth_v3.DeniedHttpResponse{
+				Status: &envoy_type_v3.HttpStatus{
+					Code: envoy_type_v3.StatusCode_Unauthorized,
+				},
+				Headers: []*envoy_core_v3.HeaderValueOption{{
+					Header: &envoy_core_v3.HeaderValue{
+						Key:   "WWW-Authenticate",
+						Value: fmt.Sprintf(`Basic realm="%s"`, realm),
+					},
+				}},
+				Body: "unauthorized",
+			},
+		},
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basicauth
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_service_auth_v3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestServerCheck(t *testing.T) {
+	s := NewServer(fixture.NewTestLogger(t))
+	s.OnChange(buildDAG(t,
+		fixture.SecretRootsCert,
+		fixture.NewService("roots/app").
+			WithPorts(v1.ServicePort{Port: 80}),
+		&v1.Secret{
+			ObjectMeta: fixture.ObjectMeta("roots/htpasswd"),
+			Type:       v1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				dag.BasicAuthKey: []byte(
+					"alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n" +
+						"bob:$apr1$xxYYzz12$5fkORe2Wk90T9mxxflF9k0\n" +
+						"carol:$2a$05$iZr6oayBB3UMUVS55k7hQu4c5Nd7ggcyk/ejnO7waIZ46AR5psq4G\n"),
+			},
+		},
+		fixture.NewProxy("roots/app").
+			WithFQDN("app.example.com").
+			WithCertificate("ssl-cert").
+			WithBasicAuth(contour_api_v1.BasicAuth{SecretName: "htpasswd", Realm: "app"}).
+			WithSpec(contour_api_v1.HTTPProxySpec{
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{Name: "app", Port: 80}},
+				}},
+			}),
+	))
+
+	basic := func(user, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	}
+
+	tests := map[string]struct {
+		secret        string
+		authorization string
+		allowed       bool
+	}{
+		"SHA1 user": {
+			secret:        "roots/htpasswd",
+			authorization: basic("alice", "password"),
+			allowed:       true,
+		},
+		"apr1 user": {
+			secret:        "roots/htpasswd",
+			authorization: basic("bob", "password"),
+			allowed:       true,
+		},
+		"bcrypt user": {
+			secret:        "roots/htpasswd",
+			authorization: basic("carol", "secret"),
+			allowed:       true,
+		},
+		"wrong password": {
+			secret:        "roots/htpasswd",
+			authorization: basic("carol", "password"),
+			allowed:       false,
+		},
+		"unknown user": {
+			secret:        "roots/htpasswd",
+			authorization: basic("dave", "password"),
+			allowed:       false,
+		},
+		"missing credentials": {
+			secret:  "roots/htpasswd",
+			allowed: false,
+		},
+		"bearer credentials": {
+			secret:        "roots/htpasswd",
+			authorization: "Bearer abc",
+			allowed:       false,
+		},
+		"unknown secret": {
+			secret:        "roots/other",
+			authorization: basic("alice", "password"),
+			allowed:       false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := &envoy_service_auth_v3.CheckRequest{
+				Attributes: &envoy_service_auth_v3.AttributeContext{
+					Request: &envoy_service_auth_v3.AttributeContext_Request{
+						Http: &envoy_service_auth_v3.AttributeContext_HttpRequest{
+							Headers: map[string]string{},
+						},
+					},
+					ContextExtensions: map[string]string{
+						envoy_v3.BasicAuthSecretKey: tc.secret,
+						envoy_v3.BasicAuthRealmKey:  "app",
+					},
+				},
+			}
+			if tc.authorization != "" {
+				req.Attributes.Request.Http.Headers["authorization"] = tc.authorization
+			}
+
+			got, err := s.Check(context.Background(), req)
+			require.NoError(t, err)
+
+			if tc.allowed {
+				assert.Equal(t, int32(code.Code_OK), got.GetStatus().GetCode())
+				return
+			}
+
+			protobuf.ExpectEqual(t, &envoy_service_auth_v3.CheckResponse{
+				Status: &status.Status{Code: int32(code.Code_UNAUTHENTICATED)},
+				HttpResponse: &envoy_service_auth_v3.CheckResponse_DeniedResponse{
+					DeniedResponse: &envoy_service_auth_v3.DeniedHttpResponse{
+						Status: &envoy_type_v3.HttpStatus{
+							Code: envoy_type_v3.StatusCode_Unauthorized,
+						},
+						Headers: []*envoy_core_v3.HeaderValueOption{{
+							Header: &envoy_core_v3.HeaderValue{
+								Key:   "WWW-Authenticate",
+								Value: `Basic realm="app"`,
+							},
+						}},
+						Body: "unauthorized",
+					},
+				},
+			}, got)
+		})
+	}
+}
+
+func buildDAG(t *testing.T, objs ...interface{}) *dag.DAG {
+	builder := dag.Builder{
+		Source: dag.KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []dag.Processor{
+			&dag.HTTPProxyProcessor{
+				Clock: fixture.Clock,
+			},
+			&dag.ListenerProcessor{},
+		},
+	}
+
+	for _, o := range objs {
+		builder.Source.Insert(o)
+	}
+	return builder.Build()
+}
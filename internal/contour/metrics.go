@@ -80,6 +80,8 @@ func (m *RebuildMetricsObserver) OnChange(d *dag.DAG) {
 	m.NextObserver.OnChange(d)
 	timer.ObserveDuration()
 
+	m.Metrics.SetCertificateExpiryMetric(calculateCertificateMetric(d))
+
 	select {
 	// If we are leader, the IsLeader channel is closed.
 	case <-m.IsLeader:
@@ -88,6 +90,40 @@ func (m *RebuildMetricsObserver) OnChange(d *dag.DAG) {
 	}
 }
 
+// calculateCertificateMetric returns the expiry times of the
+// certificates served by the secure virtual hosts of the DAG.
+func calculateCertificateMetric(d *dag.DAG) map[metrics.CertificateMeta]time.Time {
+	expiries := make(map[metrics.CertificateMeta]time.Time)
+
+	var visit func(dag.Vertex)
+	visit = func(vertex dag.Vertex) {
+		svh, ok := vertex.(*dag.SecureVirtualHost)
+		if !ok {
+			vertex.Visit(visit)
+			return
+		}
+
+		for _, secret := range append([]*dag.Secret{svh.Secret}, svh.AdditionalSecrets...) {
+			// The secret is nil when TLS is passed through.
+			if secret == nil {
+				continue
+			}
+			cert, err := secret.Certificate()
+			if err != nil {
+				continue
+			}
+			expiries[metrics.CertificateMeta{
+				VHost:     svh.Name,
+				Namespace: secret.Namespace(),
+				Name:      secret.Name(),
+			}] = cert.NotAfter
+		}
+	}
+	d.Visit(visit)
+
+	return expiries
+}
+
 func calculateRouteMetric(updates []*status.ProxyUpdate) metrics.RouteMetric {
 	proxyMetricTotal := make(map[metrics.Meta]int)
 	proxyMetricValid := make(map[metrics.Meta]int)
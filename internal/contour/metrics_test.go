@@ -15,6 +15,7 @@ package contour
 
 import (
 	"testing"
+	"time"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/dag"
@@ -53,7 +54,9 @@ func TestHTTPProxyMetrics(t *testing.T) {
 					&dag.IngressProcessor{
 						FieldLogger: fixture.NewTestLogger(t),
 					},
-					&dag.HTTPProxyProcessor{},
+					&dag.HTTPProxyProcessor{
+						Clock: fixture.Clock,
+					},
 					&dag.ListenerProcessor{},
 				},
 			}
@@ -558,3 +561,44 @@ func TestHTTPProxyMetrics(t *testing.T) {
 		},
 	})
 }
+
+func TestCertificateMetrics(t *testing.T) {
+	builder := dag.Builder{
+		Source: dag.KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []dag.Processor{
+			&dag.HTTPProxyProcessor{
+				Clock: fixture.Clock,
+			},
+			&dag.ListenerProcessor{},
+		},
+	}
+
+	builder.Source.Insert(fixture.SecretRootsCert)
+	builder.Source.Insert(fixture.ServiceRootsKuard)
+	builder.Source.Insert(&contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fixture.SecretRootsCert.Namespace,
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: fixture.SecretRootsCert.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	})
+
+	assert.Equal(t, map[metrics.CertificateMeta]time.Time{
+		{VHost: "example.com", Namespace: fixture.SecretRootsCert.Namespace, Name: fixture.SecretRootsCert.Name}: time.Date(2029, 12, 2, 1, 34, 33, 0, time.UTC),
+	}, calculateCertificateMetric(builder.Build()))
+}
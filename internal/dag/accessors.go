@@ -18,7 +18,6 @@ import (
 	"strconv"
 
 	"github.com/projectcontour/contour/internal/annotation"
-	"github.com/projectcontour/contour/internal/k8s"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -29,6 +28,7 @@ type RouteServiceName struct {
 	Name      string
 	Namespace string
 	Port      int32
+	Protocol  v1.Protocol
 }
 
 // GetServices returns all services in the DAG.
@@ -39,17 +39,18 @@ func (dag *DAG) GetServices() map[RouteServiceName]*Service {
 }
 
 // GetService returns the service in the DAG that matches the provided
-// namespace, name and port, or nil if no matching service is found.
+// namespace, name and TCP port, or nil if no matching service is found.
 func (dag *DAG) GetService(meta types.NamespacedName, port int32) *Service {
 	return dag.GetServices()[RouteServiceName{
 		Name:      meta.Name,
 		Namespace: meta.Namespace,
 		Port:      port,
+		Protocol:  v1.ProtocolTCP,
 	}]
 }
 
 // EnsureService looks for a Kubernetes service in the cache matching the provided
-// namespace, name and port, and returns a DAG service for it. If a matching service
+// namespace, name and TCP port, and returns a DAG service for it. If a matching service
 // cannot be found in the cache, an error is returned.
 func (dag *DAG) EnsureService(meta types.NamespacedName, port intstr.IntOrString, cache *KubernetesCache) (*Service, error) {
 	svc, svcPort, err := cache.LookupService(meta, port)
@@ -57,8 +58,29 @@ func (dag *DAG) EnsureService(meta types.NamespacedName, port intstr.IntOrString
 		return nil, err
 	}
 
-	if dagSvc := dag.GetService(k8s.NamespacedNameOf(svc), svcPort.Port); dagSvc != nil {
-		return dagSvc, nil
+	return dag.ensureService(svc, svcPort), nil
+}
+
+// EnsureUDPService looks for a Kubernetes service in the cache matching the provided
+// namespace, name and UDP port, and returns a DAG service for it. If a matching service
+// cannot be found in the cache, an error is returned.
+func (dag *DAG) EnsureUDPService(meta types.NamespacedName, port intstr.IntOrString, cache *KubernetesCache) (*Service, error) {
+	svc, svcPort, err := cache.LookupUDPService(meta, port)
+	if err != nil {
+		return nil, err
+	}
+
+	return dag.ensureService(svc, svcPort), nil
+}
+
+func (dag *DAG) ensureService(svc *v1.Service, svcPort v1.ServicePort) *Service {
+	if dagSvc := dag.GetServices()[RouteServiceName{
+		Name:      svc.Name,
+		Namespace: svc.Namespace,
+		Port:      svcPort.Port,
+		Protocol:  servicePortProtocol(svcPort),
+	}]; dagSvc != nil {
+		return dagSvc
 	}
 
 	dagSvc := &Service{
@@ -75,7 +97,7 @@ func (dag *DAG) EnsureService(meta types.NamespacedName, port intstr.IntOrString
 		MaxRetries:         annotation.MaxRetries(svc),
 		ExternalName:       externalName(svc),
 	}
-	return dagSvc, nil
+	return dagSvc
 }
 
 func upstreamProtocol(svc *v1.Service, port v1.ServicePort) string {
@@ -105,6 +127,7 @@ func (s serviceGetter) visit(vertex Vertex) {
 			Name:      obj.Weighted.ServiceName,
 			Namespace: obj.Weighted.ServiceNamespace,
 			Port:      obj.Weighted.ServicePort.Port,
+			Protocol:  servicePortProtocol(obj.Weighted.ServicePort),
 		}] = obj
 	default:
 		vertex.Visit(s.visit)
@@ -2285,6 +2285,7 @@ func TestDAGInsertGatewayAPI(t *testing.T) {
 						FieldLogger: fixture.NewTestLogger(t),
 					},
 					&HTTPProxyProcessor{
+						Clock:                 fixture.Clock,
 						DisablePermitInsecure: tc.disablePermitInsecure,
 						FallbackCertificate: &types.NamespacedName{
 							Name:      tc.fallbackCertificateName,
@@ -3951,6 +3952,98 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
+	secECDSA := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ecdsa",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(fixture.EC_CERTIFICATE, fixture.EC_PRIVATE_KEY),
+	}
+
+	proxyAliases := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.com",
+				Aliases: []string{"WWW.example.com"},
+				TLS: &contour_api_v1.TLS{
+					SecretName: secECDSA.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	proxyAliasNotInCert := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.com",
+				Aliases: []string{"example.org"},
+				TLS: &contour_api_v1.TLS{
+					SecretName: secECDSA.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	secExpired := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "expired",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(fixture.SelfSignedCertificate(fixture.Now.Add(-time.Hour), "example.com"), fixture.RSA_PRIVATE_KEY),
+	}
+
+	proxyExpiredCert := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: secExpired.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
 	proxyMinTLSInvalid := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "example-com",
@@ -4377,6 +4470,77 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
+	selectorChild := func(namespace, name, team, prefix string) *contour_api_v1.HTTPProxy {
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"team": team},
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				Routes: []contour_api_v1.Route{{
+					Conditions: []contour_api_v1.MatchCondition{{
+						Prefix: prefix,
+					}},
+					Services: []contour_api_v1.Service{{
+						Name: "kuard",
+						Port: 8080,
+					}},
+				}},
+			},
+		}
+	}
+
+	proxyIncludeSelector := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "root",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "a"},
+				},
+			}},
+		},
+	}
+
+	proxyIncludeNamespaceSelector := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "root",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "a"},
+				},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"tenant": "true"},
+				},
+			}},
+		},
+	}
+
+	nsTeamA := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "teama",
+			Labels: map[string]string{"tenant": "true"},
+		},
+	}
+
+	nsTeamB := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "teamb",
+		},
+	}
+
 	s14 := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "nginx",
@@ -4867,6 +5031,63 @@ func TestDAGInsert(t *testing.T) {
 		},
 	}
 
+	proxy17spiffe := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+					UpstreamValidation: &contour_api_v1.UpstreamValidation{
+						CACertificate: cert1.Name,
+						SubjectNames: []contour_api_v1.SubjectNameMatch{{
+							Exact: "spiffe://cluster.local/ns/default/sa/kuard",
+						}, {
+							Prefix: "spiffe://cluster.local/ns/kuard/",
+						}},
+					},
+				}},
+			}},
+		},
+	}
+
+	proxy17badsan := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+					UpstreamValidation: &contour_api_v1.UpstreamValidation{
+						CACertificate: cert1.Name,
+						SubjectNames: []contour_api_v1.SubjectNameMatch{{
+							Exact:  "spiffe://cluster.local/ns/default/sa/kuard",
+							Prefix: "spiffe://cluster.local/ns/kuard/",
+						}},
+					},
+				}},
+			}},
+		},
+	}
+
 	// proxy18 is downstream validation, HTTP route
 	proxy18 := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -7157,6 +7378,107 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
+		"insert httpproxy including children by label selector": {
+			objs: []interface{}{
+				proxyIncludeSelector,
+				selectorChild("default", "kuard-b", "a", "/b"),
+				selectorChild("default", "kuard-a", "a", "/a"),
+				selectorChild("default", "kuard-other-team", "b", "/other-team"),
+				selectorChild("teama", "kuard-other-namespace", "a", "/other-namespace"),
+				s1,
+				s12,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com",
+							prefixroute("/a", service(s1)),
+							prefixroute("/b", service(s1)),
+						),
+					),
+				},
+			),
+		},
+		"insert httpproxy including children by label and namespace selector": {
+			objs: []interface{}{
+				proxyIncludeNamespaceSelector,
+				selectorChild("default", "kuard", "a", "/default"),
+				selectorChild("teama", "kuard", "a", "/teama"),
+				selectorChild("teamb", "kuard", "a", "/teamb"),
+				nsTeamA,
+				nsTeamB,
+				s1,
+				s12,
+				s13,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", prefixroute("/teama", service(s12))),
+					),
+				},
+			),
+		},
+		"insert httpproxy with aliases": {
+			objs: []interface{}{
+				proxyAliases, s1, secECDSA,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", routeUpgrade("/", service(s1))),
+						virtualhost("www.example.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Port: 443,
+					VirtualHosts: virtualhosts(
+						securevirtualhost("example.com", secECDSA, routeUpgrade("/", service(s1))),
+						securevirtualhost("www.example.com", secECDSA, routeUpgrade("/", service(s1))),
+					),
+				},
+			),
+		},
+		"insert httpproxy with alias not valid for certificate": {
+			objs: []interface{}{
+				proxyAliasNotInCert, s1, secECDSA,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", routeUpgrade("/", service(s1))),
+						virtualhost("example.org", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Port: 443,
+					VirtualHosts: virtualhosts(
+						securevirtualhost("example.com", secECDSA, routeUpgrade("/", service(s1))),
+						securevirtualhost("example.org", secECDSA, routeUpgrade("/", service(s1))),
+					),
+				},
+			),
+		},
+		"insert httpproxy with expired certificate": {
+			objs: []interface{}{
+				proxyExpiredCert, s1, secExpired,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", routeUpgrade("/", service(s1))),
+					),
+				}, &Listener{
+					Port: 443,
+					VirtualHosts: virtualhosts(
+						securevirtualhost("example.com", secExpired, routeUpgrade("/", service(s1))),
+					),
+				},
+			),
+		},
 		"insert httpproxy with invalid tls version": {
 			objs: []interface{}{
 				proxyMinTLSInvalid, s1, sec1,
@@ -8524,6 +8846,50 @@ func TestDAGInsert(t *testing.T) {
 			},
 			want: listeners(), //no listeners, missing certificate
 		},
+		"insert httpproxy expecting upstream verification of multiple subject names": {
+			objs: []interface{}{
+				cert1, proxy17spiffe, s1a,
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com",
+							routeCluster("/",
+								&Cluster{
+									Upstream: &Service{
+										Protocol: "tls",
+										Weighted: WeightedService{
+											Weight:           1,
+											ServiceName:      s1a.Name,
+											ServiceNamespace: s1a.Namespace,
+											ServicePort:      s1a.Spec.Ports[0],
+										},
+									},
+									Protocol: "tls",
+									UpstreamValidation: &PeerValidationContext{
+										CACertificate: secret(cert1),
+										SubjectNames: []SubjectNameMatch{{
+											MatchType: SubjectNameMatchTypeExact,
+											Value:     "spiffe://cluster.local/ns/default/sa/kuard",
+										}, {
+											MatchType: SubjectNameMatchTypePrefix,
+											Value:     "spiffe://cluster.local/ns/kuard/",
+										}},
+									},
+								},
+							),
+						),
+					),
+				},
+			),
+		},
+		"insert httpproxy expecting upstream verification, ambiguous subject name match": {
+			objs: []interface{}{
+				cert1, proxy17badsan, s1a,
+			},
+			want: listeners(), // no listeners, invalid subject name match
+		},
 		"insert httpproxy expecting upstream verification, no annotation on service": {
 			objs: []interface{}{
 				cert1, proxy17, s1,
@@ -10194,6 +10560,7 @@ func TestDAGInsert(t *testing.T) {
 						FieldLogger: fixture.NewTestLogger(t),
 					},
 					&HTTPProxyProcessor{
+						Clock:                 fixture.Clock,
 						DisablePermitInsecure: tc.disablePermitInsecure,
 						FallbackCertificate: &types.NamespacedName{
 							Name:      tc.fallbackCertificateName,
@@ -10403,7 +10770,9 @@ func TestDAGRootNamespaces(t *testing.T) {
 					&IngressProcessor{
 						FieldLogger: fixture.NewTestLogger(t),
 					},
-					&HTTPProxyProcessor{},
+					&HTTPProxyProcessor{
+						Clock: fixture.Clock,
+					},
 					&ListenerProcessor{},
 				},
 			}
@@ -10862,6 +11231,210 @@ func TestBuilderRunsProcessorsInOrder(t *testing.T) {
 	assert.Equal(t, []string{"foo", "bar", "baz", "abc", "def"}, got)
 }
 
+func TestDAGAliasesCarryVirtualHostPolicies(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     8080,
+			}},
+		},
+	}
+
+	sec := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ecdsa",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(fixture.EC_CERTIFICATE, fixture.EC_PRIVATE_KEY),
+	}
+
+	proxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.com",
+				Aliases: []string{"www.example.com"},
+				TLS: &contour_api_v1.TLS{
+					SecretName:             sec.Name,
+					MinimumProtocolVersion: "1.3",
+				},
+				CORSPolicy: &contour_api_v1.CORSPolicy{
+					AllowOrigin:  []string{"*"},
+					AllowMethods: []contour_api_v1.CORSHeaderValue{"GET"},
+				},
+				RateLimitPolicy: &contour_api_v1.RateLimitPolicy{
+					Local: &contour_api_v1.LocalRateLimitPolicy{
+						Requests: 10,
+						Unit:     "second",
+					},
+				},
+				CSRFPolicy: &contour_api_v1.CSRFPolicy{
+					Shadow: true,
+				},
+				HSTSPolicy: &contour_api_v1.HSTSPolicy{
+					MaxAge: "1h",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: svc.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	builder := Builder{
+		Source: KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []Processor{
+			&HTTPProxyProcessor{
+				Clock: fixture.Clock,
+			},
+			&ListenerProcessor{},
+		},
+	}
+	for _, o := range []interface{}{svc, sec, proxy} {
+		builder.Source.Insert(o)
+	}
+	dag := builder.Build()
+
+	insecure := dag.GetVirtualHost(ListenerName{Name: "example.com", ListenerName: "ingress_http"})
+	insecureAlias := dag.GetVirtualHost(ListenerName{Name: "www.example.com", ListenerName: "ingress_http"})
+	secure := dag.GetSecureVirtualHost(ListenerName{Name: "example.com", ListenerName: "ingress_https"})
+	secureAlias := dag.GetSecureVirtualHost(ListenerName{Name: "www.example.com", ListenerName: "ingress_https"})
+
+	assert.NotNil(t, insecure.CORSPolicy)
+	assert.NotNil(t, insecure.RateLimitPolicy)
+	assert.NotNil(t, insecure.CSRFPolicy)
+	assert.NotNil(t, secure.HSTSPolicy)
+	assert.Equal(t, "1.3", secure.MinTLSVersion)
+
+	// Apart from their names, the aliases must be identical to
+	// the virtual hosts that they alias.
+	insecureAlias.Name = insecure.Name
+	assert.Equal(t, insecure, insecureAlias)
+
+	secureAlias.Name = secure.Name
+	assert.Equal(t, secure, secureAlias)
+}
+
+func TestDAGIncludedRoutesAssignedPriorityRange(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     8080,
+			}},
+		},
+	}
+
+	root := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-com",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name: "child",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/child",
+				}},
+				PriorityRange: &contour_api_v1.RoutePriorityRange{
+					Min: 10,
+					Max: 20,
+				},
+			}},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: svc.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	child := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "child",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/default",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: svc.Name,
+					Port: 8080,
+				}},
+			}, {
+				Priority: 15,
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/explicit",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: svc.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	builder := Builder{
+		Source: KubernetesCache{
+			FieldLogger: fixture.NewTestLogger(t),
+		},
+		Processors: []Processor{
+			&HTTPProxyProcessor{
+				Clock: fixture.Clock,
+			},
+			&ListenerProcessor{},
+		},
+	}
+	for _, o := range []interface{}{svc, root, child} {
+		builder.Source.Insert(o)
+	}
+	dag := builder.Build()
+
+	priorities := map[string]int32{}
+	vhost := dag.GetVirtualHost(ListenerName{Name: "example.com", ListenerName: "ingress_http"})
+	for _, r := range vhost.routes {
+		priorities[r.PathMatchCondition.(*PrefixMatchCondition).Prefix] = r.Priority
+	}
+
+	// Routes of the root keep the default priority, but the
+	// included routes without a priority are assigned the
+	// nearest bound of their range.
+	assert.Equal(t, map[string]int32{
+		"/":               0,
+		"/child/default":  10,
+		"/child/explicit": 15,
+	}, priorities)
+}
+
 func routes(routes ...*Route) map[string]*Route {
 	if len(routes) == 0 {
 		return nil
@@ -54,9 +54,11 @@ type KubernetesCache struct {
 	ConfiguredSecretRefs []*types.NamespacedName
 
 	ingresses                 map[types.NamespacedName]*networking_v1.Ingress
+	acmesolveringresses       map[types.NamespacedName]*networking_v1.Ingress
 	ingressclass              *networking_v1.IngressClass
 	httpproxies               map[types.NamespacedName]*contour_api_v1.HTTPProxy
 	secrets                   map[types.NamespacedName]*v1.Secret
+	configmaps                map[types.NamespacedName]*v1.ConfigMap
 	tlscertificatedelegations map[types.NamespacedName]*contour_api_v1.TLSCertificateDelegation
 	services                  map[types.NamespacedName]*v1.Service
 	namespaces                map[string]*v1.Namespace
@@ -76,8 +78,10 @@ type KubernetesCache struct {
 // init creates the internal cache storage. It is called implicitly from the public API.
 func (kc *KubernetesCache) init() {
 	kc.ingresses = make(map[types.NamespacedName]*networking_v1.Ingress)
+	kc.acmesolveringresses = make(map[types.NamespacedName]*networking_v1.Ingress)
 	kc.httpproxies = make(map[types.NamespacedName]*contour_api_v1.HTTPProxy)
 	kc.secrets = make(map[types.NamespacedName]*v1.Secret)
+	kc.configmaps = make(map[types.NamespacedName]*v1.ConfigMap)
 	kc.tlscertificatedelegations = make(map[types.NamespacedName]*contour_api_v1.TLSCertificateDelegation)
 	kc.services = make(map[types.NamespacedName]*v1.Service)
 	kc.namespaces = make(map[string]*v1.Namespace)
@@ -201,6 +205,24 @@ func (kc *KubernetesCache) Insert(obj interface{}) bool {
 
 		kc.secrets[k8s.NamespacedNameOf(obj)] = obj
 		return kc.secretTriggersRebuild(obj)
+	case *v1.ConfigMap:
+		// Only ConfigMaps holding a JWKS or a CRL are interesting.
+		_, isJWKS := obj.Data[JWKSKey]
+		_, isCRL := obj.Data[CRLKey]
+		if !isJWKS && !isCRL {
+			return false
+		}
+
+		name := k8s.NamespacedNameOf(obj)
+		kc.configmaps[name] = obj
+
+		jwksRebuild := isJWKS && kc.jwksTriggersRebuild(name, func(jwks *contour_api_v1.LocalJWKS) string {
+			return jwks.ConfigMapName
+		})
+		crlRebuild := isCRL && kc.crlTriggersRebuild(name, func(crl *contour_api_v1.CertificateRevocationList) string {
+			return crl.ConfigMapName
+		})
+		return jwksRebuild || crlRebuild
 	case *v1.Service:
 		kc.services[k8s.NamespacedNameOf(obj)] = obj
 		return kc.serviceTriggersRebuild(obj)
@@ -210,16 +232,9 @@ func (kc *KubernetesCache) Insert(obj interface{}) bool {
 	case *v1beta1.Ingress:
 		// Convert the v1beta1 object to v1 before adding to the
 		// local ingress cache for easier processing later on.
-		objV1 := toV1Ingress(obj)
-		if kc.ingressMatchesIngressClass(objV1) {
-			kc.ingresses[k8s.NamespacedNameOf(objV1)] = objV1
-			return true
-		}
+		return kc.insertIngress(toV1Ingress(obj))
 	case *networking_v1.Ingress:
-		if kc.ingressMatchesIngressClass(obj) {
-			kc.ingresses[k8s.NamespacedNameOf(obj)] = obj
-			return true
-		}
+		return kc.insertIngress(obj)
 	case *networking_v1.IngressClass:
 		if kc.matchesIngressClass(obj) {
 			kc.ingressclass = obj
@@ -274,6 +289,36 @@ func (kc *KubernetesCache) Insert(obj interface{}) bool {
 	return false
 }
 
+// insertIngress adds the Ingress to the cache if it matches the
+// ingress class. cert-manager's HTTP-01 solver Ingresses are also kept
+// regardless of their class, so that HTTPProxies can route challenges
+// to the solvers.
+func (kc *KubernetesCache) insertIngress(obj *networking_v1.Ingress) bool {
+	m := k8s.NamespacedNameOf(obj)
+
+	solver := obj.Labels[acmeSolverIngressLabel] == "true"
+	if solver {
+		kc.acmesolveringresses[m] = obj
+	} else {
+		delete(kc.acmesolveringresses, m)
+	}
+
+	if kc.ingressMatchesIngressClass(obj) {
+		kc.ingresses[m] = obj
+		return true
+	}
+	return solver
+}
+
+// removeIngress removes the named Ingress from the cache.
+func (kc *KubernetesCache) removeIngress(m types.NamespacedName) bool {
+	_, ok := kc.ingresses[m]
+	_, solver := kc.acmesolveringresses[m]
+	delete(kc.ingresses, m)
+	delete(kc.acmesolveringresses, m)
+	return ok || solver
+}
+
 func toV1Ingress(obj *v1beta1.Ingress) *networking_v1.Ingress {
 
 	if obj == nil {
@@ -391,6 +436,11 @@ func (kc *KubernetesCache) remove(obj interface{}) bool {
 		_, ok := kc.secrets[m]
 		delete(kc.secrets, m)
 		return ok
+	case *v1.ConfigMap:
+		m := k8s.NamespacedNameOf(obj)
+		_, ok := kc.configmaps[m]
+		delete(kc.configmaps, m)
+		return ok
 	case *v1.Service:
 		m := k8s.NamespacedNameOf(obj)
 		_, ok := kc.services[m]
@@ -401,15 +451,9 @@ func (kc *KubernetesCache) remove(obj interface{}) bool {
 		delete(kc.namespaces, obj.Name)
 		return ok
 	case *v1beta1.Ingress:
-		m := k8s.NamespacedNameOf(obj)
-		_, ok := kc.ingresses[m]
-		delete(kc.ingresses, m)
-		return ok
+		return kc.removeIngress(k8s.NamespacedNameOf(obj))
 	case *networking_v1.Ingress:
-		m := k8s.NamespacedNameOf(obj)
-		_, ok := kc.ingresses[m]
-		delete(kc.ingresses, m)
-		return ok
+		return kc.removeIngress(k8s.NamespacedNameOf(obj))
 	case *networking_v1.IngressClass:
 		if kc.matchesIngressClass(obj) {
 			kc.ingressclass = nil
@@ -553,6 +597,26 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 		return true
 	}
 
+	if _, isJWKS := secret.Data[JWKSKey]; isJWKS {
+		return kc.jwksTriggersRebuild(k8s.NamespacedNameOf(secret), func(jwks *contour_api_v1.LocalJWKS) string {
+			return jwks.SecretName
+		})
+	}
+
+	if _, isCRL := secret.Data[CRLKey]; isCRL {
+		return kc.crlTriggersRebuild(k8s.NamespacedNameOf(secret), func(crl *contour_api_v1.CertificateRevocationList) string {
+			return crl.SecretName
+		})
+	}
+
+	if _, isBasicAuth := secret.Data[BasicAuthKey]; isBasicAuth {
+		return kc.basicAuthTriggersRebuild(secret)
+	}
+
+	if _, isSessionTicketKeys := secret.Data[SessionTicketKeysKey]; isSessionTicketKeys {
+		return kc.sessionTicketKeysTriggersRebuild(secret)
+	}
+
 	delegations := make(map[string]bool) // targetnamespace/secretname to bool
 
 	// TODO(youngnick): Check if this is required.
@@ -601,17 +665,19 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 			continue
 		}
 
-		if proxy.Namespace == secret.Namespace && tls.SecretName == secret.Name {
-			return true
-		}
-		if delegations[proxy.Namespace+"/"+secret.Name] {
-			if tls.SecretName == secret.Namespace+"/"+secret.Name {
+		for _, name := range append([]string{tls.SecretName}, tls.AdditionalSecretNames...) {
+			if proxy.Namespace == secret.Namespace && name == secret.Name {
 				return true
 			}
-		}
-		if delegations["*/"+secret.Name] {
-			if tls.SecretName == secret.Namespace+"/"+secret.Name {
-				return true
+			if delegations[proxy.Namespace+"/"+secret.Name] {
+				if name == secret.Namespace+"/"+secret.Name {
+					return true
+				}
+			}
+			if delegations["*/"+secret.Name] {
+				if name == secret.Namespace+"/"+secret.Name {
+					return true
+				}
 			}
 		}
 	}
@@ -644,6 +710,95 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 	return false
 }
 
+// jwksTriggersRebuild returns true if the named object is used as a
+// local JWKS by a root HTTPProxy in the same namespace. The refName
+// function returns the name referenced by the LocalJWKS.
+func (kc *KubernetesCache) jwksTriggersRebuild(name types.NamespacedName, refName func(*contour_api_v1.LocalJWKS) string) bool {
+	for _, proxy := range kc.httpproxies {
+		if proxy.Namespace != name.Namespace || proxy.Spec.VirtualHost == nil {
+			continue
+		}
+
+		for _, provider := range proxy.Spec.VirtualHost.JWTProviders {
+			if provider.LocalJWKS != nil && refName(provider.LocalJWKS) == name.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// crlTriggersRebuild returns true if the named object holds the
+// certificate revocation list used to validate client certificates
+// by a root HTTPProxy in the same namespace. The refName function
+// returns the name referenced by the CertificateRevocationList.
+func (kc *KubernetesCache) crlTriggersRebuild(name types.NamespacedName, refName func(*contour_api_v1.CertificateRevocationList) string) bool {
+	for _, proxy := range kc.httpproxies {
+		if proxy.Namespace != name.Namespace || proxy.Spec.VirtualHost == nil || proxy.Spec.VirtualHost.TLS == nil {
+			continue
+		}
+
+		if cv := proxy.Spec.VirtualHost.TLS.ClientValidation; cv != nil && cv.CertificateRevocationList != nil {
+			if refName(cv.CertificateRevocationList) == name.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// basicAuthTriggersRebuild returns true if the secret holds the
+// basic authentication credentials of a virtual host or route of
+// an HTTPProxy in the same namespace.
+func (kc *KubernetesCache) basicAuthTriggersRebuild(secret *v1.Secret) bool {
+	for _, proxy := range kc.httpproxies {
+		if proxy.Namespace != secret.Namespace {
+			continue
+		}
+
+		if vh := proxy.Spec.VirtualHost; vh != nil && vh.BasicAuth != nil && vh.BasicAuth.SecretName == secret.Name {
+			return true
+		}
+
+		for _, route := range proxy.Spec.Routes {
+			if route.BasicAuth != nil && route.BasicAuth.SecretName == secret.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sessionTicketKeysTriggersRebuild returns true if the session
+// ticket keys secret is referenced by the configuration file, or by
+// an HTTPProxy in the same namespace or one that it is delegated to.
+func (kc *KubernetesCache) sessionTicketKeysTriggersRebuild(secret *v1.Secret) bool {
+	name := k8s.NamespacedNameOf(secret)
+
+	for _, s := range kc.ConfiguredSecretRefs {
+		if *s == name {
+			return true
+		}
+	}
+
+	for _, proxy := range kc.httpproxies {
+		vh := proxy.Spec.VirtualHost
+		if vh == nil || vh.TLS == nil || vh.TLS.SessionTicketKeysSecretName == "" {
+			continue
+		}
+
+		ref := k8s.NamespacedNameFrom(vh.TLS.SessionTicketKeysSecretName, k8s.DefaultNamespace(proxy.Namespace))
+		if ref == name && kc.DelegationPermitted(name, proxy.Namespace) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // LookupSecret returns a Secret if present or nil if the underlying kubernetes
 // secret fails validation or is missing.
 func (kc *KubernetesCache) LookupSecret(name types.NamespacedName, validate func(*v1.Secret) error) (*Secret, error) {
@@ -663,6 +818,17 @@ func (kc *KubernetesCache) LookupSecret(name types.NamespacedName, validate func
 	return s, nil
 }
 
+// LookupConfigMap returns the ConfigMap with the given name, or an
+// error if it is missing.
+func (kc *KubernetesCache) LookupConfigMap(name types.NamespacedName) (*v1.ConfigMap, error) {
+	cm, ok := kc.configmaps[name]
+	if !ok {
+		return nil, fmt.Errorf("ConfigMap not found")
+	}
+
+	return cm, nil
+}
+
 func (kc *KubernetesCache) LookupUpstreamValidation(uv *contour_api_v1.UpstreamValidation, namespace string) (*PeerValidationContext, error) {
 	if uv == nil {
 		// no upstream validation requested, nothing to do
@@ -676,17 +842,54 @@ func (kc *KubernetesCache) LookupUpstreamValidation(uv *contour_api_v1.UpstreamV
 		return nil, fmt.Errorf("invalid CA Secret %q: %s", secretName, err)
 	}
 
-	if uv.SubjectName == "" {
+	if uv.SubjectName == "" && len(uv.SubjectNames) == 0 {
 		// UpstreamValidation is requested, but SAN is not provided
 		return nil, errors.New("missing subject alternative name")
 	}
 
+	subjectNames, err := subjectNameMatches(uv.SubjectNames)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PeerValidationContext{
 		CACertificate: cacert,
 		SubjectName:   uv.SubjectName,
+		SubjectNames:  subjectNames,
 	}, nil
 }
 
+// subjectNameMatches converts a list of API subject alt name matches to
+// their DAG equivalent, returning an error if any match is malformed.
+func subjectNameMatches(matches []contour_api_v1.SubjectNameMatch) ([]SubjectNameMatch, error) {
+	var names []SubjectNameMatch
+
+	for i, m := range matches {
+		var found []SubjectNameMatch
+
+		if m.Exact != "" {
+			found = append(found, SubjectNameMatch{MatchType: SubjectNameMatchTypeExact, Value: m.Exact})
+		}
+		if m.Prefix != "" {
+			found = append(found, SubjectNameMatch{MatchType: SubjectNameMatchTypePrefix, Value: m.Prefix})
+		}
+		if m.Regex != "" {
+			if err := ValidateRegex(m.Regex); err != nil {
+				return nil, fmt.Errorf("subject alternative name %d: invalid regex %q: %s", i, m.Regex, err)
+			}
+			found = append(found, SubjectNameMatch{MatchType: SubjectNameMatchTypeRegex, Value: m.Regex})
+		}
+
+		if len(found) != 1 {
+			return nil, fmt.Errorf("subject alternative name %d: exactly one of exact, prefix or regex must be specified", i)
+		}
+
+		names = append(names, found[0])
+	}
+
+	return names, nil
+}
+
 // DelegationPermitted returns true if the referenced secret has been delegated
 // to the namespace where the ingress object is located.
 func (kc *KubernetesCache) DelegationPermitted(secret types.NamespacedName, targetNamespace string) bool {
@@ -722,6 +925,38 @@ func (kc *KubernetesCache) DelegationPermitted(secret types.NamespacedName, targ
 	return false
 }
 
+func validJWKS(s *v1.Secret) error {
+	if len(s.Data[JWKSKey]) == 0 {
+		return fmt.Errorf("empty %q key", JWKSKey)
+	}
+
+	return nil
+}
+
+func validBasicAuth(s *v1.Secret) error {
+	if len(s.Data[BasicAuthKey]) == 0 {
+		return fmt.Errorf("empty %q key", BasicAuthKey)
+	}
+
+	return nil
+}
+
+func validSessionTicketKeys(s *v1.Secret) error {
+	if len(s.Data[SessionTicketKeysKey]) == 0 {
+		return fmt.Errorf("empty %q key", SessionTicketKeysKey)
+	}
+
+	return nil
+}
+
+func validCRL(s *v1.Secret) error {
+	if len(s.Data[CRLKey]) == 0 {
+		return fmt.Errorf("empty %q key", CRLKey)
+	}
+
+	return nil
+}
+
 func validCA(s *v1.Secret) error {
 	if len(s.Data[CACertificateKey]) == 0 {
 		return fmt.Errorf("empty %q key", CACertificateKey)
@@ -730,25 +965,50 @@ func validCA(s *v1.Secret) error {
 	return nil
 }
 
-// LookupService returns the Kubernetes service and port matching the provided parameters,
+// LookupService returns the Kubernetes service and TCP port matching the provided parameters,
 // or an error if a match can't be found.
 func (kc *KubernetesCache) LookupService(meta types.NamespacedName, port intstr.IntOrString) (*v1.Service, v1.ServicePort, error) {
+	return kc.lookupService(meta, port, v1.ProtocolTCP)
+}
+
+// LookupUDPService returns the Kubernetes service and UDP port matching the provided parameters,
+// or an error if a match can't be found.
+func (kc *KubernetesCache) LookupUDPService(meta types.NamespacedName, port intstr.IntOrString) (*v1.Service, v1.ServicePort, error) {
+	return kc.lookupService(meta, port, v1.ProtocolUDP)
+}
+
+func (kc *KubernetesCache) lookupService(meta types.NamespacedName, port intstr.IntOrString, protocol v1.Protocol) (*v1.Service, v1.ServicePort, error) {
 	svc, ok := kc.services[meta]
 	if !ok {
 		return nil, v1.ServicePort{}, fmt.Errorf("service %q not found", meta)
 	}
 
+	// A Service may define the same port number for several
+	// protocols, so only fail if none of them match.
+	var err error
 	for i := range svc.Spec.Ports {
 		p := svc.Spec.Ports[i]
 		if int(p.Port) == port.IntValue() || port.String() == p.Name {
-			switch p.Protocol {
-			case "", v1.ProtocolTCP:
+			if servicePortProtocol(p) == protocol {
 				return svc, p, nil
-			default:
-				return nil, v1.ServicePort{}, fmt.Errorf("unsupported service protocol %q", p.Protocol)
+			}
+			if err == nil {
+				err = fmt.Errorf("unsupported service protocol %q", servicePortProtocol(p))
 			}
 		}
 	}
+	if err != nil {
+		return nil, v1.ServicePort{}, err
+	}
 
 	return nil, v1.ServicePort{}, fmt.Errorf("port %q on service %q not matched", port.String(), meta)
 }
+
+// servicePortProtocol returns the protocol of the service port,
+// which defaults to TCP.
+func servicePortProtocol(port v1.ServicePort) v1.Protocol {
+	if port.Protocol == "" {
+		return v1.ProtocolTCP
+	}
+	return port.Protocol
+}
@@ -101,6 +101,198 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			want: false,
 		},
 
+		"insert JWKS secret not referenced": {
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "jwks",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					JWKSKey: []byte(`{"keys":[]}`),
+				},
+			},
+			want: false,
+		},
+		"insert JWKS secret w/ invalid JSON": {
+			pre: []interface{}{
+				jwksProxy(&contour_api_v1.LocalJWKS{SecretName: "jwks"}),
+			},
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "jwks",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					JWKSKey: []byte(`{"keys":`),
+				},
+			},
+			want: false,
+		},
+		"insert JWKS secret referenced by httpproxy": {
+			pre: []interface{}{
+				jwksProxy(&contour_api_v1.LocalJWKS{SecretName: "jwks"}),
+			},
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "jwks",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					JWKSKey: []byte(`{"keys":[]}`),
+				},
+			},
+			want: true,
+		},
+		"insert basic auth secret referenced by httpproxy route": {
+			pre: []interface{}{
+				&contour_api_v1.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "child",
+						Namespace: "default",
+					},
+					Spec: contour_api_v1.HTTPProxySpec{
+						Routes: []contour_api_v1.Route{{
+							BasicAuth: &contour_api_v1.BasicAuth{SecretName: "htpasswd"},
+						}},
+					},
+				},
+			},
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "htpasswd",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n"),
+				},
+			},
+			want: true,
+		},
+		"insert basic auth secret not referenced": {
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "htpasswd",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n"),
+				},
+			},
+			want: false,
+		},
+		"insert configmap without JWKS": {
+			pre: []interface{}{
+				jwksProxy(&contour_api_v1.LocalJWKS{ConfigMapName: "jwks"}),
+			},
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "jwks",
+					Namespace: "default",
+				},
+				Data: map[string]string{
+					"config": "value",
+				},
+			},
+			want: false,
+		},
+		"insert JWKS configmap referenced by httpproxy in another namespace": {
+			pre: []interface{}{
+				jwksProxy(&contour_api_v1.LocalJWKS{ConfigMapName: "jwks"}),
+			},
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "jwks",
+					Namespace: "other",
+				},
+				Data: map[string]string{
+					JWKSKey: `{"keys":[]}`,
+				},
+			},
+			want: false,
+		},
+		"insert JWKS configmap referenced by httpproxy": {
+			pre: []interface{}{
+				jwksProxy(&contour_api_v1.LocalJWKS{ConfigMapName: "jwks"}),
+			},
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "jwks",
+					Namespace: "default",
+				},
+				Data: map[string]string{
+					JWKSKey: `{"keys":[]}`,
+				},
+			},
+			want: true,
+		},
+		"insert CRL secret referenced by httpproxy": {
+			pre: []interface{}{
+				crlProxy(&contour_api_v1.CertificateRevocationList{SecretName: "crl"}),
+			},
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "crl",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					CRLKey: []byte(fixture.CRL),
+				},
+			},
+			want: true,
+		},
+		"insert CRL secret w/ invalid CRL": {
+			pre: []interface{}{
+				crlProxy(&contour_api_v1.CertificateRevocationList{SecretName: "crl"}),
+			},
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "crl",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					CRLKey: []byte(fixture.CERTIFICATE),
+				},
+			},
+			want: false,
+		},
+		"insert CRL configmap not referenced": {
+			pre: []interface{}{
+				crlProxy(&contour_api_v1.CertificateRevocationList{ConfigMapName: "crl"}),
+			},
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other",
+					Namespace: "default",
+				},
+				Data: map[string]string{
+					CRLKey: fixture.CRL,
+				},
+			},
+			want: false,
+		},
+		"insert CRL configmap referenced by httpproxy": {
+			pre: []interface{}{
+				crlProxy(&contour_api_v1.CertificateRevocationList{ConfigMapName: "crl"}),
+			},
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "crl",
+					Namespace: "default",
+				},
+				Data: map[string]string{
+					CRLKey: fixture.CRL,
+				},
+			},
+			want: true,
+		},
+
 		"insert secret referenced by ingress": {
 			pre: []interface{}{
 				&v1beta1.Ingress{
@@ -541,6 +733,21 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			},
 			want: false,
 		},
+		"insert ingressv1 cert-manager solver with incorrect kubernetes.io/ingress.class": {
+			obj: &networking_v1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cm-acme-http-solver-abcde",
+					Namespace: "default",
+					Labels: map[string]string{
+						"acme.cert-manager.io/http01-solver": "true",
+					},
+					Annotations: map[string]string{
+						"kubernetes.io/ingress.class": "nginx",
+					},
+				},
+			},
+			want: true,
+		},
 		"insert ingressv1 explicit kubernetes.io/ingress.class": {
 			obj: &networking_v1.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
@@ -992,6 +1199,47 @@ func TestKubernetesCacheInsert(t *testing.T) {
 	}
 }
 
+// jwksProxy returns a root HTTPProxy in the default namespace
+// that verifies JWTs with the given local JWKS.
+func jwksProxy(jwks *contour_api_v1.LocalJWKS) *contour_api_v1.HTTPProxy {
+	return &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jwt",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				JWTProviders: []contour_api_v1.JWTProvider{{
+					Name:      "provider",
+					LocalJWKS: jwks,
+				}},
+			},
+		},
+	}
+}
+
+func crlProxy(crl *contour_api_v1.CertificateRevocationList) *contour_api_v1.HTTPProxy {
+	return &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "crl",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "tls",
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate:             "ca",
+						CertificateRevocationList: crl,
+					},
+				},
+			},
+		},
+	}
+}
+
 func TestKubernetesCacheRemove(t *testing.T) {
 	cache := func(objs ...interface{}) *KubernetesCache {
 		cache := KubernetesCache{
@@ -1140,6 +1388,27 @@ func TestKubernetesCacheRemove(t *testing.T) {
 			},
 			want: false,
 		},
+		"remove cert-manager solver ingress incorrect ingressclass": {
+			cache: cache(&networking_v1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cm-acme-http-solver-abcde",
+					Namespace: "default",
+					Labels: map[string]string{
+						"acme.cert-manager.io/http01-solver": "true",
+					},
+					Annotations: map[string]string{
+						"kubernetes.io/ingress.class": "nginx",
+					},
+				},
+			}),
+			obj: &networking_v1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cm-acme-http-solver-abcde",
+					Namespace: "default",
+				},
+			},
+			want: true,
+		},
 		"remove httpproxy": {
 			cache: cache(&contour_api_v1.HTTPProxy{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1325,6 +1594,7 @@ func TestLookupService(t *testing.T) {
 		cache    *KubernetesCache
 		meta     types.NamespacedName
 		port     intstr.IntOrString
+		udp      bool
 		wantSvc  *v1.Service
 		wantPort v1.ServicePort
 		wantErr  error
@@ -1362,6 +1632,28 @@ func TestLookupService(t *testing.T) {
 			wantSvc: service("default", "service-1", port("http", 80, v1.ProtocolTCP)),
 			wantErr: errors.New(`unsupported service protocol "UDP"`),
 		},
+		"service port with the same number for TCP and UDP, lookup by port num": {
+			cache:    cache(service("default", "dns", port("dns-udp", 53, v1.ProtocolUDP), port("dns-tcp", 53, v1.ProtocolTCP))),
+			meta:     types.NamespacedName{Namespace: "default", Name: "dns"},
+			port:     intstr.FromInt(53),
+			wantSvc:  service("default", "dns", port("dns-udp", 53, v1.ProtocolUDP), port("dns-tcp", 53, v1.ProtocolTCP)),
+			wantPort: port("dns-tcp", 53, v1.ProtocolTCP),
+		},
+		"UDP service port, lookup by port num": {
+			cache:    cache(service("default", "dns", port("dns-udp", 53, v1.ProtocolUDP), port("dns-tcp", 53, v1.ProtocolTCP))),
+			meta:     types.NamespacedName{Namespace: "default", Name: "dns"},
+			port:     intstr.FromInt(53),
+			udp:      true,
+			wantSvc:  service("default", "dns", port("dns-udp", 53, v1.ProtocolUDP), port("dns-tcp", 53, v1.ProtocolTCP)),
+			wantPort: port("dns-udp", 53, v1.ProtocolUDP),
+		},
+		"UDP service port, lookup of TCP port by name": {
+			cache:   cache(service("default", "dns", port("dns-udp", 53, v1.ProtocolUDP), port("dns-tcp", 53, ""))),
+			meta:    types.NamespacedName{Namespace: "default", Name: "dns"},
+			port:    intstr.FromString("dns-tcp"),
+			udp:     true,
+			wantErr: errors.New(`unsupported service protocol "TCP"`),
+		},
 		"service does not exist": {
 			cache:   cache(service("default", "service-1", port("http", 80, v1.ProtocolTCP))),
 			meta:    types.NamespacedName{Namespace: "default", Name: "nonexistent-service"},
@@ -1372,7 +1664,11 @@ func TestLookupService(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			gotSvc, gotPort, gotErr := tc.cache.LookupService(tc.meta, tc.port)
+			lookup := tc.cache.LookupService
+			if tc.udp {
+				lookup = tc.cache.LookupUDPService
+			}
+			gotSvc, gotPort, gotErr := lookup(tc.meta, tc.port)
 
 			switch {
 			case tc.wantErr != nil:
@@ -1686,6 +1982,11 @@ func TestSecretTriggersRebuild(t *testing.T) {
 		}
 	}
 
+	additionalSecrets := func(proxy *contour_api_v1.HTTPProxy, secretNames ...string) *contour_api_v1.HTTPProxy {
+		proxy.Spec.VirtualHost.TLS.AdditionalSecretNames = secretNames
+		return proxy
+	}
+
 	tests := map[string]struct {
 		cache  *KubernetesCache
 		secret *v1.Secret
@@ -1777,6 +2078,28 @@ func TestSecretTriggersRebuild(t *testing.T) {
 			secret: secret("default", "tlscert"),
 			want:   true,
 		},
+		"httpproxy additional secret triggers rebuild": {
+			cache: cache(
+				additionalSecrets(httpProxy("default", "proxy", "tlscert"), "tlscert-ecdsa"),
+			),
+			secret: secret("default", "tlscert-ecdsa"),
+			want:   true,
+		},
+		"httpproxy with delegated additional secret triggers rebuild": {
+			cache: cache(
+				tlsCertificateDelegation("default", "tlscert-ecdsa", "user"),
+				additionalSecrets(httpProxy("user", "ingress", "tlscert"), "default/tlscert-ecdsa"),
+			),
+			secret: secret("default", "tlscert-ecdsa"),
+			want:   true,
+		},
+		"httpproxy with undelegated additional secret does not trigger rebuild": {
+			cache: cache(
+				additionalSecrets(httpProxy("user", "ingress", "tlscert"), "default/tlscert-ecdsa"),
+			),
+			secret: secret("default", "tlscert-ecdsa"),
+			want:   false,
+		},
 		"configuration file secret triggers rebuild": {
 			cache: &KubernetesCache{
 				FieldLogger: fixture.NewTestLogger(t),
@@ -18,6 +18,7 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -218,6 +219,11 @@ type Route struct {
 	// AuthContext sets the authorization context (if authorization is enabled).
 	AuthContext map[string]string
 
+	// AuthorizationServer overrides the authorization server of
+	// the virtual host for this route. If nil, requests are sent
+	// to the authorization server of the virtual host.
+	AuthorizationServer *ExternalAuthorization
+
 	// Is this a websocket route?
 	// TODO(dfc) this should go on the service
 	Websocket bool
@@ -243,6 +249,10 @@ type Route struct {
 	// RateLimitPolicy defines if/how requests for the route are rate limited.
 	RateLimitPolicy *RateLimitPolicy
 
+	// CSRFPolicy overrides the CSRF policy of the virtual host
+	// for the route.
+	CSRFPolicy *CSRFPolicy
+
 	// RequestHashPolicies is a list of policies for configuring hashes on
 	// request attributes.
 	RequestHashPolicies []RequestHashPolicy
@@ -251,6 +261,50 @@ type Route struct {
 	// to be the response to a route request vs routing to
 	// an envoy cluster.
 	DirectResponse *DirectResponse
+
+	// Priority orders the route before routes with a lower
+	// priority, regardless of their match conditions.
+	Priority int32
+
+	// JWTProvider names the JWT provider that verifies JWTs
+	// on requests to this route. If empty, JWTs are not verified.
+	JWTProvider string
+
+	// JWTAllowMissing allows requests to this route that do
+	// not have a JWT. A JWT that is present is still verified.
+	JWTAllowMissing bool
+
+	// BasicAuth requires HTTP Basic authentication for
+	// requests to this route.
+	BasicAuth *BasicAuth
+
+	// IPFilterPolicy restricts the clients that can send
+	// requests to this route by their IP address.
+	IPFilterPolicy *IPFilterPolicy
+}
+
+// BasicAuth defines the credentials that are accepted by
+// HTTP Basic authentication.
+type BasicAuth struct {
+	// Realm is the authentication realm sent to clients.
+	Realm string
+
+	// Secret is the name of the Secret holding the credentials.
+	Secret types.NamespacedName
+
+	// Users maps each user name to the htpasswd hash of
+	// their password.
+	Users map[string]string
+}
+
+// IPFilterPolicy restricts access by the IP address of the client.
+type IPFilterPolicy struct {
+	// Allow is the set of permitted client address ranges.
+	// If empty, all addresses that are not denied are permitted.
+	Allow []*net.IPNet
+
+	// Deny is the set of rejected client address ranges.
+	Deny []*net.IPNet
 }
 
 // HasPathPrefix returns whether this route has a PrefixPathCondition.
@@ -415,6 +469,40 @@ type CORSPolicy struct {
 	MaxAge timeout.Setting
 }
 
+// CSRFPolicy defines the origin checks of the CSRF filter.
+type CSRFPolicy struct {
+	// EnforcementPercentage is the percentage of requests
+	// whose origin is enforced.
+	EnforcementPercentage uint32
+	// Shadow evaluates the origin of requests that are not
+	// enforced without rejecting them.
+	Shadow bool
+	// AdditionalOrigins are allowed in addition to the
+	// destination host.
+	AdditionalOrigins []OriginMatch
+}
+
+const (
+	// OriginMatchTypeExact matches an origin exactly.
+	OriginMatchTypeExact = "exact"
+
+	// OriginMatchTypeSuffix matches an origin if it ends
+	// with the value.
+	OriginMatchTypeSuffix = "suffix"
+
+	// OriginMatchTypeRegex matches an origin if it matches
+	// the value as a regular expression.
+	OriginMatchTypeRegex = "regex"
+)
+
+// OriginMatch holds a match on the origin of a request.
+type OriginMatch struct {
+	// MatchType is one of the OriginMatchType constants.
+	MatchType string
+	// Value is the string or regular expression to match.
+	Value string
+}
+
 type HeaderValue struct {
 	// Name represents a key of a header
 	Key string
@@ -430,9 +518,70 @@ type PeerValidationContext struct {
 	// SubjectName holds an optional subject name which Envoy will check against the
 	// certificate presented by the upstream.
 	SubjectName string
+	// SubjectNames holds an optional list of subject alternative name matches
+	// which Envoy will check against the certificate presented by the upstream
+	// in addition to SubjectName.
+	SubjectNames []SubjectNameMatch
 	// SkipClientCertValidation when set to true will ensure Envoy requests but
 	// does not verify peer certificates.
 	SkipClientCertValidation bool
+	// OptionalClientCertificate when set to true will ensure Envoy requests
+	// but does not require peer certificates.
+	OptionalClientCertificate bool
+	// CRL holds an optional PEM encoded certificate revocation list which
+	// Envoy will check peer certificates against.
+	CRL []byte
+}
+
+const (
+	// ForwardClientCertificateSanitize removes the XFCC header.
+	ForwardClientCertificateSanitize = "Sanitize"
+
+	// ForwardClientCertificateForward forwards the XFCC header.
+	ForwardClientCertificateForward = "Forward"
+
+	// ForwardClientCertificateAppend appends the client
+	// certificate details to the XFCC header.
+	ForwardClientCertificateAppend = "Append"
+
+	// ForwardClientCertificateSanitizeSet replaces the XFCC
+	// header with the client certificate details.
+	ForwardClientCertificateSanitizeSet = "SanitizeSet"
+)
+
+// ForwardClientCertificate configures the x-forwarded-client-cert
+// (XFCC) header that is sent to upstream services.
+type ForwardClientCertificate struct {
+	// Mode is one of the ForwardClientCertificate* constants.
+	Mode string
+
+	// The client certificate details that are added to the
+	// XFCC header by the Append and SanitizeSet modes.
+	Subject bool
+	URI     bool
+	DNS     bool
+	Cert    bool
+	Chain   bool
+}
+
+const (
+	// SubjectNameMatchTypeExact matches a subject alt name exactly.
+	SubjectNameMatchTypeExact = "exact"
+
+	// SubjectNameMatchTypePrefix matches a subject alt name if it starts
+	// with the provided value.
+	SubjectNameMatchTypePrefix = "prefix"
+
+	// SubjectNameMatchTypeRegex matches a subject alt name if it matches
+	// the provided regular expression.
+	SubjectNameMatchTypeRegex = "regex"
+)
+
+// SubjectNameMatch matches a subject alternative name of a peer
+// certificate by MatchType.
+type SubjectNameMatch struct {
+	MatchType string
+	Value     string
 }
 
 // GetCACertificate returns the CA certificate from PeerValidationContext.
@@ -453,6 +602,25 @@ func (pvc *PeerValidationContext) GetSubjectName() string {
 	return pvc.SubjectName
 }
 
+// GetSubjectNames returns the set of subject alt name matches from
+// PeerValidationContext. SubjectName, if present, is returned as
+// the first exact match.
+func (pvc *PeerValidationContext) GetSubjectNames() []SubjectNameMatch {
+	if pvc == nil {
+		// No validation required.
+		return nil
+	}
+
+	var names []SubjectNameMatch
+	if len(pvc.SubjectName) > 0 {
+		names = append(names, SubjectNameMatch{
+			MatchType: SubjectNameMatchTypeExact,
+			Value:     pvc.SubjectName,
+		})
+	}
+	return append(names, pvc.SubjectNames...)
+}
+
 func (r *Route) Visit(f func(Vertex)) {
 	for _, c := range r.Clusters {
 		f(c)
@@ -479,6 +647,9 @@ type VirtualHost struct {
 	// are rate limited.
 	RateLimitPolicy *RateLimitPolicy
 
+	// CSRFPolicy defines the CSRF protection of the virtual host.
+	CSRFPolicy *CSRFPolicy
+
 	routes map[string]*Route
 }
 
@@ -508,6 +679,42 @@ func (v *VirtualHost) Valid() bool {
 	return len(v.routes) > 0
 }
 
+// ExternalAuthorization configures an external authorization
+// server that client requests are forwarded to.
+type ExternalAuthorization struct {
+	// AuthorizationService points to the extension that client
+	// requests are forwarded to for authorization.
+	AuthorizationService *ExtensionCluster
+
+	// AuthorizationResponseTimeout sets how long the proxy should wait
+	// for authorization server responses.
+	AuthorizationResponseTimeout timeout.Setting
+
+	// AuthorizationFailOpen sets whether authorization server
+	// failures should cause the client request to also fail. The
+	// only reason to set this to `true` is when you are migrating
+	// from internal to external authorization.
+	AuthorizationFailOpen bool
+
+	// AuthorizationServerWithRequestBody configures the request
+	// body that is sent to the authorization server. If nil, the
+	// request body is not sent.
+	AuthorizationServerWithRequestBody *AuthorizationServerBufferSettings
+
+	// AuthorizationAllowedHeaders is the list of request headers
+	// that are sent to the authorization server. If empty, all
+	// request headers are sent.
+	AuthorizationAllowedHeaders []string
+}
+
+// AuthorizationServerBufferSettings configures how the request
+// body is buffered and sent to the authorization server.
+type AuthorizationServerBufferSettings struct {
+	MaxRequestBytes     uint32
+	AllowPartialMessage bool
+	PackAsBytes         bool
+}
+
 // A SecureVirtualHost represents a HTTP host protected by TLS.
 type SecureVirtualHost struct {
 	VirtualHost
@@ -515,32 +722,118 @@ type SecureVirtualHost struct {
 	// TLS minimum protocol version. Defaults to envoy_tls_v3.TlsParameters_TLS_AUTO
 	MinTLSVersion string
 
+	// TLS maximum protocol version. If blank, TLS 1.3 is the
+	// maximum.
+	MaxTLSVersion string
+
+	// CipherSuites are the TLS 1.2 cipher suites for this host.
+	// If nil, the globally configured cipher suites are used.
+	CipherSuites []string
+
+	// OCSPStaplePolicy is how OCSP responses in the secrets are
+	// stapled. If blank, stapling is lenient.
+	OCSPStaplePolicy string
+
 	// The cert and key for this host.
 	Secret *Secret
 
+	// AdditionalSecrets are further certs and keys for this
+	// host, each with a different key type than Secret.
+	AdditionalSecrets []*Secret
+
 	// FallbackCertificate
 	FallbackCertificate *Secret
 
+	// SessionTicketKeys are the keys used to encrypt and decrypt
+	// TLS session tickets for this host. If nil, each Envoy
+	// generates its own keys.
+	SessionTicketKeys *Secret
+
 	// Service to TCP proxy all incoming connections.
 	*TCPProxy
 
 	// DownstreamValidation defines how to verify the client's certificate.
 	DownstreamValidation *PeerValidationContext
 
-	// AuthorizationService points to the extension that client
-	// requests are forwarded to for authorization. If nil, no
+	// ForwardClientCertificate configures the XFCC header. If
+	// nil, the XFCC header is removed from requests.
+	ForwardClientCertificate *ForwardClientCertificate
+
+	// ExternalAuthorization configures the authorization server
+	// for this host. If its AuthorizationService is nil, no
 	// authorization is enabled for this host.
-	AuthorizationService *ExtensionCluster
+	ExternalAuthorization
 
-	// AuthorizationResponseTimeout sets how long the proxy should wait
-	// for authorization server responses.
-	AuthorizationResponseTimeout timeout.Setting
+	// JWTProviders specify how to verify JWTs.
+	JWTProviders []JWTProvider
 
-	// AuthorizationFailOpen sets whether authorization server
-	// failures should cause the client request to also fail. The
-	// only reason to set this to `true` is when you are migrating
-	// from internal to external authorization.
-	AuthorizationFailOpen bool
+	// HSTSPolicy configures the Strict-Transport-Security
+	// header added to responses. If nil, no header is added.
+	HSTSPolicy *HSTSPolicy
+}
+
+// HSTSPolicy defines the HTTP Strict Transport Security policy
+// of a secure virtual host.
+type HSTSPolicy struct {
+	// MaxAge is how long browsers should only access the
+	// virtual host over TLS.
+	MaxAge time.Duration
+
+	// IncludeSubDomains applies the policy to all subdomains.
+	IncludeSubDomains bool
+
+	// Preload allows the virtual host to be preloaded by browsers.
+	Preload bool
+}
+
+// JWTProvider defines how to verify JWTs on requests.
+type JWTProvider struct {
+	// Name is the unique name of the provider.
+	Name string
+
+	// Issuer is the required value of the JWT "iss" claim.
+	// If empty, the issuer is not checked.
+	Issuer string
+
+	// Audiences are the allowed values of the JWT "aud" claim.
+	// If empty, the audience is not checked.
+	Audiences []string
+
+	// RemoteJWKS fetches the JWKS from an HTTP endpoint.
+	// Exactly one of RemoteJWKS or LocalJWKS is set.
+	RemoteJWKS *RemoteJWKS
+
+	// LocalJWKS is the contents of the JWKS.
+	LocalJWKS string
+
+	// ForwardJWT retains the JWT on requests that are
+	// forwarded to the upstream.
+	ForwardJWT bool
+
+	// ClaimsToHeaders copies JWT claims into request headers.
+	ClaimsToHeaders []JWTClaimToHeader
+}
+
+// RemoteJWKS defines how to fetch a JWKS from an HTTP endpoint.
+type RemoteJWKS struct {
+	// URI is the URI of the JWKS.
+	URI string
+
+	// Cluster is the extension cluster that the JWKS is fetched from.
+	Cluster *ExtensionCluster
+
+	// Timeout is how long to wait for the JWKS to be fetched.
+	Timeout time.Duration
+
+	// CacheDuration is how long to cache the JWKS. If zero,
+	// the Envoy default is used.
+	CacheDuration time.Duration
+}
+
+// JWTClaimToHeader copies a JWT claim into a request header.
+type JWTClaimToHeader struct {
+	Claim  string
+	Header string
 }
 
 func (s *SecureVirtualHost) Visit(f func(Vertex)) {
@@ -551,6 +844,9 @@ func (s *SecureVirtualHost) Visit(f func(Vertex)) {
 	if s.Secret != nil {
 		f(s.Secret) // secret is not required if vhost is using tls passthrough
 	}
+	for _, secret := range s.AdditionalSecrets {
+		f(secret)
+	}
 }
 
 func (s *SecureVirtualHost) Valid() bool {
@@ -591,6 +887,10 @@ type TCPProxy struct {
 	// Clusters is the, possibly weighted, set
 	// of upstream services to forward decrypted traffic.
 	Clusters []*Cluster
+
+	// IPFilterPolicy restricts the clients that can
+	// connect by their IP address.
+	IPFilterPolicy *IPFilterPolicy
 }
 
 func (t *TCPProxy) Visit(f func(Vertex)) {
@@ -599,6 +899,31 @@ func (t *TCPProxy) Visit(f func(Vertex)) {
 	}
 }
 
+// UDPProxy forwards the datagrams received on a UDP
+// listener to a cluster of UDP endpoints.
+type UDPProxy struct {
+
+	// ListenerName is the name of the UDP listener
+	// that the proxy is bound to.
+	ListenerName string
+
+	// Cluster is the upstream service that
+	// datagrams are forwarded to.
+	Cluster *Cluster
+
+	// SessionIdleTimeout is how long a session is kept
+	// without datagrams. If zero, the Envoy default is used.
+	SessionIdleTimeout time.Duration
+
+	// HashSourceIP selects the endpoint of each session
+	// by a hash of the client's IP address.
+	HashSourceIP bool
+}
+
+func (u *UDPProxy) Visit(f func(Vertex)) {
+	f(u.Cluster)
+}
+
 // Service represents a single Kubernetes' Service's Port.
 type Service struct {
 	Weighted WeightedService
@@ -835,6 +1160,24 @@ func (s *Secret) PrivateKey() []byte {
 	return s.Object.Data[v1.TLSPrivateKeyKey]
 }
 
+// OCSPStaple returns the secret's DER encoded OCSP response, if any.
+func (s *Secret) OCSPStaple() []byte {
+	return s.Object.Data[OCSPStapleKey]
+}
+
+// SessionTicketKeys returns the secret's TLS session ticket keys. The
+// first key is used to encrypt new tickets, and all the keys are used
+// to decrypt tickets.
+func (s *Secret) SessionTicketKeys() [][]byte {
+	var keys [][]byte
+	data := s.Object.Data[SessionTicketKeysKey]
+	for len(data) >= SessionTicketKeyLength {
+		keys = append(keys, data[:SessionTicketKeyLength])
+		data = data[SessionTicketKeyLength:]
+	}
+	return keys
+}
+
 // HTTPHealthCheckPolicy http health check policy
 type HTTPHealthCheckPolicy struct {
 	Path               string
@@ -881,6 +1224,30 @@ type ExtensionCluster struct {
 	// ClientCertificate is the optional identifier of the TLS secret containing client certificate and
 	// private key to be used when establishing TLS connection to upstream cluster.
 	ClientCertificate *Secret
+
+	// HTTPAuthorization configures the extension to be sent
+	// authorization requests with the HTTP protocol. If nil,
+	// the extension is sent GRPC authorization requests.
+	HTTPAuthorization *HTTPAuthorizationService
+}
+
+// HTTPAuthorizationService configures how authorization requests
+// are sent to an extension that implements the HTTP protocol.
+type HTTPAuthorizationService struct {
+	// PathPrefix is prepended to the path of authorization requests.
+	PathPrefix string
+
+	// HeadersToAdd are added to authorization requests.
+	HeadersToAdd map[string]string
+
+	// AllowedUpstreamHeaders are the response headers that
+	// are added to allowed client requests.
+	AllowedUpstreamHeaders []string
+
+	// AllowedDownstreamHeaders are the response headers that
+	// are sent to clients whose requests are denied. If empty,
+	// all response headers are sent.
+	AllowedDownstreamHeaders []string
 }
 
 // Visit processes extension clusters.
@@ -14,6 +14,7 @@
 package dag
 
 import (
+	"fmt"
 	"path"
 	"strings"
 
@@ -26,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type ExtensionServiceProcessor struct {
@@ -131,11 +133,21 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 			".Spec.TimeoutPolicy.Idle")
 	}
 
-	// API server validation ensures that the protocol is "h2" or "h2c".
+	// API server validation ensures that the protocol is "h2",
+	// "h2c", "http/1.1" or "tls".
 	if ext.Spec.Protocol != nil {
 		extension.Protocol = stringOrDefault(*ext.Spec.Protocol, extension.Protocol)
 	}
 
+	if ext.Spec.HTTPAuthorization != nil {
+		if httpAuth, err := httpAuthorizationService(ext.Spec.HTTPAuthorization); err != nil {
+			validCondition.AddErrorf(contour_api_v1.ConditionTypeSpecError, "HTTPAuthorizationNotValid",
+				"spec.httpAuthorization is invalid: %s", err)
+		} else {
+			extension.HTTPAuthorization = httpAuth
+		}
+	}
+
 	if v := ext.Spec.UpstreamValidation; v != nil {
 		if uv, err := cache.LookupUpstreamValidation(v, ext.GetNamespace()); err != nil {
 			validCondition.AddErrorf(contour_api_v1.ConditionTypeSpecError, "TLSUpstreamValidation",
@@ -151,9 +163,21 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 			//
 			// TODO(jpeach): expose SNI in the API, https://github.com/projectcontour/contour/issues/2893.
 			extension.SNI = uv.SubjectName
+
+			// If only a list of subject names is given,
+			// use the first exact name, since a prefix or
+			// regex cannot be sent as a server name.
+			if extension.SNI == "" {
+				for _, name := range uv.SubjectNames {
+					if name.MatchType == SubjectNameMatchTypeExact {
+						extension.SNI = name.Value
+						break
+					}
+				}
+			}
 		}
 
-		if extension.Protocol != "h2" {
+		if extension.Protocol != "h2" && extension.Protocol != "tls" {
 			validCondition.AddErrorf(contour_api_v1.ConditionTypeSpecError, "InconsistentProtocol",
 				"upstream TLS validation not supported for %q protocol", extension.Protocol)
 		}
@@ -188,3 +212,35 @@ func (p *ExtensionServiceProcessor) buildExtensionService(
 
 	return &extension
 }
+
+// httpAuthorizationService validates the settings of an extension
+// that implements the HTTP authorization protocol.
+func httpAuthorizationService(in *contour_api_v1alpha1.HTTPAuthorizationService) (*HTTPAuthorizationService, error) {
+	if in.PathPrefix != "" && !strings.HasPrefix(in.PathPrefix, "/") {
+		return nil, fmt.Errorf("pathPrefix %q must start with \"/\"", in.PathPrefix)
+	}
+
+	out := &HTTPAuthorizationService{
+		PathPrefix: in.PathPrefix,
+	}
+
+	for _, h := range in.HeadersToAdd {
+		if msgs := validation.IsHTTPHeaderName(h.Name); len(msgs) != 0 {
+			return nil, fmt.Errorf("invalid header to add %q: %v", h.Name, msgs)
+		}
+		if out.HeadersToAdd == nil {
+			out.HeadersToAdd = map[string]string{}
+		}
+		out.HeadersToAdd[strings.ToLower(h.Name)] = h.Value
+	}
+
+	var err error
+	if out.AllowedUpstreamHeaders, err = headerNames("allowed upstream", in.AllowedUpstreamHeaders); err != nil {
+		return nil, err
+	}
+	if out.AllowedDownstreamHeaders, err = headerNames("allowed downstream", in.AllowedDownstreamHeaders); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
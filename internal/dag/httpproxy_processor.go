@@ -14,10 +14,15 @@
 package dag
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	contour_api_v1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
@@ -27,8 +32,21 @@ import (
 	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/projectcontour/contour/pkg/config"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// acmeChallengePrefix is the path prefix of ACME HTTP-01
+	// challenges.
+	acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+	// acmeSolverIngressLabel labels the Ingresses that cert-manager
+	// creates for its HTTP-01 challenge solvers.
+	acmeSolverIngressLabel = "acme.cert-manager.io/http01-solver"
 )
 
 // defaultExtensionRef populates the unset fields in ref with default values.
@@ -48,6 +66,11 @@ type HTTPProxyProcessor struct {
 	source   *KubernetesCache
 	orphaned map[types.NamespacedName]bool
 
+	// AdditionalListeners maps the names of the listeners that
+	// virtual hosts can bind to, besides the default HTTP and
+	// HTTPS listeners, to the protocol that each serves.
+	AdditionalListeners map[string]config.ListenerProtocol
+
 	// DisablePermitInsecure disables the use of the
 	// permitInsecure field in HTTPProxy.
 	DisablePermitInsecure bool
@@ -71,11 +94,30 @@ type HTTPProxyProcessor struct {
 	// private key to be used when establishing TLS connection to upstream cluster.
 	ClientCertificate *types.NamespacedName
 
+	// SessionTicketKeys is the optional identifier of the secret
+	// containing the TLS session ticket keys of virtual hosts that
+	// don't specify their own.
+	SessionTicketKeys *types.NamespacedName
+
+	// CertificateExpiryWarning is how long before a TLS certificate
+	// expires that a warning is added to the status of the HTTPProxies
+	// that serve it. If zero, no warnings are added.
+	CertificateExpiryWarning time.Duration
+
+	// Clock returns the current time, which the expiry of TLS
+	// certificates and OCSP staples is checked against. If nil,
+	// time.Now is used.
+	Clock func() time.Time
+
 	// Request headers that will be set on all routes (optional).
 	RequestHeadersPolicy *HeadersPolicy
 
 	// Response headers that will be set on all routes (optional).
 	ResponseHeadersPolicy *HeadersPolicy
+
+	// HSTSPolicy is the default HSTS policy of virtual hosts
+	// that terminate TLS (optional).
+	HSTSPolicy *HSTSPolicy
 }
 
 // Run translates HTTPProxies into DAG objects and
@@ -108,6 +150,34 @@ func (p *HTTPProxyProcessor) Run(dag *DAG, source *KubernetesCache) {
 	}
 }
 
+// rootVirtualHost holds the state of the virtual host of a root
+// HTTPProxy that applies to every route computed for it.
+type rootVirtualHost struct {
+	// insecureListener and secureListener are the names of the
+	// listeners that the virtual hosts bind to. insecureListener
+	// is empty if the virtual host is only served over TLS.
+	insecureListener string
+	secureListener   string
+
+	// basicAuth is the basic authentication required by the
+	// virtual host.
+	basicAuth *BasicAuth
+
+	// ipFilterPolicy is the IP filter policy of the virtual host.
+	ipFilterPolicy *IPFilterPolicy
+
+	// hstsPolicy is the HSTS policy of the virtual host.
+	hstsPolicy *HSTSPolicy
+
+	// routePriorities records the HTTPProxies that declare
+	// routes with each explicit priority.
+	routePriorities map[int32][]string
+
+	// acmeChallengeRoutes are the plain HTTP routes for ACME
+	// HTTP-01 challenges to the virtual hosts, keyed by host.
+	acmeChallengeRoutes map[string][]*Route
+}
+
 func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 	pa, commit := p.dag.StatusCache.ProxyAccessor(proxy)
 	validCond := pa.ConditionFor(status.ValidCondition)
@@ -136,9 +206,42 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 		return
 	}
 
+	// A wildcard is only permitted as the complete first DNS
+	// label, e.g. "*.example.com", matching the form that is
+	// supported for Ingress hosts.
 	if strings.Contains(host, "*") {
-		validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "WildCardNotAllowed",
-			"Spec.VirtualHost.Fqdn %q cannot use wildcards", host)
+		if errs := validation.IsWildcardDNS1123Subdomain(host); len(errs) > 0 {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "WildCardNotAllowed",
+				"Spec.VirtualHost.Fqdn %q can only use a wildcard as the first DNS label", host)
+			return
+		}
+	}
+
+	aliases, ok := validAliases(validCond, proxy)
+	if !ok {
+		return
+	}
+
+	insecureListener, secureListener, ok := p.bindListeners(validCond, proxy)
+	if !ok {
+		return
+	}
+	root := &rootVirtualHost{
+		insecureListener: insecureListener,
+		secureListener:   secureListener,
+		routePriorities:  make(map[int32][]string),
+	}
+
+	// A UDP listener forwards all of its datagrams to the
+	// UDP proxy, so there is nothing else to compute.
+	udpListener := p.AdditionalListeners[proxy.Spec.VirtualHost.Listener] == config.UDPListenerProtocol
+	if proxy.Spec.UDPProxy != nil && !udpListener {
+		validCond.AddError(contour_api_v1.ConditionTypeUDPProxyError, "ListenerNotValid",
+			"Spec.UDPProxy requires Spec.VirtualHost.Listener to be a UDP listener")
+		return
+	}
+	if udpListener {
+		p.computeUDPProxy(validCond, proxy)
 		return
 	}
 
@@ -172,184 +275,1418 @@ func (p *HTTPProxyProcessor) computeHTTPProxy(proxy *contour_api_v1.HTTPProxy) {
 			return
 		}
 
+		if tls.Passthrough && len(tls.AdditionalSecretNames) > 0 {
+			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+				"Spec.VirtualHost.TLS: both Passthrough and AdditionalSecretNames were specified")
+			return
+		}
+
+		if tls.Passthrough && tls.OCSPStaplePolicy != "" {
+			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+				"Spec.VirtualHost.TLS: both Passthrough and OCSPStaplePolicy were specified")
+			return
+		}
+
+		if tls.Passthrough && tls.SessionTicketKeysSecretName != "" {
+			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+				"Spec.VirtualHost.TLS: both Passthrough and SessionTicketKeysSecretName were specified")
+			return
+		}
+
+		if tls.Passthrough && tls.ACMEChallenge != nil {
+			validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+				"Spec.VirtualHost.TLS: both Passthrough and ACMEChallenge were specified")
+			return
+		}
+
+		if tls.ACMEChallenge != nil {
+			challengeRoutes, ok := p.acmeChallengeRoutes(validCond, proxy, tls.ACMEChallenge, append([]string{host}, aliases...))
+			if !ok {
+				return
+			}
+			root.acmeChallengeRoutes = challengeRoutes
+		}
+
 		tlsEnabled = true
 
 		// Attach secrets to TLS enabled vhosts.
 		if !tls.Passthrough {
-			secretName := k8s.NamespacedNameFrom(tls.SecretName, k8s.DefaultNamespace(proxy.Namespace))
-			sec, err := p.source.LookupSecret(secretName, validSecret)
-			if err != nil {
-				validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
-					"Spec.VirtualHost.TLS Secret %q is invalid: %s", tls.SecretName, err)
+			// The virtual host is owned by this HTTPProxy, so
+			// challenges are still routed while its certificates
+			// are missing or invalid, so that they can be issued.
+			sec, ok := p.tlsSecret(validCond, proxy, tls.SecretName, aliases)
+			if !ok {
+				p.addACMEChallengeRoutes(root)
+				return
+			}
+
+			var additionalSecrets []*Secret
+			for _, name := range tls.AdditionalSecretNames {
+				additional, ok := p.tlsSecret(validCond, proxy, name, aliases)
+				if !ok {
+					p.addACMEChallengeRoutes(root)
+					return
+				}
+				additionalSecrets = append(additionalSecrets, additional)
+			}
+
+			// Envoy selects a certificate by its key type, so
+			// there can only be one certificate of each type.
+			if len(additionalSecrets) > 0 {
+				names := append([]string{tls.SecretName}, tls.AdditionalSecretNames...)
+				secrets := append([]*Secret{sec}, additionalSecrets...)
+				keyTypes := map[string]string{}
+				for i, name := range names {
+					keyType, err := certificateKeyType(secrets[i])
+					if err != nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+							"Spec.VirtualHost.TLS Secret %q is invalid: %s", name, err)
+						return
+					}
+					if other, ok := keyTypes[keyType]; ok {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+							"Spec.VirtualHost.TLS Secrets %q and %q both have %s certificates", other, name, keyType)
+						return
+					}
+					keyTypes[keyType] = name
+				}
+			}
+
+			// default to a minimum TLS version of 1.2 if it's not specified
+			minTLSVersion := annotation.MinTLSVersion(tls.MinimumProtocolVersion, "1.2")
+
+			switch tls.MaximumProtocolVersion {
+			case "", "1.3":
+			case "1.2":
+				if minTLSVersion == "1.3" {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+						"Spec.VirtualHost.TLS.MaximumProtocolVersion %q is lower than the minimum protocol version %q",
+						tls.MaximumProtocolVersion, minTLSVersion)
+					return
+				}
+			default:
+				validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+					"Spec.VirtualHost.TLS.MaximumProtocolVersion %q is invalid, must be \"1.2\" or \"1.3\"", tls.MaximumProtocolVersion)
+				return
+			}
+
+			var cipherSuites []string
+			if len(tls.CipherSuites) > 0 {
+				if err := config.TLSCiphers(tls.CipherSuites).Validate(); err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+						"Spec.VirtualHost.TLS.CipherSuites is invalid: %s", err)
+					return
+				}
+				cipherSuites = config.SanitizeCipherSuites(tls.CipherSuites)
+			}
+
+			switch tls.OCSPStaplePolicy {
+			case "", "lenient", "strict":
+			case "must-staple":
+				// Envoy rejects a must-staple configuration if any
+				// certificate has no OCSP response, or if the
+				// response has already expired.
+				names := append([]string{tls.SecretName}, tls.AdditionalSecretNames...)
+				for i, secret := range append([]*Secret{sec}, additionalSecrets...) {
+					if len(secret.OCSPStaple()) == 0 {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotValid",
+							"Spec.VirtualHost.TLS Secret %q has no OCSP staple, which the must-staple policy requires", names[i])
+						return
+					}
+					expired, err := ocspStapleExpired(secret, p.now())
+					if err != nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotValid",
+							"Spec.VirtualHost.TLS Secret %q OCSP staple is invalid: %s", names[i], err)
+						return
+					}
+					if expired {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotValid",
+							"Spec.VirtualHost.TLS Secret %q OCSP staple has expired", names[i])
+						return
+					}
+				}
+			default:
+				validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid",
+					"Spec.VirtualHost.TLS.OCSPStaplePolicy %q is invalid, must be \"lenient\", \"strict\" or \"must-staple\"", tls.OCSPStaplePolicy)
+				return
+			}
+
+			svhost := p.dag.EnsureSecureVirtualHost(ListenerName{Name: host, ListenerName: root.secureListener})
+			svhost.Secret = sec
+			svhost.AdditionalSecrets = additionalSecrets
+			svhost.MinTLSVersion = minTLSVersion
+			svhost.MaxTLSVersion = tls.MaximumProtocolVersion
+			svhost.CipherSuites = cipherSuites
+			svhost.OCSPStaplePolicy = tls.OCSPStaplePolicy
+
+			sessionTicketKeys, ok := p.sessionTicketKeys(validCond, proxy)
+			if !ok {
+				return
+			}
+			svhost.SessionTicketKeys = sessionTicketKeys
+
+			// Check if FallbackCertificate && ClientValidation are both enabled in the same vhost
+			if tls.EnableFallbackCertificate && tls.ClientValidation != nil {
+				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
+					"Spec.Virtualhost.TLS fallback & client validation are incompatible")
+				return
+			}
+
+			// Fallback certificates and authorization are
+			// incompatible because fallback installs the routes on
+			// a separate HTTPConnectionManager. We can't have the
+			// same routes installed on multiple managers with
+			// inconsistent authorization settings.
+			if tls.EnableFallbackCertificate && proxy.Spec.VirtualHost.AuthorizationConfigured() {
+				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
+					"Spec.Virtualhost.TLS fallback & client authorization are incompatible")
+				return
+			}
+
+			// Fallback certificates and JWT verification are
+			// incompatible for the same reason.
+			if tls.EnableFallbackCertificate && len(proxy.Spec.VirtualHost.JWTProviders) > 0 {
+				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
+					"Spec.Virtualhost.TLS fallback & JWT verification are incompatible")
+				return
+			}
+
+			if tls.EnableFallbackCertificate && proxy.Spec.VirtualHost.BasicAuth != nil {
+				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
+					"Spec.Virtualhost.TLS fallback & basic authentication are incompatible")
 				return
 			}
 
-			if !p.source.DelegationPermitted(secretName, proxy.Namespace) {
-				validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "DelegationNotPermitted",
-					"Spec.VirtualHost.TLS Secret %q certificate delegation not permitted", tls.SecretName)
-				return
-			}
+			// If FallbackCertificate is enabled, but no cert passed, set error
+			if tls.EnableFallbackCertificate {
+				if p.FallbackCertificate == nil {
+					validCond.AddError(contour_api_v1.ConditionTypeTLSError, "FallbackNotPresent",
+						"Spec.Virtualhost.TLS enabled fallback but the fallback Certificate Secret is not configured in Contour configuration file")
+					return
+				}
+
+				sec, err := p.source.LookupSecret(*p.FallbackCertificate, validSecret)
+				if err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "FallbackNotValid",
+						"Spec.Virtualhost.TLS Secret %q fallback certificate is invalid: %s", p.FallbackCertificate, err)
+					return
+				}
+
+				if !p.source.DelegationPermitted(*p.FallbackCertificate, proxy.Namespace) {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "FallbackNotDelegated",
+						"Spec.VirtualHost.TLS fallback Secret %q is not configured for certificate delegation", p.FallbackCertificate)
+					return
+				}
+
+				svhost.FallbackCertificate = sec
+			}
+
+			// Fill in DownstreamValidation when external client validation is enabled.
+			if tls.ClientValidation != nil {
+				dv := &PeerValidationContext{
+					SkipClientCertValidation:  tls.ClientValidation.SkipClientCertValidation,
+					OptionalClientCertificate: tls.ClientValidation.OptionalClientCertificate,
+				}
+				if !tls.ClientValidation.SkipClientCertValidation {
+					if tls.ClientValidation.CACertificate != "" {
+						secretName := k8s.NamespacedNameFrom(tls.ClientValidation.CACertificate, k8s.DefaultNamespace(proxy.Namespace))
+						cacert, err := p.source.LookupSecret(secretName, validCA)
+						if err != nil {
+							// PeerValidationContext is requested, but cert is missing or not configured.
+							validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+								"Spec.VirtualHost.TLS client validation is invalid: invalid CA Secret %q: %s", secretName, err)
+							return
+						}
+						dv.CACertificate = cacert
+					} else {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+							"Spec.VirtualHost.TLS client validation is invalid: CA Secret must be specified")
+					}
+				}
+
+				if crl := tls.ClientValidation.CertificateRevocationList; crl != nil {
+					if tls.ClientValidation.SkipClientCertValidation {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+							"Spec.VirtualHost.TLS client validation is invalid: crl cannot be used with skipClientCertValidation")
+						return
+					}
+
+					data, err := p.certificateRevocationList(crl, proxy.Namespace)
+					if err != nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+							"Spec.VirtualHost.TLS client validation is invalid: crl is invalid: %s", err)
+						return
+					}
+					dv.CRL = data
+				}
+				svhost.DownstreamValidation = dv
+
+				if fcc := tls.ClientValidation.ForwardClientCertificate; fcc != nil {
+					forward, err := forwardClientCertificate(fcc)
+					if err != nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+							"Spec.VirtualHost.TLS client validation is invalid: %s", err)
+						return
+					}
+					svhost.ForwardClientCertificate = forward
+				}
+			}
+
+			if proxy.Spec.VirtualHost.AuthorizationConfigured() {
+				auth := proxy.Spec.VirtualHost.Authorization
+				ref := defaultExtensionRef(auth.ExtensionServiceRef)
+
+				if ref.APIVersion != contour_api_v1alpha1.GroupVersion.String() {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthBadResourceVersion",
+						"Spec.Virtualhost.Authorization.extensionRef specifies an unsupported resource version %q", auth.ExtensionServiceRef.APIVersion)
+					return
+				}
+
+				// Lookup the extension service reference.
+				extensionName := types.NamespacedName{
+					Name:      ref.Name,
+					Namespace: stringOrDefault(ref.Namespace, proxy.Namespace),
+				}
+
+				ext := p.dag.GetExtensionCluster(ExtensionClusterName(extensionName))
+				if ext == nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "ExtensionServiceNotFound",
+						"Spec.Virtualhost.Authorization.ServiceRef extension service %q not found", extensionName)
+					return
+				}
+
+				if err := validAuthorizationProtocol(ext); err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthorizationServerNotValid",
+						"Spec.Virtualhost.Authorization is invalid: extension service %q: %s", extensionName, err)
+					return
+				}
+
+				svhost.AuthorizationService = ext
+				svhost.AuthorizationFailOpen = auth.FailOpen
+
+				timeout, err := timeout.Parse(auth.ResponseTimeout)
+				if err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthResponseTimeoutInvalid",
+						"Spec.Virtualhost.Authorization.ResponseTimeout is invalid: %s", err)
+					return
+				}
+
+				if timeout.UseDefault() {
+					svhost.AuthorizationResponseTimeout = ext.TimeoutPolicy.ResponseTimeout
+				} else {
+					svhost.AuthorizationResponseTimeout = timeout
+				}
+
+				body, headers, err := authorizationServerRequest(auth)
+				if err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthorizationServerNotValid",
+						"Spec.Virtualhost.Authorization is invalid: %s", err)
+					return
+				}
+
+				svhost.AuthorizationServerWithRequestBody = body
+				svhost.AuthorizationAllowedHeaders = headers
+
+				if len(headers) > 0 && ext.HTTPAuthorization == nil {
+					validCond.AddWarningf(contour_api_v1.ConditionTypeAuthError, "AllowedHeadersNotSupported",
+						"Spec.Virtualhost.Authorization.AllowedHeaders are not supported by gRPC authorization servers, all request headers are sent")
+				}
+			}
+		}
+	}
+
+	if len(proxy.Spec.VirtualHost.JWTProviders) > 0 {
+		if !tlsEnabled || proxy.Spec.VirtualHost.TLS.Passthrough {
+			validCond.AddError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationNotPermitted",
+				"Spec.VirtualHost.JWTProviders can only be defined for root HTTPProxies that terminate TLS")
+			return
+		}
+
+		providers, ok := p.jwtProviders(validCond, proxy)
+		if !ok {
+			return
+		}
+
+		svhost := p.dag.EnsureSecureVirtualHost(ListenerName{Name: host, ListenerName: root.secureListener})
+		svhost.JWTProviders = providers
+	}
+
+	// The OAuth2 filter of Envoy 1.18 always requests the "user"
+	// scope, which identity providers such as OpenID Connect
+	// providers do not grant, and the scopes cannot be configured.
+	// Rather than serving a login flow that cannot complete, OAuth2
+	// is rejected until Contour supports a newer Envoy.
+	if proxy.Spec.VirtualHost.OAuth2 != nil {
+		validCond.AddError(contour_api_v1.ConditionTypeOAuth2Error, "OAuth2NotSupported",
+			"Spec.VirtualHost.OAuth2 is not supported: Envoy 1.18 cannot configure the OAuth2 scopes it requests")
+		return
+	}
+
+	if ba := proxy.Spec.VirtualHost.BasicAuth; ba != nil {
+		if !tlsEnabled || proxy.Spec.VirtualHost.TLS.Passthrough {
+			validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+				"Spec.VirtualHost.BasicAuth can only be defined for root HTTPProxies that terminate TLS")
+			return
+		}
+
+		// Basic authentication is verified by an ext_authz
+		// filter, whose route configuration would collide with
+		// that of the authorization server.
+		if proxy.Spec.VirtualHost.AuthorizationConfigured() {
+			validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+				"Spec.VirtualHost.BasicAuth cannot be defined together with Spec.VirtualHost.Authorization")
+			return
+		}
+
+		if ba.Disabled || isBlank(ba.SecretName) {
+			validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotValid",
+				"Spec.VirtualHost.BasicAuth must specify a secretName and cannot be disabled")
+			return
+		}
+
+		auth, ok := p.lookupBasicAuth(validCond, "Spec.VirtualHost.BasicAuth", ba, proxy.Namespace, host)
+		if !ok {
+			return
+		}
+		root.basicAuth = auth
+	}
+
+	ipFilter, err := ipFilterPolicy(proxy.Spec.VirtualHost.IPFilterPolicy)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+			"Spec.VirtualHost.IPFilterPolicy is invalid: %s", err)
+		return
+	}
+	root.ipFilterPolicy = ipFilter
+
+	terminateTLS := tlsEnabled && !proxy.Spec.VirtualHost.TLS.Passthrough
+	if hsts := proxy.Spec.VirtualHost.HSTSPolicy; hsts != nil {
+		if !terminateTLS {
+			validCond.AddError(contour_api_v1.ConditionTypeHSTSError, "HSTSPolicyNotPermitted",
+				"Spec.VirtualHost.HSTSPolicy can only be defined for root HTTPProxies that terminate TLS")
+			return
+		}
+
+		policy, err := hstsPolicy(hsts)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeHSTSError, "HSTSPolicyNotValid",
+				"Spec.VirtualHost.HSTSPolicy is invalid: %s", err)
+			return
+		}
+		root.hstsPolicy = policy
+	} else if terminateTLS {
+		root.hstsPolicy = p.HSTSPolicy
+	}
+
+	if root.hstsPolicy != nil {
+		svhost := p.dag.EnsureSecureVirtualHost(ListenerName{Name: host, ListenerName: root.secureListener})
+		svhost.HSTSPolicy = root.hstsPolicy
+	}
+
+	if proxy.Spec.TCPProxy != nil {
+		if !tlsEnabled && p.AdditionalListeners[proxy.Spec.VirtualHost.Listener] != config.TCPListenerProtocol {
+			validCond.AddError(contour_api_v1.ConditionTypeTCPProxyError, "TLSMustBeConfigured",
+				"Spec.TCPProxy requires that either Spec.TLS.Passthrough or Spec.TLS.SecretName be set")
+			return
+		}
+		if !p.processHTTPProxyTCPProxy(validCond, proxy, nil, ListenerName{Name: host, ListenerName: root.secureListener}, root.ipFilterPolicy) {
+			return
+		}
+	}
+
+	routes := p.computeRoutes(validCond, root, proxy, proxy, nil, nil, nil, tlsEnabled)
+	reportRoutePriorityTies(validCond, root.routePriorities)
+	cp, err := toCORSPolicy(proxy.Spec.VirtualHost.CORSPolicy)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeCORSError, "PolicyDidNotParse",
+			"Spec.VirtualHost.CORSPolicy: %s", err)
+		return
+	}
+
+	rlp, err := rateLimitPolicy(proxy.Spec.VirtualHost.RateLimitPolicy)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RateLimitPolicyNotValid",
+			"Spec.VirtualHost.RateLimitPolicy is invalid: %s", err)
+		return
+	}
+
+	csrf, err := csrfPolicy(proxy.Spec.VirtualHost.CSRFPolicy)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "CSRFPolicyNotValid",
+			"Spec.VirtualHost.CSRFPolicy is invalid: %s", err)
+		return
+	}
+
+	if root.insecureListener != "" {
+		insecure := p.dag.EnsureVirtualHost(ListenerName{Name: host, ListenerName: root.insecureListener})
+		insecure.CORSPolicy = cp
+		insecure.RateLimitPolicy = rlp
+		insecure.CSRFPolicy = csrf
+		addRoutes(insecure, hostRoutes(host, routes))
+	}
+
+	// if TLS is enabled for this virtual host and there is no tcp proxy defined,
+	// then add routes to the secure virtualhost definition.
+	if tlsEnabled && proxy.Spec.TCPProxy == nil {
+		secure := p.dag.EnsureSecureVirtualHost(ListenerName{Name: host, ListenerName: root.secureListener})
+		secure.CORSPolicy = cp
+
+		rlp, err := rateLimitPolicy(proxy.Spec.VirtualHost.RateLimitPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RateLimitPolicyNotValid",
+				"Spec.VirtualHost.RateLimitPolicy is invalid: %s", err)
+			return
+		}
+		secure.RateLimitPolicy = rlp
+		secure.CSRFPolicy = csrf
+
+		addRoutes(secure, hostRoutes(host, routes))
+	}
+
+	// Each alias shares the configuration of the primary virtual host.
+	for _, alias := range aliases {
+		if root.insecureListener != "" {
+			insecure := p.dag.EnsureVirtualHost(ListenerName{Name: host, ListenerName: root.insecureListener})
+			insecureAlias := p.dag.EnsureVirtualHost(ListenerName{Name: alias, ListenerName: root.insecureListener})
+			*insecureAlias = aliasVirtualHost(*insecure, alias, routes)
+		}
+
+		if tlsEnabled {
+			var secureRoutes []*Route
+			if proxy.Spec.TCPProxy == nil {
+				secureRoutes = routes
+			}
+
+			secure := p.dag.EnsureSecureVirtualHost(ListenerName{Name: host, ListenerName: root.secureListener})
+			secureAlias := p.dag.EnsureSecureVirtualHost(ListenerName{Name: alias, ListenerName: root.secureListener})
+			*secureAlias = *secure
+			secureAlias.VirtualHost = aliasVirtualHost(secure.VirtualHost, alias, secureRoutes)
+		}
+	}
+
+	// The challenge routes are added last, so that they take
+	// precedence over routes with the same conditions.
+	p.addACMEChallengeRoutes(root)
+}
+
+// addACMEChallengeRoutes adds the ACME HTTP-01 challenge routes of the
+// root HTTPProxy to its plain HTTP virtual hosts. It must only be
+// called once the HTTPProxy is known to own the virtual hosts.
+func (p *HTTPProxyProcessor) addACMEChallengeRoutes(root *rootVirtualHost) {
+	for host, routes := range root.acmeChallengeRoutes {
+		addRoutes(p.dag.EnsureVirtualHost(ListenerName{Name: host, ListenerName: root.insecureListener}), routes)
+	}
+}
+
+// bindListeners returns the names of the insecure and secure listeners
+// that the virtual hosts of the root HTTPProxy bind to. It returns
+// false and updates the condition if the selected listener is not
+// configured or does not serve the protocol of the virtual host.
+func (p *HTTPProxyProcessor) bindListeners(validCond *contour_api_v1.DetailedCondition, proxy *contour_api_v1.HTTPProxy) (string, string, bool) {
+	name := proxy.Spec.VirtualHost.Listener
+	if name == "" {
+		return "ingress_http", "ingress_https", true
+	}
+
+	protocol, ok := p.AdditionalListeners[name]
+	if !ok {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotFound",
+			"Spec.VirtualHost.Listener %q is not configured", name)
+		return "", "", false
+	}
+
+	tls := proxy.Spec.VirtualHost.TLS
+	switch protocol {
+	case config.HTTPListenerProtocol:
+		if tls != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.Listener %q serves HTTP and cannot be used with Spec.VirtualHost.TLS", name)
+			return "", "", false
+		}
+		return name, "", true
+	case config.HTTPSListenerProtocol:
+		if tls == nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.Listener %q serves HTTPS and requires Spec.VirtualHost.TLS", name)
+			return "", "", false
+		}
+		// ACME HTTP-01 challenges are always made over
+		// plain HTTP on port 80.
+		if tls.ACMEChallenge != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.TLS.ACMEChallenge cannot be used with Spec.VirtualHost.Listener %q", name)
+			return "", "", false
+		}
+		return "", name, true
+	case config.TCPListenerProtocol:
+		if tls != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.Listener %q serves TCP and cannot be used with Spec.VirtualHost.TLS", name)
+			return "", "", false
+		}
+		if proxy.Spec.TCPProxy == nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.Listener %q serves TCP and requires Spec.TCPProxy", name)
+			return "", "", false
+		}
+		if len(proxy.Spec.Routes) > 0 || len(proxy.Spec.Includes) > 0 {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.Listener %q serves TCP and cannot be used with Spec.Routes or Spec.Includes", name)
+			return "", "", false
+		}
+		// The TCP proxy is bound to the listener as a secure
+		// virtual host without a secret, like TLS passthrough.
+		return "", name, true
+	case config.UDPListenerProtocol:
+		if tls != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.Listener %q serves UDP and cannot be used with Spec.VirtualHost.TLS", name)
+			return "", "", false
+		}
+		if proxy.Spec.UDPProxy == nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.Listener %q serves UDP and requires Spec.UDPProxy", name)
+			return "", "", false
+		}
+		if len(proxy.Spec.Routes) > 0 || len(proxy.Spec.Includes) > 0 || proxy.Spec.TCPProxy != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid",
+				"Spec.VirtualHost.Listener %q serves UDP and cannot be used with Spec.Routes, Spec.Includes or Spec.TCPProxy", name)
+			return "", "", false
+		}
+	}
+
+	// The UDP proxy is bound to the listener directly,
+	// without any virtual hosts.
+	return "", "", true
+}
+
+// computeUDPProxy binds the UDP proxy of the root HTTPProxy to its
+// UDP listener. It updates the condition if the UDP proxy is invalid.
+func (p *HTTPProxyProcessor) computeUDPProxy(validCond *contour_api_v1.DetailedCondition, proxy *contour_api_v1.HTTPProxy) {
+	udpproxy := proxy.Spec.UDPProxy
+
+	var idleTimeout time.Duration
+	if udpproxy.SessionIdleTimeout != "" {
+		d, err := time.ParseDuration(udpproxy.SessionIdleTimeout)
+		if err != nil || d <= 0 {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeUDPProxyError, "SessionIdleTimeoutNotValid",
+				"Spec.UDPProxy.SessionIdleTimeout %q must be a positive duration", udpproxy.SessionIdleTimeout)
+			return
+		}
+		idleTimeout = d
+	}
+
+	m := types.NamespacedName{Name: udpproxy.ServiceName, Namespace: proxy.Namespace}
+	s, err := p.dag.EnsureUDPService(m, intstr.FromInt(udpproxy.Port), p.source)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeUDPProxyError, "UnresolvedServiceRef",
+			"Spec.UDPProxy unresolved service reference: %s", err)
+		return
+	}
+
+	// The udp_proxy filter hashes the source IP, and
+	// the cluster selects the endpoint from the hash.
+	var lbPolicy string
+	if udpproxy.HashSourceIP {
+		lbPolicy = LoadBalancerPolicyRequestHash
+	}
+
+	p.dag.AddRoot(&UDPProxy{
+		ListenerName: proxy.Spec.VirtualHost.Listener,
+		Cluster: &Cluster{
+			Upstream:           s,
+			LoadBalancerPolicy: lbPolicy,
+		},
+		SessionIdleTimeout: idleTimeout,
+		HashSourceIP:       udpproxy.HashSourceIP,
+	})
+}
+
+// acmeChallengeRoutes returns the plain HTTP routes for ACME HTTP-01
+// challenges to each of the given hosts, keyed by host. The challenges
+// are routed to the configured solver Service, or else to the solvers
+// of cert-manager's solver Ingresses for the host in the HTTPProxy's
+// namespace. It returns false and updates the condition if the
+// configuration is invalid.
+func (p *HTTPProxyProcessor) acmeChallengeRoutes(validCond *contour_api_v1.DetailedCondition, proxy *contour_api_v1.HTTPProxy, challenge *contour_api_v1.ACMEChallenge, hosts []string) (map[string][]*Route, bool) {
+	if strings.HasPrefix(hosts[0], "*.") {
+		validCond.AddError(contour_api_v1.ConditionTypeTLSError, "ACMEChallengeNotValid",
+			"Spec.VirtualHost.TLS.ACMEChallenge cannot be used with a wildcard fqdn")
+		return nil, false
+	}
+
+	if isBlank(challenge.ServiceName) != (challenge.Port == 0) {
+		validCond.AddError(contour_api_v1.ConditionTypeTLSError, "ACMEChallengeNotValid",
+			"Spec.VirtualHost.TLS.ACMEChallenge: ServiceName and Port must be specified together")
+		return nil, false
+	}
+
+	// Certificates for wildcard names cannot be issued with
+	// HTTP-01 challenges.
+	served := map[string]bool{}
+	for _, host := range hosts {
+		if !strings.HasPrefix(host, "*.") {
+			served[host] = true
+		}
+	}
+
+	routes := map[string][]*Route{}
+
+	if !isBlank(challenge.ServiceName) {
+		m := types.NamespacedName{Name: challenge.ServiceName, Namespace: proxy.Namespace}
+		s, err := p.dag.EnsureService(m, intstr.FromInt(challenge.Port), p.source)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeServiceError, "ServiceUnresolvedReference",
+				"Spec.VirtualHost.TLS.ACMEChallenge unresolved service reference: %s", err)
+			return nil, false
+		}
+
+		for host := range served {
+			routes[host] = append(routes[host], acmeChallengeRoute(
+				&PrefixMatchCondition{Prefix: acmeChallengePrefix, PrefixMatchType: PrefixMatchString}, s))
+		}
+		return routes, true
+	}
+
+	for _, ing := range p.source.acmesolveringresses {
+		if ing.Namespace != proxy.Namespace {
+			continue
+		}
+		for _, rule := range ing.Spec.Rules {
+			host := strings.ToLower(rule.Host)
+			if rule.HTTP == nil || !served[host] {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				// Solver Ingresses may only route challenges.
+				if !strings.HasPrefix(path.Path, acmeChallengePrefix) || path.Backend.Service == nil {
+					continue
+				}
+
+				port := intstr.FromInt(int(path.Backend.Service.Port.Number))
+				if len(path.Backend.Service.Port.Name) > 0 {
+					port = intstr.FromString(path.Backend.Service.Port.Name)
+				}
+
+				m := types.NamespacedName{Name: path.Backend.Service.Name, Namespace: ing.Namespace}
+				s, err := p.dag.EnsureService(m, port, p.source)
+				if err != nil {
+					// cert-manager may not have created the
+					// solver Service yet.
+					validCond.AddWarningf(contour_api_v1.ConditionTypeServiceError, "ServiceUnresolvedReference",
+						"ACME solver Ingress %q unresolved service reference: %s", ing.Name, err)
+					continue
+				}
+
+				routes[host] = append(routes[host], acmeChallengeRoute(&ExactMatchCondition{Path: path.Path}, s))
+			}
+		}
+	}
+
+	return routes, true
+}
+
+// acmeChallengeRoute returns a route for ACME HTTP-01 challenges
+// matching the given path to the solver Service. Challenges are
+// always served over plain HTTP, so the route has the highest
+// priority to be ordered before any routes that upgrade to HTTPS.
+func acmeChallengeRoute(path MatchCondition, s *Service) *Route {
+	return &Route{
+		PathMatchCondition: path,
+		Priority:           math.MaxInt32,
+		Clusters: []*Cluster{{
+			Upstream: s,
+			Protocol: s.Protocol,
+		}},
+	}
+}
+
+// tlsSecret returns the named TLS secret of the root HTTPProxy. It
+// returns false and updates the condition if the secret is invalid or
+// is not delegated to the HTTPProxy's namespace. Certificates that
+// have expired or are not valid for the fqdn or one of the aliases
+// are still served, so that clients that don't verify them keep
+// working, but the condition reports an error. It adds a warning to
+// the condition if the certificate expires soon.
+func (p *HTTPProxyProcessor) tlsSecret(validCond *contour_api_v1.DetailedCondition, proxy *contour_api_v1.HTTPProxy, name string, aliases []string) (*Secret, bool) {
+	secretName := k8s.NamespacedNameFrom(name, k8s.DefaultNamespace(proxy.Namespace))
+	sec, err := p.source.LookupSecret(secretName, validSecret)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+			"Spec.VirtualHost.TLS Secret %q is invalid: %s", name, err)
+		return nil, false
+	}
+
+	if !p.source.DelegationPermitted(secretName, proxy.Namespace) {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "DelegationNotPermitted",
+			"Spec.VirtualHost.TLS Secret %q certificate delegation not permitted", name)
+		return nil, false
+	}
+
+	cert, err := sec.Certificate()
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+			"Spec.VirtualHost.TLS Secret %q is invalid: %s", name, err)
+		return nil, false
+	}
+
+	now := p.now()
+	if now.After(cert.NotAfter) {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "CertificateExpired",
+			"Spec.VirtualHost.TLS Secret %q certificate expired at %s", name, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	// Certificates that only have a common name can't be matched
+	// against the fqdn, since hostname verification only uses the
+	// subject alternative names.
+	if len(cert.DNSNames) > 0 {
+		if err := cert.VerifyHostname(proxy.Spec.VirtualHost.Fqdn); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "CertificateNameMismatch",
+				"Spec.VirtualHost.TLS Secret %q is not valid for fqdn %q: %s", name, proxy.Spec.VirtualHost.Fqdn, err)
+		}
+	}
+
+	for _, alias := range aliases {
+		if err := cert.VerifyHostname(alias); err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "CertificateNameMismatch",
+				"Spec.VirtualHost.TLS Secret %q is not valid for alias %q: %s", name, alias, err)
+		}
+	}
+
+	if p.CertificateExpiryWarning > 0 && now.Add(p.CertificateExpiryWarning).After(cert.NotAfter) {
+		validCond.AddWarningf(contour_api_v1.ConditionTypeTLSError, "CertificateExpiring",
+			"Spec.VirtualHost.TLS Secret %q certificate expires at %s", name, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return sec, true
+}
+
+// now returns the current time of the processor's clock.
+func (p *HTTPProxyProcessor) now() time.Time {
+	if p.Clock == nil {
+		return time.Now()
+	}
+	return p.Clock()
+}
+
+// sessionTicketKeys returns the TLS session ticket keys of the root
+// HTTPProxy, falling back to the configured session ticket keys. It
+// returns nil if neither is set, and returns false and updates the
+// condition if the secret is invalid or not delegated to the
+// HTTPProxy's namespace.
+func (p *HTTPProxyProcessor) sessionTicketKeys(validCond *contour_api_v1.DetailedCondition, proxy *contour_api_v1.HTTPProxy) (*Secret, bool) {
+	name := proxy.Spec.VirtualHost.TLS.SessionTicketKeysSecretName
+	if name == "" {
+		if p.SessionTicketKeys == nil {
+			return nil, true
+		}
+
+		sec, err := p.source.LookupSecret(*p.SessionTicketKeys, validSessionTicketKeys)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SessionTicketKeysNotValid",
+				"tls.session-ticket-keys Secret %q is invalid: %s", p.SessionTicketKeys, err)
+			return nil, false
+		}
+		return sec, true
+	}
+
+	secretName := k8s.NamespacedNameFrom(name, k8s.DefaultNamespace(proxy.Namespace))
+	sec, err := p.source.LookupSecret(secretName, validSessionTicketKeys)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "SessionTicketKeysNotValid",
+			"Spec.VirtualHost.TLS session ticket keys Secret %q is invalid: %s", name, err)
+		return nil, false
+	}
+
+	if !p.source.DelegationPermitted(secretName, proxy.Namespace) {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "DelegationNotPermitted",
+			"Spec.VirtualHost.TLS session ticket keys Secret %q certificate delegation not permitted", name)
+		return nil, false
+	}
+
+	return sec, true
+}
+
+// jwtProviders returns the JWT providers of the root HTTPProxy. It
+// returns false and updates the condition if any provider is invalid.
+func (p *HTTPProxyProcessor) jwtProviders(validCond *contour_api_v1.DetailedCondition, proxy *contour_api_v1.HTTPProxy) ([]JWTProvider, bool) {
+	var providers []JWTProvider
+	var defaultProvider string
+	names := map[string]bool{}
+
+	for i, jwt := range proxy.Spec.VirtualHost.JWTProviders {
+		if isBlank(jwt.Name) {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "ProviderNameNotValid",
+				"Spec.VirtualHost.JWTProviders[%d] name must be specified", i)
+			return nil, false
+		}
+
+		if names[jwt.Name] {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "DuplicateProviderName",
+				"Spec.VirtualHost.JWTProviders[%d] name %q is not unique", i, jwt.Name)
+			return nil, false
+		}
+		names[jwt.Name] = true
+
+		if jwt.Default {
+			if defaultProvider != "" {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "MultipleDefaultProvidersSpecified",
+					"Spec.VirtualHost.JWTProviders can have at most one default provider, but %q and %q are both marked as default", defaultProvider, jwt.Name)
+				return nil, false
+			}
+			defaultProvider = jwt.Name
+		}
+
+		provider := JWTProvider{
+			Name:       jwt.Name,
+			Issuer:     jwt.Issuer,
+			Audiences:  jwt.Audiences,
+			ForwardJWT: jwt.ForwardJWT,
+		}
+
+		switch {
+		case (jwt.RemoteJWKS == nil) == (jwt.LocalJWKS == nil):
+			validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "JWKSNotValid",
+				"Spec.VirtualHost.JWTProviders[%d] must specify exactly one of remoteJWKS or localJWKS", i)
+			return nil, false
+		case jwt.RemoteJWKS != nil:
+			remote, err := p.remoteJWKS(jwt.RemoteJWKS, proxy.Namespace)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "RemoteJWKSNotValid",
+					"Spec.VirtualHost.JWTProviders[%d].RemoteJWKS is invalid: %s", i, err)
+				return nil, false
+			}
+			provider.RemoteJWKS = remote
+		default:
+			local, err := p.localJWKS(jwt.LocalJWKS, proxy.Namespace)
+			if err != nil {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "LocalJWKSNotValid",
+					"Spec.VirtualHost.JWTProviders[%d].LocalJWKS is invalid: %s", i, err)
+				return nil, false
+			}
+			provider.LocalJWKS = local
+		}
+
+		for _, c := range jwt.ClaimsToHeaders {
+			if isBlank(c.Claim) {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "ClaimToHeaderNotValid",
+					"Spec.VirtualHost.JWTProviders[%d].ClaimsToHeaders claim must be specified", i)
+				return nil, false
+			}
+			if msgs := validation.IsHTTPHeaderName(c.Header); len(msgs) != 0 {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "ClaimToHeaderNotValid",
+					"Spec.VirtualHost.JWTProviders[%d].ClaimsToHeaders header %q is invalid: %s", i, c.Header, strings.Join(msgs, ","))
+				return nil, false
+			}
+
+			provider.ClaimsToHeaders = append(provider.ClaimsToHeaders, JWTClaimToHeader{
+				Claim:  c.Claim,
+				Header: c.Header,
+			})
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers, true
+}
+
+// remoteJWKS resolves the extension service that a remote JWKS is
+// fetched from.
+func (p *HTTPProxyProcessor) remoteJWKS(remote *contour_api_v1.RemoteJWKS, namespace string) (*RemoteJWKS, error) {
+	if err := validHTTPURI(remote.URI); err != nil {
+		return nil, err
+	}
+
+	ext, err := p.extensionCluster(remote.ExtensionServiceRef, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := &RemoteJWKS{
+		URI:     remote.URI,
+		Cluster: ext,
+		Timeout: time.Second,
+	}
+
+	fetchTimeout, err := timeout.Parse(remote.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("timeout is invalid: %s", err)
+	}
+	if fetchTimeout.IsDisabled() {
+		return nil, fmt.Errorf("timeout %q cannot be disabled", remote.Timeout)
+	}
+	if !fetchTimeout.UseDefault() {
+		jwks.Timeout = fetchTimeout.Duration()
+	}
+
+	if remote.CacheDuration != "" {
+		jwks.CacheDuration, err = time.ParseDuration(remote.CacheDuration)
+		if err != nil {
+			return nil, fmt.Errorf("cacheDuration is invalid: %s", err)
+		}
+	}
+
+	return jwks, nil
+}
+
+// extensionCluster returns the extension cluster of the referenced
+// ExtensionService.
+func (p *HTTPProxyProcessor) extensionCluster(ref contour_api_v1.ExtensionServiceReference, namespace string) (*ExtensionCluster, error) {
+	ref = defaultExtensionRef(ref)
+	if ref.APIVersion != contour_api_v1alpha1.GroupVersion.String() {
+		return nil, fmt.Errorf("extensionRef specifies an unsupported resource version %q", ref.APIVersion)
+	}
+
+	extensionName := types.NamespacedName{
+		Name:      ref.Name,
+		Namespace: stringOrDefault(ref.Namespace, namespace),
+	}
+
+	ext := p.dag.GetExtensionCluster(ExtensionClusterName(extensionName))
+	if ext == nil {
+		return nil, fmt.Errorf("extension service %q not found", extensionName)
+	}
+
+	return ext, nil
+}
+
+// routeAuthorization returns the authorization server that
+// overrides the authorization server of the virtual host for
+// a route.
+func (p *HTTPProxyProcessor) routeAuthorization(auth *contour_api_v1.AuthorizationServer, namespace string) (*ExternalAuthorization, error) {
+	if auth.AuthPolicy != nil {
+		return nil, errors.New("authPolicy is not permitted, use the authPolicy of the route")
+	}
+
+	ext, err := p.extensionCluster(auth.ExtensionServiceRef, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validAuthorizationProtocol(ext); err != nil {
+		return nil, err
+	}
+
+	responseTimeout, err := timeout.Parse(auth.ResponseTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("responseTimeout is invalid: %s", err)
+	}
+	if responseTimeout.UseDefault() {
+		responseTimeout = ext.TimeoutPolicy.ResponseTimeout
+	}
+
+	body, headers, err := authorizationServerRequest(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExternalAuthorization{
+		AuthorizationService:               ext,
+		AuthorizationResponseTimeout:       responseTimeout,
+		AuthorizationFailOpen:              auth.FailOpen,
+		AuthorizationServerWithRequestBody: body,
+		AuthorizationAllowedHeaders:        headers,
+	}, nil
+}
+
+// validAuthorizationProtocol returns an error if the extension
+// cannot be sent authorization requests.
+func validAuthorizationProtocol(ext *ExtensionCluster) error {
+	if ext.HTTPAuthorization == nil && ext.Protocol != "h2" && ext.Protocol != "h2c" {
+		return fmt.Errorf("GRPC authorization requires the h2 or h2c protocol, not %q", ext.Protocol)
+	}
+
+	return nil
+}
+
+// validHTTPURI returns an error if s is not an absolute HTTP or HTTPS URI.
+func validHTTPURI(s string) error {
+	uri, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("URI %q does not parse: %s", s, err)
+	}
+	if (uri.Scheme != "http" && uri.Scheme != "https") || uri.Host == "" {
+		return fmt.Errorf("URI %q must be an absolute HTTP or HTTPS URI", s)
+	}
+
+	return nil
+}
+
+// localJWKS returns the contents of a local JWKS, looking up the
+// Secret or ConfigMap that holds it if necessary.
+func (p *HTTPProxyProcessor) localJWKS(local *contour_api_v1.LocalJWKS, namespace string) (string, error) {
+	var sources int
+	for _, s := range []string{local.Inline, local.SecretName, local.ConfigMapName} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return "", errors.New("exactly one of inline, secretName or configMapName must be specified")
+	}
+
+	var jwks string
+	switch {
+	case local.SecretName != "":
+		sec, err := p.source.LookupSecret(types.NamespacedName{Name: local.SecretName, Namespace: namespace}, validJWKS)
+		if err != nil {
+			return "", fmt.Errorf("Secret %q is invalid: %s", local.SecretName, err)
+		}
+		jwks = string(sec.Object.Data[JWKSKey])
+	case local.ConfigMapName != "":
+		cm, err := p.source.LookupConfigMap(types.NamespacedName{Name: local.ConfigMapName, Namespace: namespace})
+		if err != nil {
+			return "", fmt.Errorf("ConfigMap %q is invalid: %s", local.ConfigMapName, err)
+		}
+		jwks = cm.Data[JWKSKey]
+	default:
+		jwks = local.Inline
+	}
+
+	if !json.Valid([]byte(jwks)) {
+		return "", errors.New("JWKS is not a JSON document")
+	}
+
+	return jwks, nil
+}
+
+// certificateRevocationList returns the contents of a certificate
+// revocation list, looking up the Secret or ConfigMap that holds it.
+func (p *HTTPProxyProcessor) certificateRevocationList(crl *contour_api_v1.CertificateRevocationList, namespace string) ([]byte, error) {
+	if (crl.SecretName == "") == (crl.ConfigMapName == "") {
+		return nil, errors.New("exactly one of secretName or configMapName must be specified")
+	}
+
+	if crl.SecretName != "" {
+		sec, err := p.source.LookupSecret(types.NamespacedName{Name: crl.SecretName, Namespace: namespace}, validCRL)
+		if err != nil {
+			return nil, fmt.Errorf("Secret %q is invalid: %s", crl.SecretName, err)
+		}
+		return sec.Object.Data[CRLKey], nil
+	}
+
+	cm, err := p.source.LookupConfigMap(types.NamespacedName{Name: crl.ConfigMapName, Namespace: namespace})
+	if err != nil {
+		return nil, fmt.Errorf("ConfigMap %q is invalid: %s", crl.ConfigMapName, err)
+	}
+
+	data := []byte(cm.Data[CRLKey])
+	if err := validateCRL(data); err != nil {
+		return nil, fmt.Errorf("ConfigMap %q is invalid: %s", crl.ConfigMapName, err)
+	}
+
+	return data, nil
+}
+
+// routeJWTProvider returns the name of the JWT provider that verifies
+// requests to the route, and whether requests are allowed to omit the
+// JWT. An empty name means that JWTs are not verified.
+func routeJWTProvider(providers []contour_api_v1.JWTProvider, policy *contour_api_v1.JWTVerificationPolicy) (string, bool, error) {
+	mode := "required"
+	var name string
+	if policy != nil {
+		mode = policy.Mode
+		name = policy.Provider
+	}
+
+	if mode == "disabled" {
+		return "", false, nil
+	}
+
+	if name == "" {
+		for _, provider := range providers {
+			if provider.Default {
+				return provider.Name, mode == "optional", nil
+			}
+		}
+
+		// Without a default provider, only routes
+		// that explicitly opt in verify JWTs.
+		if policy == nil {
+			return "", false, nil
+		}
+		return "", false, errors.New("route's JWT verification policy does not name a provider and there is no default provider")
+	}
+
+	for _, provider := range providers {
+		if provider.Name == name {
+			return name, mode == "optional", nil
+		}
+	}
+
+	return "", false, fmt.Errorf("route's JWT verification policy references an undefined provider %q", name)
+}
+
+// lookupBasicAuth returns the basic authentication credentials held
+// by the Secret referenced by ba. The realm defaults to the given realm
+// if ba does not set one. It returns false and updates the condition if
+// the Secret is invalid.
+func (p *HTTPProxyProcessor) lookupBasicAuth(
+	validCond *contour_api_v1.DetailedCondition,
+	field string,
+	ba *contour_api_v1.BasicAuth,
+	namespace string,
+	realm string,
+) (*BasicAuth, bool) {
+	if ba.Realm != "" {
+		realm = ba.Realm
+	}
+
+	if err := validRealm(realm); err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotValid",
+			"%s.realm %q is invalid: %s", field, realm, err)
+		return nil, false
+	}
+
+	secretName := types.NamespacedName{Name: ba.SecretName, Namespace: namespace}
+	sec, err := p.source.LookupSecret(secretName, validBasicAuth)
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeBasicAuthError, "SecretNotValid",
+			"%s Secret %q is invalid: %s", field, ba.SecretName, err)
+		return nil, false
+	}
+
+	users, unsupported, err := parseHtpasswd(sec.Object.Data[BasicAuthKey])
+	if err != nil {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeBasicAuthError, "SecretNotValid",
+			"%s Secret %q is invalid: %s", field, ba.SecretName, err)
+		return nil, false
+	}
+
+	// Rather than locking users out, Secrets with hashes
+	// that cannot be verified are rejected.
+	if len(unsupported) > 0 {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeBasicAuthError, "UnsupportedPasswordHash",
+			"%s Secret %q has password hashes in unsupported formats, only bcrypt, apr1 and SHA1 hashes are supported: %s",
+			field, ba.SecretName, strings.Join(unsupported, ", "))
+		return nil, false
+	}
+
+	if len(users) == 0 {
+		validCond.AddErrorf(contour_api_v1.ConditionTypeBasicAuthError, "SecretNotValid",
+			"%s Secret %q has no users that can authenticate", field, ba.SecretName)
+		return nil, false
+	}
+
+	return &BasicAuth{Realm: realm, Secret: secretName, Users: users}, true
+}
+
+// validRealm returns an error if the realm cannot be sent to clients
+// as a quoted string in the WWW-Authenticate header.
+func validRealm(realm string) error {
+	for _, r := range realm {
+		if r < 0x20 || r == 0x7f || r == '"' || r == '\\' {
+			return errors.New("must not contain quotes, backslashes or control characters")
+		}
+	}
+
+	return nil
+}
 
-			svhost := p.dag.EnsureSecureVirtualHost(ListenerName{Name: host, ListenerName: "ingress_https"})
-			svhost.Secret = sec
-			// default to a minimum TLS version of 1.2 if it's not specified
-			svhost.MinTLSVersion = annotation.MinTLSVersion(tls.MinimumProtocolVersion, "1.2")
+// validAliases returns the lower cased aliases of the root HTTPProxy,
+// excluding any that duplicate its fqdn. It returns false and updates
+// the condition if any alias is invalid.
+func validAliases(validCond *contour_api_v1.DetailedCondition, proxy *contour_api_v1.HTTPProxy) ([]string, bool) {
+	fqdn := strings.ToLower(proxy.Spec.VirtualHost.Fqdn)
+	seen := map[string]bool{fqdn: true}
+
+	var aliases []string
+	for i, alias := range proxy.Spec.VirtualHost.Aliases {
+		if isBlank(alias) {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "AliasNotValid",
+				"Spec.VirtualHost.Aliases[%d] must not be empty", i)
+			return nil, false
+		}
 
-			// Check if FallbackCertificate && ClientValidation are both enabled in the same vhost
-			if tls.EnableFallbackCertificate && tls.ClientValidation != nil {
-				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
-					"Spec.Virtualhost.TLS fallback & client validation are incompatible")
-				return
+		if strings.Contains(alias, "*") {
+			if errs := validation.IsWildcardDNS1123Subdomain(alias); len(errs) > 0 {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "WildCardNotAllowed",
+					"Spec.VirtualHost.Aliases[%d] %q can only use a wildcard as the first DNS label", i, alias)
+				return nil, false
 			}
+		} else if errs := validation.IsDNS1123Subdomain(strings.ToLower(alias)); len(errs) > 0 {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "AliasNotValid",
+				"Spec.VirtualHost.Aliases[%d] %q is not a valid fqdn: %s", i, alias, strings.Join(errs, ", "))
+			return nil, false
+		}
 
-			// Fallback certificates and authorization are
-			// incompatible because fallback installs the routes on
-			// a separate HTTPConnectionManager. We can't have the
-			// same routes installed on multiple managers with
-			// inconsistent authorization settings.
-			if tls.EnableFallbackCertificate && proxy.Spec.VirtualHost.AuthorizationConfigured() {
-				validCond.AddError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures",
-					"Spec.Virtualhost.TLS fallback & client authorization are incompatible")
-				return
-			}
+		alias = strings.ToLower(alias)
+		if seen[alias] {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateAlias",
+				"Spec.VirtualHost.Aliases[%d] %q is already served by this virtual host", i, alias)
+			return nil, false
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
 
-			// If FallbackCertificate is enabled, but no cert passed, set error
-			if tls.EnableFallbackCertificate {
-				if p.FallbackCertificate == nil {
-					validCond.AddError(contour_api_v1.ConditionTypeTLSError, "FallbackNotPresent",
-						"Spec.Virtualhost.TLS enabled fallback but the fallback Certificate Secret is not configured in Contour configuration file")
-					return
-				}
+	return aliases, true
+}
 
-				sec, err = p.source.LookupSecret(*p.FallbackCertificate, validSecret)
-				if err != nil {
-					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "FallbackNotValid",
-						"Spec.Virtualhost.TLS Secret %q fallback certificate is invalid: %s", p.FallbackCertificate, err)
-					return
-				}
+// hostRoutes returns the routes to add to the virtual host named host.
+// Wildcard virtual hosts can match multiple DNS labels in Envoy, so
+// each route is copied and guarded with a header match that only
+// matches a single label.
+func hostRoutes(host string, routes []*Route) []*Route {
+	if !strings.HasPrefix(host, "*.") {
+		return routes
+	}
 
-				if !p.source.DelegationPermitted(*p.FallbackCertificate, proxy.Namespace) {
-					validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "FallbackNotDelegated",
-						"Spec.VirtualHost.TLS fallback Secret %q is not configured for certificate delegation", p.FallbackCertificate)
-					return
-				}
+	guarded := make([]*Route, 0, len(routes))
+	for _, route := range routes {
+		r := *route
+		r.HeaderMatchConditions = append(append([]HeaderMatchCondition{}, route.HeaderMatchConditions...),
+			wildcardDomainHeaderMatch(host))
+		guarded = append(guarded, &r)
+	}
+	return guarded
+}
 
-				svhost.FallbackCertificate = sec
-			}
+// aliasVirtualHost returns a copy of the virtual host for the alias.
+// The copy carries every policy of the virtual host, but only the
+// given routes, guarded for the alias if it is a wildcard.
+func aliasVirtualHost(vhost VirtualHost, alias string, routes []*Route) VirtualHost {
+	vhost.Name = alias
+	vhost.routes = nil
+	addRoutes(&vhost, hostRoutes(alias, routes))
+	return vhost
+}
 
-			// Fill in DownstreamValidation when external client validation is enabled.
-			if tls.ClientValidation != nil {
-				dv := &PeerValidationContext{
-					SkipClientCertValidation: tls.ClientValidation.SkipClientCertValidation,
-				}
-				if !tls.ClientValidation.SkipClientCertValidation {
-					if tls.ClientValidation.CACertificate != "" {
-						secretName := k8s.NamespacedNameFrom(tls.ClientValidation.CACertificate, k8s.DefaultNamespace(proxy.Namespace))
-						cacert, err := p.source.LookupSecret(secretName, validCA)
-						if err != nil {
-							// PeerValidationContext is requested, but cert is missing or not configured.
-							validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
-								"Spec.VirtualHost.TLS client validation is invalid: invalid CA Secret %q: %s", secretName, err)
-							return
-						}
-						dv.CACertificate = cacert
-					} else {
-						validCond.AddErrorf(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
-							"Spec.VirtualHost.TLS client validation is invalid: CA Secret must be specified")
-					}
-				}
-				svhost.DownstreamValidation = dv
-			}
+// clampPriority returns the route priority clamped to the given range,
+// and whether the priority had to be clamped.
+func clampPriority(priority int32, priorityRange *contour_api_v1.RoutePriorityRange) (int32, bool) {
+	switch {
+	case priorityRange == nil:
+		return priority, false
+	case priority < priorityRange.Min:
+		return priorityRange.Min, true
+	case priority > priorityRange.Max:
+		return priorityRange.Max, true
+	default:
+		return priority, false
+	}
+}
 
-			if proxy.Spec.VirtualHost.AuthorizationConfigured() {
-				auth := proxy.Spec.VirtualHost.Authorization
-				ref := defaultExtensionRef(auth.ExtensionServiceRef)
+// reportRoutePriorityTies adds a warning to the condition for each
+// explicit route priority that is used by more than one route. Routes
+// that share a priority are ordered by the default sorting rules.
+func reportRoutePriorityTies(validCond *contour_api_v1.DetailedCondition, routePriorities map[int32][]string) {
+	var priorities []int
+	for priority, proxies := range routePriorities {
+		if len(proxies) > 1 {
+			priorities = append(priorities, int(priority))
+		}
+	}
+	sort.Ints(priorities)
 
-				if ref.APIVersion != contour_api_v1alpha1.GroupVersion.String() {
-					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthBadResourceVersion",
-						"Spec.Virtualhost.Authorization.extensionRef specifies an unsupported resource version %q", auth.ExtensionServiceRef.APIVersion)
-					return
-				}
+	for _, priority := range priorities {
+		proxies := routePriorities[int32(priority)]
 
-				// Lookup the extension service reference.
-				extensionName := types.NamespacedName{
-					Name:      ref.Name,
-					Namespace: stringOrDefault(ref.Namespace, proxy.Namespace),
-				}
+		var names []string
+		seen := map[string]bool{}
+		for _, name := range proxies {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
 
-				ext := p.dag.GetExtensionCluster(ExtensionClusterName(extensionName))
-				if ext == nil {
-					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "ExtensionServiceNotFound",
-						"Spec.Virtualhost.Authorization.ServiceRef extension service %q not found", extensionName)
-					return
-				}
+		validCond.AddWarningf(contour_api_v1.ConditionTypeRouteError, "PriorityTie",
+			"%d routes share priority %d and are ordered by their match conditions: %s",
+			len(proxies), priority, strings.Join(names, ", "))
+	}
+}
 
-				svhost.AuthorizationService = ext
-				svhost.AuthorizationFailOpen = auth.FailOpen
+// includedProxies returns the HTTPProxies referenced by the include,
+// either by name or by label selector. Selected HTTPProxies are returned
+// in namespace and name order. It returns false and updates the
+// condition if the include is invalid.
+func (p *HTTPProxyProcessor) includedProxies(
+	validCond *contour_api_v1.DetailedCondition,
+	proxy *contour_api_v1.HTTPProxy,
+	include contour_api_v1.Include,
+) ([]*contour_api_v1.HTTPProxy, bool) {
+	if (include.Name == "") == (include.Selector == nil) {
+		validCond.AddError(contour_api_v1.ConditionTypeIncludeError, "IncludeNotValid",
+			"include must specify exactly one of name or selector")
+		return nil, false
+	}
 
-				timeout, err := timeout.Parse(auth.ResponseTimeout)
-				if err != nil {
-					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthResponseTimeoutInvalid",
-						"Spec.Virtualhost.Authorization.ResponseTimeout is invalid: %s", err)
-					return
-				}
+	if include.Selector == nil {
+		namespace := stringOrDefault(include.Namespace, proxy.Namespace)
 
-				if timeout.UseDefault() {
-					svhost.AuthorizationResponseTimeout = ext.TimeoutPolicy.ResponseTimeout
-				} else {
-					svhost.AuthorizationResponseTimeout = timeout
-				}
-			}
+		if include.NamespaceSelector != nil {
+			validCond.AddError(contour_api_v1.ConditionTypeIncludeError, "IncludeNotValid",
+				"include namespaceSelector can only be used with selector")
+			return nil, false
 		}
-	}
 
-	if proxy.Spec.TCPProxy != nil {
-		if !tlsEnabled {
-			validCond.AddError(contour_api_v1.ConditionTypeTCPProxyError, "TLSMustBeConfigured",
-				"Spec.TCPProxy requires that either Spec.TLS.Passthrough or Spec.TLS.SecretName be set")
-			return
+		includedProxy, ok := p.source.httpproxies[types.NamespacedName{Name: include.Name, Namespace: namespace}]
+		if !ok {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "IncludeNotFound",
+				"include %s/%s not found", namespace, include.Name)
+			return nil, false
 		}
-		if !p.processHTTPProxyTCPProxy(validCond, proxy, nil, host) {
-			return
+		if includedProxy.Spec.VirtualHost != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "RootIncludesRoot",
+				"root httpproxy cannot include another root httpproxy")
+			return nil, false
 		}
+		return []*contour_api_v1.HTTPProxy{includedProxy}, true
 	}
 
-	routes := p.computeRoutes(validCond, proxy, proxy, nil, nil, tlsEnabled)
-	insecure := p.dag.EnsureVirtualHost(ListenerName{Name: host, ListenerName: "ingress_http"})
-	cp, err := toCORSPolicy(proxy.Spec.VirtualHost.CORSPolicy)
-	if err != nil {
-		validCond.AddErrorf(contour_api_v1.ConditionTypeCORSError, "PolicyDidNotParse",
-			"Spec.VirtualHost.CORSPolicy: %s", err)
-		return
+	if include.Namespace != "" && include.NamespaceSelector != nil {
+		validCond.AddError(contour_api_v1.ConditionTypeIncludeError, "IncludeNotValid",
+			"include cannot specify both namespace and namespaceSelector")
+		return nil, false
 	}
-	insecure.CORSPolicy = cp
 
-	rlp, err := rateLimitPolicy(proxy.Spec.VirtualHost.RateLimitPolicy)
+	selector, err := metav1.LabelSelectorAsSelector(include.Selector)
 	if err != nil {
-		validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RateLimitPolicyNotValid",
-			"Spec.VirtualHost.RateLimitPolicy is invalid: %s", err)
-		return
+		validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "IncludeSelectorNotValid",
+			"include selector is invalid: %s", err)
+		return nil, false
 	}
-	insecure.RateLimitPolicy = rlp
-
-	addRoutes(insecure, routes)
-
-	// if TLS is enabled for this virtual host and there is no tcp proxy defined,
-	// then add routes to the secure virtualhost definition.
-	if tlsEnabled && proxy.Spec.TCPProxy == nil {
-		secure := p.dag.EnsureSecureVirtualHost(ListenerName{Name: host, ListenerName: "ingress_https"})
-		secure.CORSPolicy = cp
 
-		rlp, err := rateLimitPolicy(proxy.Spec.VirtualHost.RateLimitPolicy)
+	namespaceMatches := func(namespace string) bool {
+		return namespace == stringOrDefault(include.Namespace, proxy.Namespace)
+	}
+	if include.NamespaceSelector != nil {
+		namespaceSelector, err := metav1.LabelSelectorAsSelector(include.NamespaceSelector)
 		if err != nil {
-			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "RateLimitPolicyNotValid",
-				"Spec.VirtualHost.RateLimitPolicy is invalid: %s", err)
-			return
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "IncludeSelectorNotValid",
+				"include namespaceSelector is invalid: %s", err)
+			return nil, false
 		}
-		secure.RateLimitPolicy = rlp
 
-		addRoutes(secure, routes)
+		namespaceMatches = func(namespace string) bool {
+			ns, ok := p.source.namespaces[namespace]
+			return ok && namespaceSelector.Matches(labels.Set(ns.Labels))
+		}
+	}
+
+	var selected []*contour_api_v1.HTTPProxy
+	for _, candidate := range p.source.httpproxies {
+		// An HTTPProxy never selects itself, and root
+		// HTTPProxies cannot be included.
+		if candidate == proxy || candidate.Spec.VirtualHost != nil {
+			continue
+		}
+		if namespaceMatches(candidate.Namespace) && selector.Matches(labels.Set(candidate.Labels)) {
+			selected = append(selected, candidate)
+		}
 	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].Namespace != selected[j].Namespace {
+			return selected[i].Namespace < selected[j].Namespace
+		}
+		return selected[i].Name < selected[j].Name
+	})
+
+	return selected, true
 }
 
 type vhost interface {
@@ -365,9 +1702,11 @@ func addRoutes(vhost vhost, routes []*Route) {
 
 func (p *HTTPProxyProcessor) computeRoutes(
 	validCond *contour_api_v1.DetailedCondition,
+	root *rootVirtualHost,
 	rootProxy *contour_api_v1.HTTPProxy,
 	proxy *contour_api_v1.HTTPProxy,
 	conditions []contour_api_v1.MatchCondition,
+	priorityRange *contour_api_v1.RoutePriorityRange,
 	visited []*contour_api_v1.HTTPProxy,
 	enforceTLS bool,
 ) []*Route {
@@ -397,20 +1736,8 @@ func (p *HTTPProxyProcessor) computeRoutes(
 
 	// Loop over and process all includes
 	for _, include := range proxy.Spec.Includes {
-		namespace := include.Namespace
-		if namespace == "" {
-			namespace = proxy.Namespace
-		}
-
-		includedProxy, ok := p.source.httpproxies[types.NamespacedName{Name: include.Name, Namespace: namespace}]
+		includedProxies, ok := p.includedProxies(validCond, proxy, include)
 		if !ok {
-			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "IncludeNotFound",
-				"include %s/%s not found", namespace, include.Name)
-			return nil
-		}
-		if includedProxy.Spec.VirtualHost != nil {
-			validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "RootIncludesRoot",
-				"root httpproxy cannot include another root httpproxy")
 			return nil
 		}
 
@@ -420,13 +1747,31 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
-		inc, incCommit := p.dag.StatusCache.ProxyAccessor(includedProxy)
-		incValidCond := inc.ConditionFor(status.ValidCondition)
-		routes = append(routes, p.computeRoutes(incValidCond, rootProxy, includedProxy, append(conditions, include.Conditions...), visited, enforceTLS)...)
-		incCommit()
+		includeRange := priorityRange
+		if r := include.PriorityRange; r != nil {
+			if r.Min > r.Max {
+				validCond.AddErrorf(contour_api_v1.ConditionTypeIncludeError, "PriorityRangeNotValid",
+					"include priorityRange min %d is greater than max %d", r.Min, r.Max)
+				return nil
+			}
+
+			// A nested range can only narrow the range
+			// permitted by the enclosing includes.
+			min, _ := clampPriority(r.Min, priorityRange)
+			max, _ := clampPriority(r.Max, priorityRange)
+			includeRange = &contour_api_v1.RoutePriorityRange{Min: min, Max: max}
+		}
 
-		// dest is not an orphaned httpproxy, as there is an httpproxy that points to it
-		delete(p.orphaned, types.NamespacedName{Name: includedProxy.Name, Namespace: includedProxy.Namespace})
+		for _, includedProxy := range includedProxies {
+			inc, incCommit := p.dag.StatusCache.ProxyAccessor(includedProxy)
+			inc.AddIncludedBy(k8s.NamespacedNameOf(rootProxy))
+			incValidCond := inc.ConditionFor(status.ValidCondition)
+			routes = append(routes, p.computeRoutes(incValidCond, root, rootProxy, includedProxy, append(conditions, include.Conditions...), includeRange, visited, enforceTLS)...)
+			incCommit()
+
+			// dest is not an orphaned httpproxy, as there is an httpproxy that points to it
+			delete(p.orphaned, types.NamespacedName{Name: includedProxy.Name, Namespace: includedProxy.Namespace})
+		}
 	}
 
 	dynamicHeaders := map[string]string{
@@ -463,6 +1808,11 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		if root.hstsPolicy != nil && setsHeader(route.ResponseHeadersPolicy, hstsHeader) {
+			validCond.AddWarningf(contour_api_v1.ConditionTypeHSTSError, "HSTSPolicyConflict",
+				"route.responseHeadersPolicy %s header is overridden by the HSTS policy on TLS responses", hstsHeader)
+		}
+
 		if len(route.Services) < 1 {
 			validCond.AddError(contour_api_v1.ConditionTypeRouteError, "NoServicesPresent",
 				"route.services must have at least one entry")
@@ -483,8 +1833,29 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			return nil
 		}
 
+		csrf, err := csrfPolicy(route.CSRFPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeRouteError, "CSRFPolicyNotValid",
+				"route.csrfPolicy is invalid: %s", err)
+			return nil
+		}
+
 		requestHashPolicies, lbPolicy := loadBalancerRequestHashPolicies(route.LoadBalancerPolicy, validCond)
 
+		// Routes that do not set a priority keep the default,
+		// unless they are included with a priority range that
+		// excludes it, in which case they are assigned the
+		// nearest bound. Only explicit priorities are reported.
+		priority, clamped := clampPriority(route.Priority, priorityRange)
+		if route.Priority != 0 {
+			if clamped {
+				validCond.AddWarningf(contour_api_v1.ConditionTypeRouteError, "PriorityClamped",
+					"route priority %d is outside the permitted range [%d, %d] and was clamped to %d",
+					route.Priority, priorityRange.Min, priorityRange.Max, priority)
+			}
+			root.routePriorities[priority] = append(root.routePriorities[priority], proxy.Namespace+"/"+proxy.Name)
+		}
+
 		r := &Route{
 			PathMatchCondition:    mergePathMatchConditions(conds),
 			HeaderMatchConditions: mergeHeaderMatchConditions(conds),
@@ -495,7 +1866,9 @@ func (p *HTTPProxyProcessor) computeRoutes(
 			RequestHeadersPolicy:  reqHP,
 			ResponseHeadersPolicy: respHP,
 			RateLimitPolicy:       rlp,
+			CSRFPolicy:            csrf,
 			RequestHashPolicies:   requestHashPolicies,
+			Priority:              priority,
 		}
 
 		// If the enclosing root proxy enabled authorization,
@@ -518,8 +1891,111 @@ func (p *HTTPProxyProcessor) computeRoutes(
 
 			r.AuthDisabled = disabled
 			r.AuthContext = route.AuthorizationContext(rootProxy.Spec.VirtualHost.AuthorizationContext())
+
+			if route.Authorization != nil {
+				auth, err := p.routeAuthorization(route.Authorization, proxy.Namespace)
+				if err != nil {
+					validCond.AddErrorf(contour_api_v1.ConditionTypeAuthError, "AuthorizationServerNotValid",
+						"route.authorization is invalid: %s", err)
+					return nil
+				}
+
+				if len(auth.AuthorizationAllowedHeaders) > 0 && auth.AuthorizationService.HTTPAuthorization == nil {
+					validCond.AddWarningf(contour_api_v1.ConditionTypeAuthError, "AllowedHeadersNotSupported",
+						"route.authorization.allowedHeaders are not supported by gRPC authorization servers, all request headers are sent")
+				}
+
+				r.AuthorizationServer = auth
+			}
+		} else if route.Authorization != nil {
+			validCond.AddError(contour_api_v1.ConditionTypeAuthError, "AuthorizationServerNotPermitted",
+				"route.authorization requires the root HTTPProxy to configure Spec.VirtualHost.Authorization")
+			return nil
 		}
 
+		jwtProvider, jwtAllowMissing, err := routeJWTProvider(rootProxy.Spec.VirtualHost.JWTProviders, route.JWTVerificationPolicy)
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeJWTVerificationError, "JWTProviderNotDefined",
+				"%s", err)
+			return nil
+		}
+		if jwtProvider != "" && !r.HTTPSUpgrade {
+			// Insecure virtual hosts do not verify JWTs, so
+			// the route must not be reachable over HTTP.
+			validCond.AddError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationNotPermitted",
+				"route cannot verify JWTs and permit insecure requests")
+			return nil
+		}
+		r.JWTProvider = jwtProvider
+		r.JWTAllowMissing = jwtAllowMissing
+
+		// Take the basic authentication from the virtual
+		// host, unless the route has its own policy.
+		r.BasicAuth = root.basicAuth
+		if ba := route.BasicAuth; ba != nil {
+			switch {
+			case ba.Disabled:
+				r.BasicAuth = nil
+			case !isBlank(ba.SecretName):
+				auth, ok := p.lookupBasicAuth(validCond, "route.basicAuth", ba, proxy.Namespace, rootProxy.Spec.VirtualHost.Fqdn)
+				if !ok {
+					return nil
+				}
+				r.BasicAuth = auth
+			case root.basicAuth != nil:
+				if ba.Realm != "" {
+					if err := validRealm(ba.Realm); err != nil {
+						validCond.AddErrorf(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotValid",
+							"route.basicAuth.realm %q is invalid: %s", ba.Realm, err)
+						return nil
+					}
+					r.BasicAuth = &BasicAuth{
+						Realm:  ba.Realm,
+						Secret: root.basicAuth.Secret,
+						Users:  root.basicAuth.Users,
+					}
+				}
+			default:
+				validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotValid",
+					"route.basicAuth must specify a secretName when the virtual host does not require basic authentication")
+				return nil
+			}
+		}
+		if r.BasicAuth != nil {
+			// Insecure virtual hosts and the fallback
+			// certificate do not enforce basic authentication,
+			// so the route must only be reachable over TLS
+			// with SNI.
+			if !r.HTTPSUpgrade {
+				validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+					"route cannot require basic authentication and permit insecure requests")
+				return nil
+			}
+			if rootProxy.Spec.VirtualHost.TLS.EnableFallbackCertificate {
+				validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+					"route cannot require basic authentication when the fallback certificate is enabled")
+				return nil
+			}
+			if rootProxy.Spec.VirtualHost.AuthorizationConfigured() {
+				validCond.AddError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+					"route cannot require basic authentication when the virtual host has an authorization server")
+				return nil
+			}
+		}
+
+		// The IP filter policy of the route can only narrow
+		// the policy of the virtual host.
+		ipFilter, err := ipFilterPolicy(route.IPFilterPolicy)
+		if err == nil {
+			ipFilter, err = narrowIPFilterPolicy(root.ipFilterPolicy, ipFilter)
+		}
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+				"route.ipFilterPolicy is invalid: %s", err)
+			return nil
+		}
+		r.IPFilterPolicy = ipFilter
+
 		if len(route.GetPrefixReplacements()) > 0 {
 			if !r.HasPathPrefix() {
 				validCond.AddError(contour_api_v1.ConditionTypePrefixReplaceError, "MustHavePrefix",
@@ -606,6 +2082,11 @@ func (p *HTTPProxyProcessor) computeRoutes(
 				return nil
 			}
 
+			if root.hstsPolicy != nil && setsHeader(service.ResponseHeadersPolicy, hstsHeader) {
+				validCond.AddWarningf(contour_api_v1.ConditionTypeHSTSError, "HSTSPolicyConflict",
+					"service.responseHeadersPolicy %s header is overridden by the HSTS policy on TLS responses", hstsHeader)
+			}
+
 			var clientCertSecret *Secret
 			if p.ClientCertificate != nil {
 				clientCertSecret, err = p.source.LookupSecret(*p.ClientCertificate, validSecret)
@@ -653,14 +2134,28 @@ func (p *HTTPProxyProcessor) computeRoutes(
 // processHTTPProxyTCPProxy processes the spec.tcpproxy stanza in a HTTPProxy document
 // following the chain of spec.tcpproxy.include references. It returns true if processing
 // was successful, otherwise false if an error was encountered. The details of the error
-// will be recorded on the status of the relevant HTTPProxy object,
-func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.DetailedCondition, httpproxy *contour_api_v1.HTTPProxy, visited []*contour_api_v1.HTTPProxy, host string) bool {
+// will be recorded on the status of the relevant HTTPProxy object.
+// The ipFilter is the IP filter policy inherited from the virtual host
+// or including tcpproxy, which the tcpproxy policy can only narrow.
+func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.DetailedCondition, httpproxy *contour_api_v1.HTTPProxy, visited []*contour_api_v1.HTTPProxy, host ListenerName, ipFilter *IPFilterPolicy) bool {
 	tcpproxy := httpproxy.Spec.TCPProxy
 	if tcpproxy == nil {
 		// nothing to do
 		return true
 	}
 
+	if tcpproxy.IPFilterPolicy != nil {
+		policy, err := ipFilterPolicy(tcpproxy.IPFilterPolicy)
+		if err == nil {
+			ipFilter, err = narrowIPFilterPolicy(ipFilter, policy)
+		}
+		if err != nil {
+			validCond.AddErrorf(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+				"Spec.TCPProxy.IPFilterPolicy is invalid: %s", err)
+			return false
+		}
+	}
+
 	visited = append(visited, httpproxy)
 
 	// #2218 Allow support for both plural and singular "Include" for TCPProxy for the v1 API Spec
@@ -687,7 +2182,9 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 	}
 
 	if len(tcpproxy.Services) > 0 {
-		var proxy TCPProxy
+		proxy := TCPProxy{
+			IPFilterPolicy: ipFilter,
+		}
 		for _, service := range httpproxy.Spec.TCPProxy.Services {
 			m := types.NamespacedName{Name: service.Name, Namespace: httpproxy.Namespace}
 			s, err := p.dag.EnsureService(m, intstr.FromInt(service.Port), p.source)
@@ -712,7 +2209,7 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 				SNI:                  s.ExternalName,
 			})
 		}
-		secure := p.dag.EnsureSecureVirtualHost(ListenerName{Name: host, ListenerName: "ingress_https"})
+		secure := p.dag.EnsureSecureVirtualHost(host)
 		secure.TCPProxy = &proxy
 
 		return true
@@ -767,7 +2264,7 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 	inc, commit := p.dag.StatusCache.ProxyAccessor(dest)
 	incValidCond := inc.ConditionFor(status.ValidCondition)
 	defer commit()
-	ok = p.processHTTPProxyTCPProxy(incValidCond, dest, visited, host)
+	ok = p.processHTTPProxyTCPProxy(incValidCond, dest, visited, host, ipFilter)
 	return ok
 }
 
@@ -775,43 +2272,101 @@ func (p *HTTPProxyProcessor) processHTTPProxyTCPProxy(validCond *contour_api_v1.
 // invalid HTTPProxy objects are excluded from the slice and their status
 // updated accordingly.
 func (p *HTTPProxyProcessor) validHTTPProxies() []*contour_api_v1.HTTPProxy {
-	// ensure that a given fqdn is only referenced in a single HTTPProxy resource
+	// ensure that a given fqdn or alias is only referenced in a single
+	// HTTPProxy resource for each listener
 	var valid []*contour_api_v1.HTTPProxy
-	fqdnHTTPProxies := make(map[string][]*contour_api_v1.HTTPProxy)
+	fqdnHTTPProxies := make(map[ListenerName][]*contour_api_v1.HTTPProxy)
 	for _, proxy := range p.source.httpproxies {
 		if proxy.Spec.VirtualHost == nil {
 			valid = append(valid, proxy)
 			continue
 		}
-		fqdn := strings.ToLower(proxy.Spec.VirtualHost.Fqdn)
-		fqdnHTTPProxies[fqdn] = append(fqdnHTTPProxies[fqdn], proxy)
+		// A TCP or UDP listener proxies all of its traffic to
+		// a single HTTPProxy, whatever its fqdn.
+		if isProxyListener(p.AdditionalListeners[proxy.Spec.VirtualHost.Listener]) {
+			key := ListenerName{ListenerName: proxy.Spec.VirtualHost.Listener}
+			fqdnHTTPProxies[key] = append(fqdnHTTPProxies[key], proxy)
+			continue
+		}
+		for _, fqdn := range proxyHosts(proxy) {
+			key := ListenerName{Name: fqdn, ListenerName: proxy.Spec.VirtualHost.Listener}
+			fqdnHTTPProxies[key] = append(fqdnHTTPProxies[key], proxy)
+		}
 	}
 
-	for fqdn, proxies := range fqdnHTTPProxies {
-		switch len(proxies) {
-		case 1:
-			valid = append(valid, proxies[0])
-		default:
-			// multiple proxies use the same fqdn. mark them as invalid.
-			var conflicting []string
-			for _, proxy := range proxies {
-				conflicting = append(conflicting, proxy.Namespace+"/"+proxy.Name)
-			}
-			sort.Strings(conflicting) // sort for test stability
-			msg := fmt.Sprintf("fqdn %q is used in multiple HTTPProxies: %s", fqdn, strings.Join(conflicting, ", "))
-			for _, proxy := range proxies {
-				pa, commit := p.dag.StatusCache.ProxyAccessor(proxy)
-				pa.Vhost = fqdn
-				pa.ConditionFor(status.ValidCondition).AddError(contour_api_v1.ConditionTypeVirtualHostError,
-					"DuplicateVhost",
-					msg)
-				commit()
-			}
+	var keys []ListenerName
+	for key := range fqdnHTTPProxies {
+		keys = append(keys, key)
+	}
+	// sort for test stability
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return keys[i].ListenerName < keys[j].ListenerName
+	})
+
+	duplicate := make(map[*contour_api_v1.HTTPProxy]bool)
+	for _, key := range keys {
+		proxies := fqdnHTTPProxies[key]
+		if len(proxies) == 1 {
+			continue
+		}
+
+		// multiple proxies use the same fqdn, TCP or UDP listener. mark them as invalid.
+		var conflicting []string
+		for _, proxy := range proxies {
+			conflicting = append(conflicting, proxy.Namespace+"/"+proxy.Name)
+		}
+		sort.Strings(conflicting) // sort for test stability
+		reason := "DuplicateVhost"
+		msg := fmt.Sprintf("fqdn %q is used in multiple HTTPProxies: %s", key.Name, strings.Join(conflicting, ", "))
+		if protocol := p.AdditionalListeners[key.ListenerName]; isProxyListener(protocol) {
+			reason = "DuplicateListener"
+			msg = fmt.Sprintf("%s listener %q is used in multiple HTTPProxies: %s",
+				strings.ToUpper(string(protocol)), key.ListenerName, strings.Join(conflicting, ", "))
+		}
+		for _, proxy := range proxies {
+			duplicate[proxy] = true
+
+			pa, commit := p.dag.StatusCache.ProxyAccessor(proxy)
+			pa.Vhost = strings.ToLower(proxy.Spec.VirtualHost.Fqdn)
+			pa.ConditionFor(status.ValidCondition).AddError(contour_api_v1.ConditionTypeVirtualHostError,
+				reason,
+				msg)
+			commit()
+		}
+	}
+
+	for _, proxy := range p.source.httpproxies {
+		if proxy.Spec.VirtualHost != nil && !duplicate[proxy] {
+			valid = append(valid, proxy)
 		}
 	}
 	return valid
 }
 
+// isProxyListener returns true if a listener of the protocol
+// proxies all of its traffic to a single HTTPProxy.
+func isProxyListener(protocol config.ListenerProtocol) bool {
+	return protocol == config.TCPListenerProtocol || protocol == config.UDPListenerProtocol
+}
+
+// proxyHosts returns the distinct, lower cased fqdn and aliases
+// of the root HTTPProxy.
+func proxyHosts(proxy *contour_api_v1.HTTPProxy) []string {
+	seen := map[string]bool{}
+	var hosts []string
+	for _, host := range append([]string{proxy.Spec.VirtualHost.Fqdn}, proxy.Spec.VirtualHost.Aliases...) {
+		host = strings.ToLower(host)
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
 // rootAllowed returns true if the HTTPProxy lives in a permitted root namespace.
 func (p *HTTPProxyProcessor) rootAllowed(namespace string) bool {
 	if len(p.source.RootNamespaces) == 0 {
@@ -236,20 +236,27 @@ func route(ingress *networking_v1.Ingress, host string, path string, pathType ne
 	// labels. This match ignores a port in the hostname in case it is present.
 	if strings.HasPrefix(host, "*.") {
 		r.HeaderMatchConditions = []HeaderMatchCondition{
-			{
-				// Internally Envoy uses the HTTP/2 ":authority" header in
-				// place of the HTTP/1 "host" header.
-				// See: https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/route/v3/route_components.proto#config-route-v3-headermatcher
-				Name:      ":authority",
-				MatchType: HeaderMatchTypeRegex,
-				Value:     singleDNSLabelWildcardRegex + regexp.QuoteMeta(host[1:]),
-			},
+			wildcardDomainHeaderMatch(host),
 		}
 	}
 
 	return r, nil
 }
 
+// wildcardDomainHeaderMatch returns a HeaderMatchCondition that only
+// matches hosts that are a single DNS label under the supplied wildcard
+// host (e.g. "*.example.com").
+func wildcardDomainHeaderMatch(host string) HeaderMatchCondition {
+	return HeaderMatchCondition{
+		// Internally Envoy uses the HTTP/2 ":authority" header in
+		// place of the HTTP/1 "host" header.
+		// See: https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/route/v3/route_components.proto#config-route-v3-headermatcher
+		Name:      ":authority",
+		MatchType: HeaderMatchTypeRegex,
+		Value:     singleDNSLabelWildcardRegex + regexp.QuoteMeta(host[1:]),
+	}
+}
+
 // rulesFromSpec merges the IngressSpec's Rules with a synthetic
 // rule representing the default backend.
 // Prepend the default backend so it can be overridden by later rules.
@@ -16,6 +16,7 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"regexp"
 	"strings"
@@ -27,6 +28,7 @@ import (
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/annotation"
 	"github.com/projectcontour/contour/internal/timeout"
+	"github.com/projectcontour/contour/pkg/config"
 	"github.com/sirupsen/logrus"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -643,3 +645,279 @@ func loadBalancerRequestHashPolicies(lbp *contour_api_v1.LoadBalancerPolicy, val
 	}
 
 }
+
+// ipFilterPolicy parses the address ranges of an IP filter policy. It
+// returns nil if there is no policy. A policy without any ranges is
+// rejected, since it would not restrict any clients.
+func ipFilterPolicy(in *contour_api_v1.IPFilterPolicy) (*IPFilterPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if len(in.Allow) == 0 && len(in.Deny) == 0 {
+		return nil, errors.New("at least one allow or deny entry must be specified")
+	}
+
+	allow, err := parseCIDRs(in.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allow entry: %w", err)
+	}
+
+	deny, err := parseCIDRs(in.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deny entry: %w", err)
+	}
+
+	return &IPFilterPolicy{
+		Allow: allow,
+		Deny:  deny,
+	}, nil
+}
+
+// narrowIPFilterPolicy returns a policy that only permits the clients
+// that are permitted by both the outer policy, which is inherited from
+// the virtual host, and the inner policy. Either policy may be nil. It
+// returns an error if the allowed ranges of the policies do not overlap,
+// since no client would be permitted.
+func narrowIPFilterPolicy(outer, inner *IPFilterPolicy) (*IPFilterPolicy, error) {
+	if outer == nil {
+		return inner, nil
+	}
+	if inner == nil {
+		return outer, nil
+	}
+
+	narrowed := &IPFilterPolicy{}
+	narrowed.Deny = append(narrowed.Deny, outer.Deny...)
+	narrowed.Deny = append(narrowed.Deny, inner.Deny...)
+
+	switch {
+	case len(outer.Allow) == 0:
+		narrowed.Allow = inner.Allow
+	case len(inner.Allow) == 0:
+		narrowed.Allow = outer.Allow
+	default:
+		// CIDR ranges either nest or are disjoint, so the
+		// intersection of two overlapping ranges is the
+		// narrower of them.
+		seen := map[string]bool{}
+		for _, o := range outer.Allow {
+			for _, i := range inner.Allow {
+				if !o.Contains(i.IP) && !i.Contains(o.IP) {
+					continue
+				}
+
+				cidr := o
+				outerLen, _ := o.Mask.Size()
+				innerLen, _ := i.Mask.Size()
+				if innerLen > outerLen {
+					cidr = i
+				}
+
+				if !seen[cidr.String()] {
+					seen[cidr.String()] = true
+					narrowed.Allow = append(narrowed.Allow, cidr)
+				}
+			}
+		}
+
+		if len(narrowed.Allow) == 0 {
+			return nil, errors.New("no allow entry overlaps the allowed ranges of the virtual host")
+		}
+	}
+
+	return narrowed, nil
+}
+
+// parseCIDRs parses a list of CIDR ranges. An IP address without a
+// prefix length is treated as a range that contains only that address.
+func parseCIDRs(in []string) ([]*net.IPNet, error) {
+	var out []*net.IPNet
+
+	for _, s := range in {
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", s)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			out = append(out, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, cidr, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", s)
+		}
+		out = append(out, cidr)
+	}
+
+	return out, nil
+}
+
+// authorizationServerRequest returns the request body settings
+// and the allowed headers of an authorization server.
+func authorizationServerRequest(auth *contour_api_v1.AuthorizationServer) (*AuthorizationServerBufferSettings, []string, error) {
+	var body *AuthorizationServerBufferSettings
+	if auth.WithRequestBody != nil {
+		body = &AuthorizationServerBufferSettings{
+			MaxRequestBytes:     auth.WithRequestBody.MaxRequestBytes,
+			AllowPartialMessage: auth.WithRequestBody.AllowPartialMessage,
+			PackAsBytes:         auth.WithRequestBody.PackAsBytes,
+		}
+
+		// Follow the CRD default if it was not applied.
+		if body.MaxRequestBytes == 0 {
+			body.MaxRequestBytes = 1024
+		}
+	}
+
+	headers, err := headerNames("allowed", auth.AllowedHeaders)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, headers, nil
+}
+
+// headerNames validates a list of header names, and returns them
+// lower-cased and without duplicates, since that is how Envoy
+// matches them.
+func headerNames(kind string, names []string) ([]string, error) {
+	var headers []string
+	seen := sets.NewString()
+	for _, h := range names {
+		if msgs := validation.IsHTTPHeaderName(h); len(msgs) != 0 {
+			return nil, fmt.Errorf("invalid %s header %q: %v", kind, h, msgs)
+		}
+
+		name := strings.ToLower(h)
+		if seen.Has(name) {
+			continue
+		}
+		seen.Insert(name)
+		headers = append(headers, name)
+	}
+
+	return headers, nil
+}
+
+// forwardClientCertificate returns the XFCC header configuration.
+func forwardClientCertificate(in *contour_api_v1.ForwardClientCertificate) (*ForwardClientCertificate, error) {
+	out := &ForwardClientCertificate{
+		Mode:    in.Mode,
+		Subject: in.Subject,
+		URI:     in.URI,
+		DNS:     in.DNS,
+		Cert:    in.Cert,
+		Chain:   in.Chain,
+	}
+
+	switch out.Mode {
+	case "":
+		out.Mode = ForwardClientCertificateSanitizeSet
+	case ForwardClientCertificateAppend, ForwardClientCertificateSanitizeSet:
+	case ForwardClientCertificateSanitize, ForwardClientCertificateForward:
+		if out.Subject || out.URI || out.DNS || out.Cert || out.Chain {
+			return nil, fmt.Errorf("client certificate details cannot be added in %q mode", out.Mode)
+		}
+	default:
+		return nil, fmt.Errorf("invalid forwardClientCertificate mode %q", out.Mode)
+	}
+
+	return out, nil
+}
+
+// hstsHeader is the response header that carries the HSTS policy.
+const hstsHeader = "Strict-Transport-Security"
+
+// hstsPolicy returns the HTTP Strict Transport Security policy.
+func hstsPolicy(in *contour_api_v1.HSTSPolicy) (*HSTSPolicy, error) {
+	maxAge, err := time.ParseDuration(in.MaxAge)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maxAge %q: %v", in.MaxAge, err)
+	}
+	if maxAge < 0 {
+		return nil, fmt.Errorf("invalid maxAge %q: must not be negative", in.MaxAge)
+	}
+	if in.Preload && (!in.IncludeSubDomains || maxAge < config.HSTSPreloadMinMaxAge) {
+		return nil, fmt.Errorf("preload requires includeSubDomains and a maxAge of at least %s", config.HSTSPreloadMinMaxAge)
+	}
+
+	return &HSTSPolicy{
+		MaxAge:            maxAge,
+		IncludeSubDomains: in.IncludeSubDomains,
+		Preload:           in.Preload,
+	}, nil
+}
+
+// setsHeader returns true if the headers policy sets or removes
+// the named header.
+func setsHeader(policy *contour_api_v1.HeadersPolicy, name string) bool {
+	if policy == nil {
+		return false
+	}
+
+	for _, entry := range policy.Set {
+		if strings.EqualFold(entry.Name, name) {
+			return true
+		}
+	}
+	for _, entry := range policy.Remove {
+		if strings.EqualFold(entry, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// csrfPolicy returns the CSRF protection policy.
+func csrfPolicy(in *contour_api_v1.CSRFPolicy) (*CSRFPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	out := &CSRFPolicy{
+		EnforcementPercentage: 100,
+		Shadow:                in.Shadow,
+	}
+	if in.Shadow {
+		out.EnforcementPercentage = 0
+	}
+	if in.EnforcementPercentage != nil {
+		if *in.EnforcementPercentage > 100 {
+			return nil, fmt.Errorf("enforcementPercentage %d is greater than 100", *in.EnforcementPercentage)
+		}
+		out.EnforcementPercentage = *in.EnforcementPercentage
+	}
+
+	for i, origin := range in.AdditionalOrigins {
+		var found []OriginMatch
+
+		if origin.Exact != "" {
+			found = append(found, OriginMatch{MatchType: OriginMatchTypeExact, Value: origin.Exact})
+		}
+		if origin.Suffix != "" {
+			found = append(found, OriginMatch{MatchType: OriginMatchTypeSuffix, Value: origin.Suffix})
+		}
+		if origin.Regex != "" {
+			if err := ValidateRegex(origin.Regex); err != nil {
+				return nil, fmt.Errorf("additional origin %d: invalid regex %q: %s", i, origin.Regex, err)
+			}
+			found = append(found, OriginMatch{MatchType: OriginMatchTypeRegex, Value: origin.Regex})
+		}
+
+		if len(found) != 1 {
+			return nil, fmt.Errorf("additional origin %d: exactly one of exact, suffix or regex must be specified", i)
+		}
+
+		out.AdditionalOrigins = append(out.AdditionalOrigins, found[0])
+	}
+
+	return out, nil
+}
@@ -15,6 +15,7 @@ package dag
 
 import (
 	"io/ioutil"
+	"net"
 	"testing"
 	"time"
 
@@ -849,3 +850,373 @@ func TestRateLimitPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestIPFilterPolicy(t *testing.T) {
+	cidr := func(s string) *net.IPNet {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	tests := map[string]struct {
+		in      *contour_api_v1.IPFilterPolicy
+		want    *IPFilterPolicy
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"empty policy": {
+			in:      &contour_api_v1.IPFilterPolicy{},
+			wantErr: "at least one allow or deny entry must be specified",
+		},
+		"allow and deny": {
+			in: &contour_api_v1.IPFilterPolicy{
+				Allow: []string{"10.0.0.0/8", "2001:db8::/32"},
+				Deny:  []string{"10.8.0.0/16"},
+			},
+			want: &IPFilterPolicy{
+				Allow: []*net.IPNet{cidr("10.0.0.0/8"), cidr("2001:db8::/32")},
+				Deny:  []*net.IPNet{cidr("10.8.0.0/16")},
+			},
+		},
+		"addresses": {
+			in: &contour_api_v1.IPFilterPolicy{
+				Deny: []string{"192.168.1.1", "2001:db8::1"},
+			},
+			want: &IPFilterPolicy{
+				Deny: []*net.IPNet{cidr("192.168.1.1/32"), cidr("2001:db8::1/128")},
+			},
+		},
+		"invalid allow": {
+			in: &contour_api_v1.IPFilterPolicy{
+				Allow: []string{"10.0.0.0/33"},
+			},
+			wantErr: `invalid allow entry: "10.0.0.0/33" is not an IP address or CIDR range`,
+		},
+		"invalid deny": {
+			in: &contour_api_v1.IPFilterPolicy{
+				Deny: []string{"office"},
+			},
+			wantErr: `invalid deny entry: "office" is not an IP address or CIDR range`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ipFilterPolicy(tc.in)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNarrowIPFilterPolicy(t *testing.T) {
+	cidrs := func(in ...string) []*net.IPNet {
+		var out []*net.IPNet
+		for _, s := range in {
+			_, n, err := net.ParseCIDR(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, n)
+		}
+		return out
+	}
+
+	tests := map[string]struct {
+		outer   *IPFilterPolicy
+		inner   *IPFilterPolicy
+		want    *IPFilterPolicy
+		wantErr string
+	}{
+		"no policies": {},
+		"outer only": {
+			outer: &IPFilterPolicy{Deny: cidrs("10.0.0.0/8")},
+			want:  &IPFilterPolicy{Deny: cidrs("10.0.0.0/8")},
+		},
+		"inner only": {
+			inner: &IPFilterPolicy{Allow: cidrs("10.0.0.0/8")},
+			want:  &IPFilterPolicy{Allow: cidrs("10.0.0.0/8")},
+		},
+		"deny ranges are combined": {
+			outer: &IPFilterPolicy{Deny: cidrs("10.0.0.0/8")},
+			inner: &IPFilterPolicy{Allow: cidrs("192.168.0.0/16"), Deny: cidrs("192.168.1.0/24")},
+			want: &IPFilterPolicy{
+				Allow: cidrs("192.168.0.0/16"),
+				Deny:  cidrs("10.0.0.0/8", "192.168.1.0/24"),
+			},
+		},
+		"outer allow ranges are kept": {
+			outer: &IPFilterPolicy{Allow: cidrs("10.0.0.0/8")},
+			inner: &IPFilterPolicy{Deny: cidrs("10.1.0.0/16")},
+			want: &IPFilterPolicy{
+				Allow: cidrs("10.0.0.0/8"),
+				Deny:  cidrs("10.1.0.0/16"),
+			},
+		},
+		"inner cannot widen allow ranges": {
+			outer: &IPFilterPolicy{Allow: cidrs("10.1.0.0/16", "2001:db8::/32")},
+			inner: &IPFilterPolicy{Allow: cidrs("10.0.0.0/8", "192.168.0.0/16", "2001:db8:1::/48")},
+			want: &IPFilterPolicy{
+				Allow: cidrs("10.1.0.0/16", "2001:db8:1::/48"),
+			},
+		},
+		"disjoint allow ranges": {
+			outer:   &IPFilterPolicy{Allow: cidrs("10.0.0.0/8")},
+			inner:   &IPFilterPolicy{Allow: cidrs("192.168.0.0/16")},
+			wantErr: "no allow entry overlaps the allowed ranges of the virtual host",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := narrowIPFilterPolicy(tc.outer, tc.inner)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCSRFPolicy(t *testing.T) {
+	percentage := func(v uint32) *uint32 {
+		return &v
+	}
+
+	tests := map[string]struct {
+		in      *contour_api_v1.CSRFPolicy
+		want    *CSRFPolicy
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"empty policy": {
+			in: &contour_api_v1.CSRFPolicy{},
+			want: &CSRFPolicy{
+				EnforcementPercentage: 100,
+			},
+		},
+		"shadow mode": {
+			in: &contour_api_v1.CSRFPolicy{
+				Shadow: true,
+			},
+			want: &CSRFPolicy{
+				EnforcementPercentage: 0,
+				Shadow:                true,
+			},
+		},
+		"partial enforcement with shadow": {
+			in: &contour_api_v1.CSRFPolicy{
+				EnforcementPercentage: percentage(25),
+				Shadow:                true,
+			},
+			want: &CSRFPolicy{
+				EnforcementPercentage: 25,
+				Shadow:                true,
+			},
+		},
+		"additional origins": {
+			in: &contour_api_v1.CSRFPolicy{
+				AdditionalOrigins: []contour_api_v1.CSRFOriginMatch{
+					{Exact: "www.example.com"},
+					{Suffix: ".example.net"},
+					{Regex: `[a-z]+\.example\.org`},
+				},
+			},
+			want: &CSRFPolicy{
+				EnforcementPercentage: 100,
+				AdditionalOrigins: []OriginMatch{
+					{MatchType: OriginMatchTypeExact, Value: "www.example.com"},
+					{MatchType: OriginMatchTypeSuffix, Value: ".example.net"},
+					{MatchType: OriginMatchTypeRegex, Value: `[a-z]+\.example\.org`},
+				},
+			},
+		},
+		"enforcement percentage out of range": {
+			in: &contour_api_v1.CSRFPolicy{
+				EnforcementPercentage: percentage(101),
+			},
+			wantErr: "enforcementPercentage 101 is greater than 100",
+		},
+		"empty origin": {
+			in: &contour_api_v1.CSRFPolicy{
+				AdditionalOrigins: []contour_api_v1.CSRFOriginMatch{{}},
+			},
+			wantErr: "additional origin 0: exactly one of exact, suffix or regex must be specified",
+		},
+		"multiple origin matches": {
+			in: &contour_api_v1.CSRFPolicy{
+				AdditionalOrigins: []contour_api_v1.CSRFOriginMatch{
+					{Exact: "www.example.com"},
+					{Exact: "www.example.com", Suffix: ".example.com"},
+				},
+			},
+			wantErr: "additional origin 1: exactly one of exact, suffix or regex must be specified",
+		},
+		"invalid regex": {
+			in: &contour_api_v1.CSRFPolicy{
+				AdditionalOrigins: []contour_api_v1.CSRFOriginMatch{
+					{Regex: "^(example"},
+				},
+			},
+			wantErr: "additional origin 0: invalid regex \"^(example\": error parsing regexp: missing closing ): `^(example`",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := csrfPolicy(tc.in)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAuthorizationServerRequest(t *testing.T) {
+	tests := map[string]struct {
+		in          *contour_api_v1.AuthorizationServer
+		wantBody    *AuthorizationServerBufferSettings
+		wantHeaders []string
+		wantErr     string
+	}{
+		"no request settings": {
+			in: &contour_api_v1.AuthorizationServer{},
+		},
+		"request body": {
+			in: &contour_api_v1.AuthorizationServer{
+				WithRequestBody: &contour_api_v1.AuthorizationServerBufferSettings{
+					MaxRequestBytes:     4096,
+					AllowPartialMessage: true,
+					PackAsBytes:         true,
+				},
+			},
+			wantBody: &AuthorizationServerBufferSettings{
+				MaxRequestBytes:     4096,
+				AllowPartialMessage: true,
+				PackAsBytes:         true,
+			},
+		},
+		"default max request bytes": {
+			in: &contour_api_v1.AuthorizationServer{
+				WithRequestBody: &contour_api_v1.AuthorizationServerBufferSettings{},
+			},
+			wantBody: &AuthorizationServerBufferSettings{
+				MaxRequestBytes: 1024,
+			},
+		},
+		"allowed headers": {
+			in: &contour_api_v1.AuthorizationServer{
+				AllowedHeaders: []string{"X-Signature", "Content-Type", "x-signature"},
+			},
+			wantHeaders: []string{"x-signature", "content-type"},
+		},
+		"invalid allowed header": {
+			in: &contour_api_v1.AuthorizationServer{
+				AllowedHeaders: []string{"x signature"},
+			},
+			wantErr: `invalid allowed header "x signature": [a valid HTTP header must consist of alphanumeric characters or '-' (e.g. 'X-Header-Name', regex used for validation is '[-A-Za-z0-9]+')]`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			body, headers, err := authorizationServerRequest(tc.in)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantBody, body)
+				assert.Equal(t, tc.wantHeaders, headers)
+			}
+		})
+	}
+}
+
+func TestForwardClientCertificate(t *testing.T) {
+	tests := map[string]struct {
+		in      *contour_api_v1.ForwardClientCertificate
+		want    *ForwardClientCertificate
+		wantErr string
+	}{
+		"default mode": {
+			in: &contour_api_v1.ForwardClientCertificate{
+				Subject: true,
+				Cert:    true,
+			},
+			want: &ForwardClientCertificate{
+				Mode:    ForwardClientCertificateSanitizeSet,
+				Subject: true,
+				Cert:    true,
+			},
+		},
+		"append": {
+			in: &contour_api_v1.ForwardClientCertificate{
+				Mode:  "Append",
+				URI:   true,
+				DNS:   true,
+				Chain: true,
+			},
+			want: &ForwardClientCertificate{
+				Mode:  ForwardClientCertificateAppend,
+				URI:   true,
+				DNS:   true,
+				Chain: true,
+			},
+		},
+		"forward": {
+			in: &contour_api_v1.ForwardClientCertificate{
+				Mode: "Forward",
+			},
+			want: &ForwardClientCertificate{
+				Mode: ForwardClientCertificateForward,
+			},
+		},
+		"sanitize with details": {
+			in: &contour_api_v1.ForwardClientCertificate{
+				Mode:    "Sanitize",
+				Subject: true,
+			},
+			wantErr: `client certificate details cannot be added in "Sanitize" mode`,
+		},
+		"invalid mode": {
+			in: &contour_api_v1.ForwardClientCertificate{
+				Mode: "Replace",
+			},
+			wantErr: `invalid forwardClientCertificate mode "Replace"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := forwardClientCertificate(tc.in)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
@@ -15,22 +15,51 @@ package dag
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1" // nolint:gosec
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ocsp"
 	v1 "k8s.io/api/core/v1"
 )
 
 // CACertificateKey is the key name for accessing TLS CA certificate bundles in Kubernetes Secrets.
 const CACertificateKey = "ca.crt"
 
+// JWKSKey is the key name for accessing JSON Web Key Sets in Kubernetes Secrets and ConfigMaps.
+const JWKSKey = "jwks"
+
+// BasicAuthKey is the key name for accessing htpasswd credentials in Kubernetes Secrets.
+const BasicAuthKey = "auth"
+
+// CRLKey is the key name for accessing certificate revocation lists in Kubernetes Secrets and ConfigMaps.
+const CRLKey = "crl.pem"
+
+// OCSPStapleKey is the key name for accessing DER encoded OCSP responses in Kubernetes TLS Secrets.
+const OCSPStapleKey = "tls.ocsp-staple"
+
+// SessionTicketKeysKey is the key name for accessing TLS session ticket keys in Kubernetes Secrets.
+const SessionTicketKeysKey = "session-ticket-keys"
+
+// SessionTicketKeyLength is the length of each TLS session ticket key.
+const SessionTicketKeyLength = 80
+
 // isValidSecret returns true if the secret is interesting and well
 // formed. TLS certificate/key pairs must be secrets of type
 // "kubernetes.io/tls". Certificate bundles may be "kubernetes.io/tls"
-// or generic (type "Opaque" or "") secrets.
+// or generic (type "Opaque" or "") secrets. JSON Web Key Sets,
+// htpasswd credentials, certificate revocation lists and session
+// ticket keys must be generic secrets.
 func isValidSecret(secret *v1.Secret) (bool, error) {
 	switch secret.Type {
 	// We will accept TLS secrets that also have the 'ca.crt' payload.
@@ -53,7 +82,14 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 			return false, fmt.Errorf("invalid TLS private key: %v", err)
 		}
 
-	// Generic secrets may have a 'ca.crt' only.
+		if staple := secret.Data[OCSPStapleKey]; len(staple) > 0 {
+			if _, err := parseOCSPStaple(staple, secret.Data[v1.TLSCertKey]); err != nil {
+				return false, fmt.Errorf("invalid OCSP staple: %v", err)
+			}
+		}
+
+	// Generic secrets may have a 'ca.crt', a 'jwks', an 'auth',
+	// a 'crl.pem' or a 'session-ticket-keys' only.
 	case v1.SecretTypeOpaque, "":
 		if _, ok := secret.Data[v1.TLSCertKey]; ok {
 			return false, nil
@@ -63,10 +99,30 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 			return false, nil
 		}
 
-		if data := secret.Data[CACertificateKey]; len(data) == 0 {
+		found := false
+		for _, key := range []string{CACertificateKey, JWKSKey, BasicAuthKey, CRLKey, SessionTicketKeysKey} {
+			if len(secret.Data[key]) > 0 {
+				found = true
+			}
+		}
+		if !found {
 			return false, nil
 		}
 
+		if data := secret.Data[JWKSKey]; len(data) > 0 && !json.Valid(data) {
+			return false, errors.New("invalid JWKS: not a JSON document")
+		}
+
+		if data := secret.Data[CRLKey]; len(data) > 0 {
+			if err := validateCRL(data); err != nil {
+				return false, fmt.Errorf("invalid certificate revocation list: %v", err)
+			}
+		}
+
+		if data := secret.Data[SessionTicketKeysKey]; len(data)%SessionTicketKeyLength != 0 {
+			return false, fmt.Errorf("invalid session ticket keys: length %d is not a multiple of %d", len(data), SessionTicketKeyLength)
+		}
+
 	default:
 		return false, nil
 
@@ -85,6 +141,90 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 	return true, nil
 }
 
+// parseHtpasswd parses htpasswd formatted credentials, returning a
+// map of user names to their password hashes. Users whose passwords
+// are not hashed with bcrypt, apr1 or SHA1 cannot be verified, so
+// they are returned separately, along with the format of their hashes.
+func parseHtpasswd(data []byte) (map[string]string, []string, error) {
+	users := map[string]string{}
+	var unsupported []string
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		colon := strings.Index(line, ":")
+		if colon < 1 {
+			return nil, nil, fmt.Errorf("line %d: expected \"user:password\"", i+1)
+		}
+
+		user, password := line[:colon], line[colon+1:]
+		format := htpasswdHashFormat(password)
+		switch format {
+		case "SHA1":
+			digest, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(password, "{SHA}"))
+			if err != nil || len(digest) != sha1.Size {
+				return nil, nil, fmt.Errorf("line %d: invalid SHA1 password hash for user %q", i+1, user)
+			}
+		case "apr1":
+			if !validAPR1(password) {
+				return nil, nil, fmt.Errorf("line %d: invalid apr1 password hash for user %q", i+1, user)
+			}
+		case "bcrypt":
+			if _, err := bcrypt.Cost([]byte(password)); err != nil {
+				return nil, nil, fmt.Errorf("line %d: invalid bcrypt password hash for user %q: %v", i+1, user, err)
+			}
+		default:
+			unsupported = append(unsupported, fmt.Sprintf("%s (%s)", user, format))
+			continue
+		}
+
+		users[user] = password
+	}
+
+	return users, unsupported, nil
+}
+
+// htpasswdHashFormat returns the name of the format of an htpasswd
+// password hash.
+func htpasswdHashFormat(password string) string {
+	switch {
+	case strings.HasPrefix(password, "{SHA}"):
+		return "SHA1"
+	case strings.HasPrefix(password, "$apr1$"):
+		return "apr1"
+	case strings.HasPrefix(password, "$2a$"), strings.HasPrefix(password, "$2b$"), strings.HasPrefix(password, "$2y$"):
+		return "bcrypt"
+	case strings.HasPrefix(password, "$"):
+		return "crypt"
+	default:
+		return "crypt or plain text"
+	}
+}
+
+// validAPR1 returns true if password has the form of an apr1 hash,
+// "$apr1$<salt>$<hash>", with a salt of up to 8 characters and a
+// 22 character hash.
+func validAPR1(password string) bool {
+	parts := strings.Split(strings.TrimPrefix(password, "$apr1$"), "$")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[0]) > 8 || len(parts[1]) != 22 {
+		return false
+	}
+
+	for _, c := range parts[1] {
+		if !strings.ContainsRune(apr1Alphabet, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apr1Alphabet is the alphabet that apr1 hashes are encoded with.
+const apr1Alphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
 // containsPEMHeader returns true if the given slice contains a string
 // that looks like a PEM header block. The problem is that pem.Decode
 // does not give us a way to distinguish between a missing PEM block
@@ -145,6 +285,114 @@ func validateCertificate(data []byte) error {
 	return nil
 }
 
+// validateCRL returns an error unless data holds one or more PEM
+// encoded certificate revocation lists.
+func validateCRL(data []byte) error {
+	var exists bool
+
+	for containsPEMHeader(data) {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return errors.New("failed to parse PEM block")
+		}
+		if block.Type != "X509 CRL" {
+			return fmt.Errorf("unexpected block type '%s'", block.Type)
+		}
+		if _, err := x509.ParseDERCRL(block.Bytes); err != nil {
+			return err
+		}
+
+		exists = true
+	}
+
+	if !exists {
+		return errors.New("failed to locate certificate revocation list")
+	}
+
+	return nil
+}
+
+// Certificate returns the leaf certificate of the secret's TLS
+// certificate chain.
+func (s *Secret) Certificate() (*x509.Certificate, error) {
+	block, _ := pem.Decode(s.Cert())
+	if block == nil {
+		return nil, errors.New("failed to parse PEM block")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// parseOCSPStaple parses a DER encoded OCSP response and checks that
+// it is for the leaf certificate of the PEM encoded certificate chain.
+// The signature of the response is not checked.
+func parseOCSPStaple(staple []byte, certChain []byte) (*ocsp.Response, error) {
+	block, _ := pem.Decode(certChain)
+	if block == nil {
+		return nil, errors.New("failed to parse certificate PEM block")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ocsp.ParseResponse(staple, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+		return nil, errors.New("response does not match the certificate")
+	}
+
+	if resp.ThisUpdate.After(time.Now()) {
+		return nil, fmt.Errorf("response is not valid until %s", resp.ThisUpdate.Format(time.RFC3339))
+	}
+
+	return resp, nil
+}
+
+// ocspStapleExpired returns true if the secret's OCSP response has
+// expired at the given time. A response without a next update time
+// never expires.
+func ocspStapleExpired(secret *Secret, now time.Time) (bool, error) {
+	resp, err := parseOCSPStaple(secret.OCSPStaple(), secret.Cert())
+	if err != nil {
+		return false, err
+	}
+
+	return !resp.NextUpdate.IsZero() && resp.NextUpdate.Before(now), nil
+}
+
+// certificateKeyType returns the key type of the certificate of a
+// TLS secret, either "RSA" or "ECDSA". Envoy only supports ECDSA
+// certificates that use the P-256 curve.
+func certificateKeyType(secret *Secret) (string, error) {
+	block, _ := pem.Decode(secret.Data()[v1.TLSCertKey])
+	if block == nil {
+		return "", errors.New("failed to parse PEM block")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return "", fmt.Errorf("unsupported ECDSA curve %s", key.Curve.Params().Name)
+		}
+		return "ECDSA", nil
+	default:
+		return "", fmt.Errorf("unsupported public key algorithm %s", cert.PublicKeyAlgorithm)
+	}
+}
+
 func hasCommonName(c *x509.Certificate) bool {
 	return strings.TrimSpace(c.Subject.CommonName) != ""
 }
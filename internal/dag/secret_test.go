@@ -14,9 +14,17 @@
 package dag
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/projectcontour/contour/internal/fixture"
 	"github.com/stretchr/testify/assert"
@@ -99,3 +107,229 @@ func caBundleData(cert ...string) map[string][]byte {
 		CACertificateKey: []byte(data),
 	}
 }
+
+func TestParseHtpasswd(t *testing.T) {
+	tests := map[string]struct {
+		data        string
+		users       map[string]string
+		unsupported []string
+		err         error
+	}{
+		"SHA1 users": {
+			data: "# comment\nalice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n\nbob:{SHA}qUqP5cyxm6YcTAhz05Hph5gvu9M=\n",
+			users: map[string]string{
+				"alice": "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=",
+				"bob":   "{SHA}qUqP5cyxm6YcTAhz05Hph5gvu9M=",
+			},
+		},
+		"apr1 and bcrypt users": {
+			data: "alice:$apr1$xxYYzz12$5fkORe2Wk90T9mxxflF9k0\nbob:$2a$05$HXuQCPJC8ldNxLLSahzbeuogzlxVPlKzu.NgFZb.ZpyjaZTftNWqq\n",
+			users: map[string]string{
+				"alice": "$apr1$xxYYzz12$5fkORe2Wk90T9mxxflF9k0",
+				"bob":   "$2a$05$HXuQCPJC8ldNxLLSahzbeuogzlxVPlKzu.NgFZb.ZpyjaZTftNWqq",
+			},
+		},
+		"unsupported hashes": {
+			data: "alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\nbob:$1$saltsalt$qjXMvbEw8oaL.CzflDugX/\ncarol:rl4Jh5ESO1MzA\ndave:password\n",
+			users: map[string]string{
+				"alice": "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=",
+			},
+			unsupported: []string{"bob (crypt)", "carol (crypt or plain text)", "dave (crypt or plain text)"},
+		},
+		"missing password": {
+			data: "alice\n",
+			err:  errors.New(`line 1: expected "user:password"`),
+		},
+		"malformed SHA1 hash": {
+			data: "alice:{SHA}W6ph5Mm5\n",
+			err:  errors.New(`line 1: invalid SHA1 password hash for user "alice"`),
+		},
+		"malformed apr1 hash": {
+			data: "alice:$apr1$xxYYzz12$5fkORe2Wk90T9mxx\n",
+			err:  errors.New(`line 1: invalid apr1 password hash for user "alice"`),
+		},
+		"malformed bcrypt hash": {
+			data: "alice:$2y$05$HXuQCPJC8ldNxLLSahz\n",
+			err:  errors.New(`line 1: invalid bcrypt password hash for user "alice": crypto/bcrypt: hashedSecret too short to be a bcrypted password`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			users, unsupported, err := parseHtpasswd([]byte(tc.data))
+			assert.Equal(t, tc.err, err)
+			if tc.err == nil {
+				assert.Equal(t, tc.users, users)
+			}
+			assert.Equal(t, tc.unsupported, unsupported)
+		})
+	}
+}
+
+func TestCertificateKeyType(t *testing.T) {
+	p384 := func() string {
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	tests := map[string]struct {
+		cert    string
+		want    string
+		wantErr string
+	}{
+		"RSA certificate": {
+			cert: fixture.CERTIFICATE,
+			want: "RSA",
+		},
+		"ECDSA certificate": {
+			cert: fixture.EC_CERTIFICATE,
+			want: "ECDSA",
+		},
+		"ECDSA certificate with unsupported curve": {
+			cert:    p384(),
+			wantErr: "unsupported ECDSA curve P-384",
+		},
+		"not a certificate": {
+			cert:    "not a certificate",
+			wantErr: "failed to parse PEM block",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := certificateKeyType(&Secret{
+				Object: &v1.Secret{
+					Type: v1.SecretTypeTLS,
+					Data: secretdata(tc.cert, ""),
+				},
+			})
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIsValidSecretOCSPStaple(t *testing.T) {
+	now := time.Now()
+
+	// err is a prefix of the expected error.
+	tests := map[string]struct {
+		staple []byte
+		err    string
+	}{
+		"valid staple": {
+			staple: fixture.OCSPStaple(nil, now.Add(-time.Hour), now.Add(time.Hour)),
+		},
+		"expired staple": {
+			staple: fixture.OCSPStaple(nil, now.Add(-2*time.Hour), now.Add(-time.Hour)),
+		},
+		"staple for another certificate": {
+			staple: fixture.OCSPStaple(big.NewInt(1), now.Add(-time.Hour), now.Add(time.Hour)),
+			err:    "invalid OCSP staple: response does not match the certificate",
+		},
+		"staple from the future": {
+			staple: fixture.OCSPStaple(nil, now.Add(time.Hour).Truncate(time.Second), now.Add(2*time.Hour)),
+			err:    "invalid OCSP staple: response is not valid until " + now.Add(time.Hour).Truncate(time.Second).UTC().Format(time.RFC3339),
+		},
+		"not an OCSP response": {
+			staple: []byte("not an OCSP response"),
+			err:    "invalid OCSP staple: asn1: structure error",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := secretdata(fixture.CERTIFICATE, fixture.RSA_PRIVATE_KEY)
+			data[OCSPStapleKey] = tc.staple
+
+			valid, err := isValidSecret(&v1.Secret{
+				Type: v1.SecretTypeTLS,
+				Data: data,
+			})
+
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.True(t, strings.HasPrefix(err.Error(), tc.err), err.Error())
+				}
+				assert.False(t, valid)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, valid)
+			}
+		})
+	}
+}
+
+func TestIsValidSecretSessionTicketKeys(t *testing.T) {
+	tests := map[string]struct {
+		keys  []byte
+		valid bool
+		err   error
+	}{
+		"one key": {
+			keys:  make([]byte, SessionTicketKeyLength),
+			valid: true,
+		},
+		"three keys": {
+			keys:  make([]byte, 3*SessionTicketKeyLength),
+			valid: true,
+		},
+		"no keys": {
+			keys:  []byte{},
+			valid: false,
+		},
+		"truncated key": {
+			keys:  make([]byte, 2*SessionTicketKeyLength-1),
+			valid: false,
+			err:   errors.New("invalid session ticket keys: length 159 is not a multiple of 80"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			valid, err := isValidSecret(&v1.Secret{
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					SessionTicketKeysKey: tc.keys,
+				},
+			})
+
+			assert.Equal(t, tc.valid, valid)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestSecretSessionTicketKeys(t *testing.T) {
+	keys := make([]byte, 2*SessionTicketKeyLength)
+	for i := range keys {
+		keys[i] = byte(i / SessionTicketKeyLength)
+	}
+
+	s := &Secret{
+		Object: &v1.Secret{
+			Data: map[string][]byte{
+				SessionTicketKeysKey: keys,
+			},
+		},
+	}
+
+	assert.Equal(t, [][]byte{keys[:SessionTicketKeyLength], keys[SessionTicketKeyLength:]}, s.SessionTicketKeys())
+}
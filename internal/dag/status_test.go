@@ -15,12 +15,14 @@ package dag
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/fixture"
 	"github.com/projectcontour/contour/internal/status"
+	"github.com/projectcontour/contour/pkg/config"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
@@ -34,9 +36,11 @@ import (
 func TestDAGStatus(t *testing.T) {
 
 	type testcase struct {
-		objs                []interface{}
-		fallbackCertificate *types.NamespacedName
-		want                map[types.NamespacedName]contour_api_v1.DetailedCondition
+		objs                     []interface{}
+		fallbackCertificate      *types.NamespacedName
+		certificateExpiryWarning time.Duration
+		additionalListeners      map[string]config.ListenerProtocol
+		want                     map[types.NamespacedName]contour_api_v1.DetailedCondition
 	}
 
 	run := func(t *testing.T, desc string, tc testcase) {
@@ -53,7 +57,10 @@ func TestDAGStatus(t *testing.T) {
 						FieldLogger: fixture.NewTestLogger(t),
 					},
 					&HTTPProxyProcessor{
-						FallbackCertificate: tc.fallbackCertificate,
+						Clock:                    fixture.Clock,
+						FallbackCertificate:      tc.fallbackCertificate,
+						CertificateExpiryWarning: tc.certificateExpiryWarning,
+						AdditionalListeners:      tc.additionalListeners,
 					},
 					&GatewayAPIProcessor{
 						FieldLogger: fixture.NewTestLogger(t),
@@ -712,7 +719,38 @@ func TestDAGStatus(t *testing.T) {
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
 			{Name: proxyWildCardFQDN.Name, Namespace: proxyWildCardFQDN.Namespace}: fixture.NewValidCondition().
 				WithGeneration(proxyWildCardFQDN.Generation).
-				WithError(contour_api_v1.ConditionTypeVirtualHostError, "WildCardNotAllowed", `Spec.VirtualHost.Fqdn "example.*.com" cannot use wildcards`),
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "WildCardNotAllowed", `Spec.VirtualHost.Fqdn "example.*.com" can only use a wildcard as the first DNS label`),
+		},
+	})
+
+	// proxyWildCardFirstLabelFQDN is valid because the wildcard is the first DNS label
+	proxyWildCardFirstLabelFQDN := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "*.example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/foo",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: "home",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy valid FQDN with wildcard first label", testcase{
+		objs: []interface{}{proxyWildCardFirstLabelFQDN, fixture.ServiceRootsHome},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyWildCardFirstLabelFQDN.Name, Namespace: proxyWildCardFirstLabelFQDN.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyWildCardFirstLabelFQDN.Generation).
+				Valid(),
 		},
 	})
 
@@ -859,6 +897,107 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	proxyInternalReuseExampleCom := proxyValidReuseExampleCom.DeepCopy()
+	proxyInternalReuseExampleCom.Spec.VirtualHost.Listener = "internal"
+
+	run(t, "fqdn reuse on different listeners", testcase{
+		objs: []interface{}{fixture.ServiceRootsKuard, proxyValidExampleCom, proxyInternalReuseExampleCom},
+		additionalListeners: map[string]config.ListenerProtocol{
+			"internal": config.HTTPListenerProtocol,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyValidExampleCom.Name, Namespace: proxyValidExampleCom.Namespace}:                 fixture.NewValidCondition().Valid(),
+			{Name: proxyInternalReuseExampleCom.Name, Namespace: proxyInternalReuseExampleCom.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	proxyAliasReusesExampleCom := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "alias-example",
+			Namespace: "roots",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.org",
+				Aliases: []string{"www.example.org", "Example.com"},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "conflicting proxies due to alias reuse", testcase{
+		objs: []interface{}{proxyValidExampleCom, proxyAliasReusesExampleCom},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyValidExampleCom.Name, Namespace: proxyValidExampleCom.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyValidExampleCom.Generation).
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateVhost", `fqdn "example.com" is used in multiple HTTPProxies: roots/alias-example, roots/example-com`),
+			{Name: proxyAliasReusesExampleCom.Name, Namespace: proxyAliasReusesExampleCom.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyAliasReusesExampleCom.Generation).
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateVhost", `fqdn "example.com" is used in multiple HTTPProxies: roots/alias-example, roots/example-com`),
+		},
+	})
+
+	proxyInvalidAlias := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid-alias",
+			Namespace: "roots",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.org",
+				Aliases: []string{"www.*.example.org"},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy with invalid alias", testcase{
+		objs: []interface{}{proxyInvalidAlias},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidAlias.Name, Namespace: proxyInvalidAlias.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyInvalidAlias.Generation).
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "WildCardNotAllowed", `Spec.VirtualHost.Aliases[0] "www.*.example.org" can only use a wildcard as the first DNS label`),
+		},
+	})
+
+	proxyDuplicateAlias := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "duplicate-alias",
+			Namespace: "roots",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:    "example.org",
+				Aliases: []string{"EXAMPLE.org"},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "proxy with alias duplicating its fqdn", testcase{
+		objs: []interface{}{proxyDuplicateAlias},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyDuplicateAlias.Name, Namespace: proxyDuplicateAlias.Namespace}: fixture.NewValidCondition().
+				WithGeneration(proxyDuplicateAlias.Generation).
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateAlias", `Spec.VirtualHost.Aliases[0] "example.org" is already served by this virtual host`),
+		},
+	})
+
 	proxyRootIncludesRoot := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "root-blog",
@@ -1733,6 +1872,158 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	proxyInvalidIncludeNameAndSelector := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name: "child",
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "child"},
+				},
+			}},
+		},
+	}
+
+	run(t, "httpproxy w/ include name and selector", testcase{
+		objs: []interface{}{proxyInvalidIncludeNameAndSelector, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidIncludeNameAndSelector.Name, Namespace: proxyInvalidIncludeNameAndSelector.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "IncludeNotValid", "include must specify exactly one of name or selector"),
+		},
+	})
+
+	proxyInvalidIncludeSelector := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Selector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      "app",
+						Operator: "Matches",
+					}},
+				},
+			}},
+		},
+	}
+
+	run(t, "httpproxy w/ invalid include selector", testcase{
+		objs: []interface{}{proxyInvalidIncludeSelector, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidIncludeSelector.Name, Namespace: proxyInvalidIncludeSelector.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "IncludeSelectorNotValid", `include selector is invalid: "Matches" is not a valid pod selector operator`),
+		},
+	})
+
+	proxyRootRoutePriority := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name: "child",
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/child",
+				}},
+				PriorityRange: &contour_api_v1.RoutePriorityRange{
+					Min: 0,
+					Max: 10,
+				},
+			}},
+			Routes: []contour_api_v1.Route{{
+				Priority: 5,
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	proxyChildRoutePriority := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "child",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Priority: 5,
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/a",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}, {
+				Priority: 20,
+				Conditions: []contour_api_v1.MatchCondition{{
+					Prefix: "/b",
+				}},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
+	run(t, "httpproxy w/ route priority ties and clamped child priority", testcase{
+		objs: []interface{}{proxyRootRoutePriority, proxyChildRoutePriority, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyRootRoutePriority.Name, Namespace: proxyRootRoutePriority.Namespace}: fixture.NewValidCondition().
+				WithWarning(contour_api_v1.ConditionTypeRouteError, "PriorityTie",
+					"2 routes share priority 5 and are ordered by their match conditions: roots/child, roots/example"),
+			{Name: proxyChildRoutePriority.Name, Namespace: proxyChildRoutePriority.Namespace}: fixture.NewValidCondition().
+				WithWarning(contour_api_v1.ConditionTypeRouteError, "PriorityClamped",
+					"route priority 20 is outside the permitted range [0, 10] and was clamped to 10"),
+		},
+	})
+
+	proxyInvalidPriorityRange := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "roots",
+			Name:      "example",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []contour_api_v1.Include{{
+				Name: "child",
+				PriorityRange: &contour_api_v1.RoutePriorityRange{
+					Min: 10,
+					Max: 0,
+				},
+			}},
+		},
+	}
+
+	run(t, "httpproxy w/ invalid include priority range", testcase{
+		objs: []interface{}{proxyInvalidPriorityRange, proxyChildRoutePriority, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidPriorityRange.Name, Namespace: proxyInvalidPriorityRange.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIncludeError, "PriorityRangeNotValid", "include priorityRange min 10 is greater than max 0"),
+			{Name: proxyChildRoutePriority.Name, Namespace: proxyChildRoutePriority.Namespace}: fixture.NewValidCondition().
+				Orphaned(),
+		},
+	})
+
 	proxyTCPInvalidMissingService := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "missing-tcp-proxy-service",
@@ -2162,7 +2453,7 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
-	tlsNoPassthroughOrSecretName := &contour_api_v1.HTTPProxy{
+	tlsPassthroughAndAdditionalSecretNames := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "invalid",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
@@ -2171,80 +2462,750 @@ func TestDAGStatus(t *testing.T) {
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "tcpproxy.example.com",
 				TLS: &contour_api_v1.TLS{
-					Passthrough: false,
-					SecretName:  "",
+					Passthrough:           true,
+					AdditionalSecretNames: []string{fixture.SecretRootsECDSACert.Name},
 				},
 			},
 			TCPProxy: &contour_api_v1.TCPProxy{},
 		},
 	}
 
-	run(t, "httpproxy w/ tcpproxy with neither TLS passthrough nor secret name specified", testcase{
+	run(t, "tcpproxy with TLS passthrough and additional secret names both specified", testcase{
 		objs: []interface{}{
-			fixture.SecretRootsCert,
-			tlsNoPassthroughOrSecretName,
+			fixture.SecretRootsECDSACert,
+			tlsPassthroughAndAdditionalSecretNames,
 		},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
 			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: neither Passthrough nor SecretName were specified"),
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: both Passthrough and AdditionalSecretNames were specified"),
 		},
 	})
 
-	emptyProxy := &contour_api_v1.HTTPProxy{
+	tlsAdditionalSecretSameKeyType := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "empty",
-			Namespace: "roots",
+			Name:      "invalid",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:            fixture.SecretRootsCert.Name,
+					AdditionalSecretNames: []string{fixture.SecretRootsECDSACert.Name, fixture.SecretRootsFallback.Name},
+				},
 			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
+			}},
 		},
 	}
 
-	run(t, "proxy with no routes, includes, or tcpproxy is invalid", testcase{
-		objs: []interface{}{emptyProxy},
+	run(t, "additional secret with the same key type as another secret", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.SecretRootsECDSACert,
+			fixture.SecretRootsFallback,
+			fixture.ServiceRootsKuard,
+			tlsAdditionalSecretSameKeyType,
+		},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: emptyProxy.Name, Namespace: emptyProxy.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeSpecError, "NothingDefined", "HTTPProxy.Spec must have at least one Route, Include, or a TCPProxy"),
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "SecretNotValid", `Spec.VirtualHost.TLS Secrets "ssl-cert" and "fallbacksecret" both have RSA certificates`),
 		},
 	})
 
-	invalidRequestHeadersPolicyService := &contour_api_v1.HTTPProxy{
+	tlsAdditionalSecretNotDelegated := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "invalidRHPService",
+			Name:      "invalid",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
 		},
 		Spec: contour_api_v1.HTTPProxySpec{
 			VirtualHost: &contour_api_v1.VirtualHost{
 				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:            fixture.SecretRootsECDSACert.Name,
+					AdditionalSecretNames: []string{fixture.SecretProjectContourCert.Namespace + "/" + fixture.SecretProjectContourCert.Name},
+				},
 			},
 			Routes: []contour_api_v1.Route{{
-				Services: []contour_api_v1.Service{
-					{
-						Name: fixture.ServiceRootsKuard.Name,
-						Port: 8080,
-						RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
-							Set: []contour_api_v1.HeaderValue{{
-								Name:  "Host",
-								Value: "external.com",
-							}},
-						},
-					},
-				},
+				Services: []contour_api_v1.Service{{
+					Name: fixture.ServiceRootsKuard.Name,
+					Port: 8080,
+				}},
 			}},
 		},
 	}
 
-	run(t, "requestHeadersPolicy, Host header invalid on Service", testcase{
-		objs: []interface{}{invalidRequestHeadersPolicyService, fixture.ServiceRootsKuard},
+	run(t, "additional secret delegation failure", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsECDSACert,
+			fixture.SecretProjectContourCert,
+			fixture.ServiceRootsKuard,
+			tlsAdditionalSecretNotDelegated,
+		},
 		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
-			{Name: invalidRequestHeadersPolicyService.Name, Namespace: invalidRequestHeadersPolicyService.Namespace}: fixture.NewValidCondition().
-				WithError(contour_api_v1.ConditionTypeServiceError, "RequestHeadersPolicyInvalid", `rewriting "Host" header is not supported on request headers`),
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "DelegationNotPermitted", `Spec.VirtualHost.TLS Secret "projectcontour/default-ssl-cert" certificate delegation not permitted`),
 		},
 	})
 
-	invalidResponseHeadersPolicyService := &contour_api_v1.HTTPProxy{
+	tlsInvalidParameters := func(tls contour_api_v1.TLS) *contour_api_v1.HTTPProxy {
+		tls.SecretName = fixture.SecretRootsCert.Name
+		return &contour_api_v1.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "invalid",
+				Namespace: fixture.ServiceRootsKuard.Namespace,
+			},
+			Spec: contour_api_v1.HTTPProxySpec{
+				VirtualHost: &contour_api_v1.VirtualHost{
+					Fqdn: "example.com",
+					TLS:  &tls,
+				},
+				Routes: []contour_api_v1.Route{{
+					Services: []contour_api_v1.Service{{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+					}},
+				}},
+			},
+		}
+	}
+
+	run(t, "invalid TLS maximum protocol version", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{MaximumProtocolVersion: "1.1"}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", `Spec.VirtualHost.TLS.MaximumProtocolVersion "1.1" is invalid, must be "1.2" or "1.3"`),
+		},
+	})
+
+	run(t, "TLS maximum protocol version lower than minimum", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{MinimumProtocolVersion: "1.3", MaximumProtocolVersion: "1.2"}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", `Spec.VirtualHost.TLS.MaximumProtocolVersion "1.2" is lower than the minimum protocol version "1.3"`),
+		},
+	})
+
+	run(t, "invalid TLS cipher suites", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{CipherSuites: []string{"ECDHE-RSA-AES128-GCM-SHA256", "DES-CBC3-SHA"}}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS.CipherSuites is invalid: invalid ciphers: DES-CBC3-SHA"),
+		},
+	})
+
+	run(t, "must-staple OCSP policy without an OCSP staple", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{OCSPStaplePolicy: "must-staple"}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotValid", `Spec.VirtualHost.TLS Secret "ssl-cert" has no OCSP staple, which the must-staple policy requires`),
+		},
+	})
+
+	expiredStapleSecret := fixture.SecretRootsCert.DeepCopy()
+	expiredStapleSecret.Data[OCSPStapleKey] = fixture.OCSPStaple(nil, fixture.Now.Add(-2*time.Hour), fixture.Now.Add(-time.Hour))
+
+	run(t, "must-staple OCSP policy with an expired OCSP staple", testcase{
+		objs: []interface{}{
+			expiredStapleSecret,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{OCSPStaplePolicy: "must-staple"}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "OCSPStapleNotValid", `Spec.VirtualHost.TLS Secret "ssl-cert" OCSP staple has expired`),
+		},
+	})
+
+	run(t, "invalid OCSP staple policy", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{OCSPStaplePolicy: "sometimes"}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", `Spec.VirtualHost.TLS.OCSPStaplePolicy "sometimes" is invalid, must be "lenient", "strict" or "must-staple"`),
+		},
+	})
+
+	certificateSecret := func(notAfter time.Time, dnsNames ...string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fixture.SecretRootsCert.Name,
+				Namespace: fixture.SecretRootsCert.Namespace,
+			},
+			Type: v1.SecretTypeTLS,
+			Data: secretdata(fixture.SelfSignedCertificate(notAfter, dnsNames...), fixture.RSA_PRIVATE_KEY),
+		}
+	}
+
+	// Certificates are encoded with a precision of one second.
+	expired := fixture.Now.Add(-time.Hour).Truncate(time.Second)
+
+	run(t, "expired certificate", testcase{
+		objs: []interface{}{
+			certificateSecret(expired, "example.com"),
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "CertificateExpired", `Spec.VirtualHost.TLS Secret "ssl-cert" certificate expired at `+expired.UTC().Format(time.RFC3339)),
+		},
+	})
+
+	run(t, "certificate not valid for the fqdn", testcase{
+		objs: []interface{}{
+			certificateSecret(fixture.Now.Add(time.Hour), "other.example.com"),
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "CertificateNameMismatch", `Spec.VirtualHost.TLS Secret "ssl-cert" is not valid for fqdn "example.com": x509: certificate is valid for other.example.com, not example.com`),
+		},
+	})
+
+	wildcardCertificate := tlsInvalidParameters(contour_api_v1.TLS{})
+	wildcardCertificate.Spec.VirtualHost.Fqdn = "*.example.com"
+
+	run(t, "wildcard certificate for a wildcard fqdn", testcase{
+		objs: []interface{}{
+			certificateSecret(fixture.Now.Add(time.Hour), "*.example.com"),
+			fixture.ServiceRootsKuard,
+			wildcardCertificate,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	expiring := fixture.Now.Add(24 * time.Hour).Truncate(time.Second)
+
+	run(t, "certificate expiring within the warning period", testcase{
+		objs: []interface{}{
+			certificateSecret(expiring, "example.com"),
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{}),
+		},
+		certificateExpiryWarning: 14 * 24 * time.Hour,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithWarning(contour_api_v1.ConditionTypeTLSError, "CertificateExpiring", `Spec.VirtualHost.TLS Secret "ssl-cert" certificate expires at `+expiring.UTC().Format(time.RFC3339)),
+		},
+	})
+
+	run(t, "certificate expiring after the warning period", testcase{
+		objs: []interface{}{
+			certificateSecret(fixture.Now.Add(30*24*time.Hour), "example.com"),
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{}),
+		},
+		certificateExpiryWarning: 14 * 24 * time.Hour,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	tlsPassthroughAndOCSPStaplePolicy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough:      true,
+					OCSPStaplePolicy: "strict",
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{},
+		},
+	}
+
+	run(t, "tcpproxy with TLS passthrough and OCSP staple policy both specified", testcase{
+		objs: []interface{}{
+			tlsPassthroughAndOCSPStaplePolicy,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: both Passthrough and OCSPStaplePolicy were specified"),
+		},
+	})
+
+	run(t, "missing session ticket keys secret", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{SessionTicketKeysSecretName: "ticket-keys"}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "SessionTicketKeysNotValid", `Spec.VirtualHost.TLS session ticket keys Secret "ticket-keys" is invalid: Secret not found`),
+		},
+	})
+
+	sessionTicketKeysSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ticket-keys",
+			Namespace: "projectcontour",
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			SessionTicketKeysKey: make([]byte, SessionTicketKeyLength),
+		},
+	}
+
+	run(t, "session ticket keys secret delegation failure", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			sessionTicketKeysSecret,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{SessionTicketKeysSecretName: "projectcontour/ticket-keys"}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "DelegationNotPermitted", `Spec.VirtualHost.TLS session ticket keys Secret "projectcontour/ticket-keys" certificate delegation not permitted`),
+		},
+	})
+
+	tlsPassthroughAndSessionTicketKeys := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough:                 true,
+					SessionTicketKeysSecretName: "ticket-keys",
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{},
+		},
+	}
+
+	run(t, "tcpproxy with TLS passthrough and session ticket keys both specified", testcase{
+		objs: []interface{}{
+			tlsPassthroughAndSessionTicketKeys,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: both Passthrough and SessionTicketKeysSecretName were specified"),
+		},
+	})
+
+	tlsPassthroughAndACMEChallenge := tlsPassthroughAndSessionTicketKeys.DeepCopy()
+	tlsPassthroughAndACMEChallenge.Spec.VirtualHost.TLS = &contour_api_v1.TLS{
+		Passthrough:   true,
+		ACMEChallenge: &contour_api_v1.ACMEChallenge{},
+	}
+
+	run(t, "tcpproxy with TLS passthrough and ACME challenge both specified", testcase{
+		objs: []interface{}{
+			tlsPassthroughAndACMEChallenge,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: both Passthrough and ACMEChallenge were specified"),
+		},
+	})
+
+	run(t, "ACME challenge service without port", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{ACMEChallenge: &contour_api_v1.ACMEChallenge{ServiceName: "kuard"}}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "ACMEChallengeNotValid", "Spec.VirtualHost.TLS.ACMEChallenge: ServiceName and Port must be specified together"),
+		},
+	})
+
+	run(t, "ACME challenge service not found", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsInvalidParameters(contour_api_v1.TLS{ACMEChallenge: &contour_api_v1.ACMEChallenge{ServiceName: "solver", Port: 8089}}),
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "ServiceUnresolvedReference", `Spec.VirtualHost.TLS.ACMEChallenge unresolved service reference: service "roots/solver" not found`),
+		},
+	})
+
+	acmeChallengeWildcard := tlsInvalidParameters(contour_api_v1.TLS{ACMEChallenge: &contour_api_v1.ACMEChallenge{}})
+	acmeChallengeWildcard.Spec.VirtualHost.Fqdn = "*.example.com"
+
+	run(t, "ACME challenge with wildcard fqdn", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			acmeChallengeWildcard,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "ACMEChallengeNotValid", "Spec.VirtualHost.TLS.ACMEChallenge cannot be used with a wildcard fqdn"),
+		},
+	})
+
+	additionalListeners := map[string]config.ListenerProtocol{
+		"internal": config.HTTPListenerProtocol,
+		"public":   config.HTTPSListenerProtocol,
+		"mqtt":     config.TCPListenerProtocol,
+		"dns":      config.UDPListenerProtocol,
+	}
+
+	listenerNotFound := tlsInvalidParameters(contour_api_v1.TLS{})
+	listenerNotFound.Spec.VirtualHost.Listener = "missing"
+
+	run(t, "virtual host binds to a listener that is not configured", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			listenerNotFound,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotFound", `Spec.VirtualHost.Listener "missing" is not configured`),
+		},
+	})
+
+	tlsOnHTTPListener := tlsInvalidParameters(contour_api_v1.TLS{})
+	tlsOnHTTPListener.Spec.VirtualHost.Listener = "internal"
+
+	run(t, "TLS virtual host binds to an HTTP listener", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsOnHTTPListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid", `Spec.VirtualHost.Listener "internal" serves HTTP and cannot be used with Spec.VirtualHost.TLS`),
+		},
+	})
+
+	noTLSOnHTTPSListener := tlsInvalidParameters(contour_api_v1.TLS{})
+	noTLSOnHTTPSListener.Spec.VirtualHost.TLS = nil
+	noTLSOnHTTPSListener.Spec.VirtualHost.Listener = "public"
+
+	run(t, "virtual host without TLS binds to an HTTPS listener", testcase{
+		objs: []interface{}{
+			fixture.ServiceRootsKuard,
+			noTLSOnHTTPSListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid", `Spec.VirtualHost.Listener "public" serves HTTPS and requires Spec.VirtualHost.TLS`),
+		},
+	})
+
+	acmeChallengeOnHTTPSListener := tlsInvalidParameters(contour_api_v1.TLS{ACMEChallenge: &contour_api_v1.ACMEChallenge{}})
+	acmeChallengeOnHTTPSListener.Spec.VirtualHost.Listener = "public"
+
+	run(t, "ACME challenge on an HTTPS listener", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			acmeChallengeOnHTTPSListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid", `Spec.VirtualHost.TLS.ACMEChallenge cannot be used with Spec.VirtualHost.Listener "public"`),
+		},
+	})
+
+	tlsOnTCPListener := tlsInvalidParameters(contour_api_v1.TLS{})
+	tlsOnTCPListener.Spec.VirtualHost.Listener = "mqtt"
+
+	run(t, "TLS virtual host binds to a TCP listener", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			fixture.ServiceRootsKuard,
+			tlsOnTCPListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid", `Spec.VirtualHost.Listener "mqtt" serves TCP and cannot be used with Spec.VirtualHost.TLS`),
+		},
+	})
+
+	routesOnTCPListener := tlsInvalidParameters(contour_api_v1.TLS{})
+	routesOnTCPListener.Spec.VirtualHost.TLS = nil
+	routesOnTCPListener.Spec.VirtualHost.Listener = "mqtt"
+
+	run(t, "virtual host without a TCP proxy binds to a TCP listener", testcase{
+		objs: []interface{}{
+			fixture.ServiceRootsKuard,
+			routesOnTCPListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid", `Spec.VirtualHost.Listener "mqtt" serves TCP and requires Spec.TCPProxy`),
+		},
+	})
+
+	routesAndTCPProxyOnTCPListener := routesOnTCPListener.DeepCopy()
+	routesAndTCPProxyOnTCPListener.Spec.TCPProxy = &contour_api_v1.TCPProxy{
+		Services: []contour_api_v1.Service{{
+			Name: fixture.ServiceRootsKuard.Name,
+			Port: 8080,
+		}},
+	}
+
+	run(t, "virtual host with routes binds to a TCP listener", testcase{
+		objs: []interface{}{
+			fixture.ServiceRootsKuard,
+			routesAndTCPProxyOnTCPListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid", `Spec.VirtualHost.Listener "mqtt" serves TCP and cannot be used with Spec.Routes or Spec.Includes`),
+		},
+	})
+
+	tcpProxyOnTCPListener := routesAndTCPProxyOnTCPListener.DeepCopy()
+	tcpProxyOnTCPListener.Name = "mqtt"
+	tcpProxyOnTCPListener.Spec.Routes = nil
+
+	run(t, "TCP proxy binds to a TCP listener", testcase{
+		objs: []interface{}{
+			fixture.ServiceRootsKuard,
+			tcpProxyOnTCPListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "mqtt", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	udpProxyOnDefaultListener := routesOnTCPListener.DeepCopy()
+	udpProxyOnDefaultListener.Spec.VirtualHost.Listener = ""
+	udpProxyOnDefaultListener.Spec.UDPProxy = &contour_api_v1.UDPProxy{
+		ServiceName: "dns",
+		Port:        53,
+	}
+
+	run(t, "UDP proxy binds to the default listeners", testcase{
+		objs: []interface{}{
+			fixture.ServiceRootsKuard,
+			udpProxyOnDefaultListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeUDPProxyError, "ListenerNotValid", "Spec.UDPProxy requires Spec.VirtualHost.Listener to be a UDP listener"),
+		},
+	})
+
+	routesOnUDPListener := routesOnTCPListener.DeepCopy()
+	routesOnUDPListener.Spec.VirtualHost.Listener = "dns"
+
+	run(t, "virtual host without a UDP proxy binds to a UDP listener", testcase{
+		objs: []interface{}{
+			fixture.ServiceRootsKuard,
+			routesOnUDPListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid", `Spec.VirtualHost.Listener "dns" serves UDP and requires Spec.UDPProxy`),
+		},
+	})
+
+	routesAndUDPProxyOnUDPListener := udpProxyOnDefaultListener.DeepCopy()
+	routesAndUDPProxyOnUDPListener.Spec.VirtualHost.Listener = "dns"
+
+	run(t, "virtual host with routes binds to a UDP listener", testcase{
+		objs: []interface{}{
+			fixture.ServiceRootsKuard,
+			routesAndUDPProxyOnUDPListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeVirtualHostError, "ListenerNotValid", `Spec.VirtualHost.Listener "dns" serves UDP and cannot be used with Spec.Routes, Spec.Includes or Spec.TCPProxy`),
+		},
+	})
+
+	serviceRootsDNS := &v1.Service{
+		ObjectMeta: fixture.ObjectMeta("roots/dns"),
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:     "dns-udp",
+				Protocol: "UDP",
+				Port:     53,
+			}, {
+				Name:     "dns-tcp",
+				Protocol: "TCP",
+				Port:     53,
+			}},
+		},
+	}
+
+	udpProxyOnUDPListener := routesAndUDPProxyOnUDPListener.DeepCopy()
+	udpProxyOnUDPListener.Name = "dns"
+	udpProxyOnUDPListener.Spec.Routes = nil
+
+	run(t, "UDP proxy binds to a UDP listener", testcase{
+		objs: []interface{}{
+			serviceRootsDNS,
+			udpProxyOnUDPListener,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "dns", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().Valid(),
+		},
+	})
+
+	udpProxyTCPService := udpProxyOnUDPListener.DeepCopy()
+	udpProxyTCPService.Spec.UDPProxy = &contour_api_v1.UDPProxy{
+		ServiceName: fixture.ServiceRootsKuard.Name,
+		Port:        8080,
+	}
+
+	run(t, "UDP proxy forwards to a TCP service port", testcase{
+		objs: []interface{}{
+			fixture.ServiceRootsKuard,
+			udpProxyTCPService,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "dns", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeUDPProxyError, "UnresolvedServiceRef", `Spec.UDPProxy unresolved service reference: unsupported service protocol "TCP"`),
+		},
+	})
+
+	udpProxyInvalidTimeout := udpProxyOnUDPListener.DeepCopy()
+	udpProxyInvalidTimeout.Spec.UDPProxy.SessionIdleTimeout = "0s"
+
+	run(t, "UDP proxy with a zero session idle timeout", testcase{
+		objs: []interface{}{
+			serviceRootsDNS,
+			udpProxyInvalidTimeout,
+		},
+		additionalListeners: additionalListeners,
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "dns", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeUDPProxyError, "SessionIdleTimeoutNotValid", `Spec.UDPProxy.SessionIdleTimeout "0s" must be a positive duration`),
+		},
+	})
+
+	tlsNoPassthroughOrSecretName := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcpproxy.example.com",
+				TLS: &contour_api_v1.TLS{
+					Passthrough: false,
+					SecretName:  "",
+				},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{},
+		},
+	}
+
+	run(t, "httpproxy w/ tcpproxy with neither TLS passthrough nor secret name specified", testcase{
+		objs: []interface{}{
+			fixture.SecretRootsCert,
+			tlsNoPassthroughOrSecretName,
+		},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: "invalid", Namespace: fixture.ServiceRootsKuard.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeTLSError, "TLSConfigNotValid", "Spec.VirtualHost.TLS: neither Passthrough nor SecretName were specified"),
+		},
+	})
+
+	emptyProxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "empty",
+			Namespace: "roots",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+		},
+	}
+
+	run(t, "proxy with no routes, includes, or tcpproxy is invalid", testcase{
+		objs: []interface{}{emptyProxy},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: emptyProxy.Name, Namespace: emptyProxy.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeSpecError, "NothingDefined", "HTTPProxy.Spec must have at least one Route, Include, or a TCPProxy"),
+		},
+	})
+
+	invalidRequestHeadersPolicyService := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalidRHPService",
+			Namespace: fixture.ServiceRootsKuard.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{
+					{
+						Name: fixture.ServiceRootsKuard.Name,
+						Port: 8080,
+						RequestHeadersPolicy: &contour_api_v1.HeadersPolicy{
+							Set: []contour_api_v1.HeaderValue{{
+								Name:  "Host",
+								Value: "external.com",
+							}},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	run(t, "requestHeadersPolicy, Host header invalid on Service", testcase{
+		objs: []interface{}{invalidRequestHeadersPolicyService, fixture.ServiceRootsKuard},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: invalidRequestHeadersPolicyService.Name, Namespace: invalidRequestHeadersPolicyService.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeServiceError, "RequestHeadersPolicyInvalid", `rewriting "Host" header is not supported on request headers`),
+		},
+	})
+
+	invalidResponseHeadersPolicyService := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "invalidRHPService",
 			Namespace: fixture.ServiceRootsKuard.Namespace,
@@ -2340,6 +3301,303 @@ func TestDAGStatus(t *testing.T) {
 		},
 	})
 
+	jwtProvider := contour_api_v1.JWTProvider{
+		Name:      "provider",
+		LocalJWKS: &contour_api_v1.LocalJWKS{Inline: `{"keys":[]}`},
+		Default:   true,
+	}
+
+	proxyJWTMultipleDefaults := fixture.NewProxy("roots/jwt-multiple-defaults").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithJWTProviders(jwtProvider, contour_api_v1.JWTProvider{
+			Name:      "other",
+			LocalJWKS: &contour_api_v1.LocalJWKS{Inline: `{"keys":[]}`},
+			Default:   true,
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "JWT providers with multiple defaults are invalid", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyJWTMultipleDefaults},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyJWTMultipleDefaults.Name, Namespace: proxyJWTMultipleDefaults.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeJWTVerificationError, "MultipleDefaultProvidersSpecified",
+					`Spec.VirtualHost.JWTProviders can have at most one default provider, but "provider" and "other" are both marked as default`),
+		},
+	})
+
+	proxyJWTMissingSecret := fixture.NewProxy("roots/jwt-missing-secret").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithJWTProviders(contour_api_v1.JWTProvider{
+			Name:      "provider",
+			LocalJWKS: &contour_api_v1.LocalJWKS{SecretName: "jwks"},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "JWT provider with missing JWKS secret is invalid", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyJWTMissingSecret},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyJWTMissingSecret.Name, Namespace: proxyJWTMissingSecret.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeJWTVerificationError, "LocalJWKSNotValid",
+					`Spec.VirtualHost.JWTProviders[0].LocalJWKS is invalid: Secret "jwks" is invalid: Secret not found`),
+		},
+	})
+
+	proxyJWTUndefinedProvider := fixture.NewProxy("roots/jwt-undefined-provider").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithJWTProviders(jwtProvider).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+				JWTVerificationPolicy: &contour_api_v1.JWTVerificationPolicy{
+					Mode:     "required",
+					Provider: "missing",
+				},
+			}},
+		})
+
+	run(t, "route referencing an undefined JWT provider is invalid", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyJWTUndefinedProvider},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyJWTUndefinedProvider.Name, Namespace: proxyJWTUndefinedProvider.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTProviderNotDefined",
+					`route's JWT verification policy references an undefined provider "missing"`),
+		},
+	})
+
+	proxyJWTPermitInsecure := fixture.NewProxy("roots/jwt-permit-insecure").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithJWTProviders(jwtProvider).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services:       []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+				PermitInsecure: true,
+			}},
+		})
+
+	run(t, "route verifying JWTs cannot permit insecure requests", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyJWTPermitInsecure},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyJWTPermitInsecure.Name, Namespace: proxyJWTPermitInsecure.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationNotPermitted",
+					"route cannot verify JWTs and permit insecure requests"),
+		},
+	})
+
+	basicAuthSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "htpasswd",
+			Namespace: "roots",
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\nbob:password\n"),
+		},
+	}
+
+	proxyBasicAuthMissingSecret := fixture.NewProxy("roots/basic-auth-missing-secret").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithBasicAuth(contour_api_v1.BasicAuth{SecretName: "missing"}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "basic auth with missing secret is invalid", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyBasicAuthMissingSecret},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBasicAuthMissingSecret.Name, Namespace: proxyBasicAuthMissingSecret.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "SecretNotValid",
+					`Spec.VirtualHost.BasicAuth Secret "missing" is invalid: Secret not found`),
+		},
+	})
+
+	proxyBasicAuthUnsupportedHash := fixture.NewProxy("roots/basic-auth-unsupported-hash").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithBasicAuth(contour_api_v1.BasicAuth{SecretName: basicAuthSecret.Name}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "basic auth with unsupported password hashes is invalid", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, basicAuthSecret, proxyBasicAuthUnsupportedHash},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBasicAuthUnsupportedHash.Name, Namespace: proxyBasicAuthUnsupportedHash.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "UnsupportedPasswordHash",
+					`Spec.VirtualHost.BasicAuth Secret "htpasswd" has password hashes in unsupported formats, only bcrypt, apr1 and SHA1 hashes are supported: bob (crypt or plain text)`),
+		},
+	})
+
+	proxyBasicAuthPermitInsecure := fixture.NewProxy("roots/basic-auth-permit-insecure").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services:       []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+				PermitInsecure: true,
+				BasicAuth:      &contour_api_v1.BasicAuth{SecretName: "htpasswd-sha"},
+			}},
+		})
+
+	run(t, "route requiring basic auth cannot permit insecure requests", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyBasicAuthPermitInsecure, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "htpasswd-sha",
+				Namespace: "roots",
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n"),
+			},
+		}},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyBasicAuthPermitInsecure.Name, Namespace: proxyBasicAuthPermitInsecure.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+					"route cannot require basic authentication and permit insecure requests"),
+		},
+	})
+
+	oauth2 := contour_api_v1.OAuth2{
+		TokenEndpoint: contour_api_v1.OAuth2TokenEndpoint{
+			URI: "https://idp.example.com/token",
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+		},
+		AuthorizationEndpoint: "https://idp.example.com/authorize",
+		ClientID:              "app",
+		ClientSecretName:      "oauth2",
+		HMACSecretName:        "oauth2",
+	}
+
+	proxyOAuth2 := fixture.NewProxy("roots/oauth2").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithOAuth2(oauth2).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "OAuth2 is not supported", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyOAuth2},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyOAuth2.Name, Namespace: proxyOAuth2.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeOAuth2Error, "OAuth2NotSupported",
+					"Spec.VirtualHost.OAuth2 is not supported: Envoy 1.18 cannot configure the OAuth2 scopes it requests"),
+		},
+	})
+
+	proxyInvalidIPFilter := fixture.NewProxy("roots/invalid-ip-filter").
+		WithFQDN("example.com").
+		WithIPFilterPolicy(contour_api_v1.IPFilterPolicy{Allow: []string{"10.0.0.0/8", "office"}}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "invalid virtual host IP filter policy", testcase{
+		objs: []interface{}{fixture.ServiceRootsKuard, proxyInvalidIPFilter},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidIPFilter.Name, Namespace: proxyInvalidIPFilter.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+					`Spec.VirtualHost.IPFilterPolicy is invalid: invalid allow entry: "office" is not an IP address or CIDR range`),
+		},
+	})
+
+	proxyInvalidRouteIPFilter := fixture.NewProxy("roots/invalid-route-ip-filter").
+		WithFQDN("example.com").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services:       []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+				IPFilterPolicy: &contour_api_v1.IPFilterPolicy{Deny: []string{"10.0.0.0/40"}},
+			}},
+		})
+
+	run(t, "invalid route IP filter policy", testcase{
+		objs: []interface{}{fixture.ServiceRootsKuard, proxyInvalidRouteIPFilter},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidRouteIPFilter.Name, Namespace: proxyInvalidRouteIPFilter.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+					`route.ipFilterPolicy is invalid: invalid deny entry: "10.0.0.0/40" is not an IP address or CIDR range`),
+		},
+	})
+
+	proxyEmptyIPFilter := fixture.NewProxy("roots/empty-ip-filter").
+		WithFQDN("example.com").
+		WithIPFilterPolicy(contour_api_v1.IPFilterPolicy{}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "empty virtual host IP filter policy", testcase{
+		objs: []interface{}{fixture.ServiceRootsKuard, proxyEmptyIPFilter},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyEmptyIPFilter.Name, Namespace: proxyEmptyIPFilter.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+					"Spec.VirtualHost.IPFilterPolicy is invalid: at least one allow or deny entry must be specified"),
+		},
+	})
+
+	proxyDisjointRouteIPFilter := fixture.NewProxy("roots/disjoint-route-ip-filter").
+		WithFQDN("example.com").
+		WithIPFilterPolicy(contour_api_v1.IPFilterPolicy{Allow: []string{"10.0.0.0/8"}}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services:       []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+				IPFilterPolicy: &contour_api_v1.IPFilterPolicy{Allow: []string{"192.168.0.0/16"}},
+			}},
+		})
+
+	run(t, "route IP filter policy cannot widen the virtual host policy", testcase{
+		objs: []interface{}{fixture.ServiceRootsKuard, proxyDisjointRouteIPFilter},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyDisjointRouteIPFilter.Name, Namespace: proxyDisjointRouteIPFilter.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+					"route.ipFilterPolicy is invalid: no allow entry overlaps the allowed ranges of the virtual host"),
+		},
+	})
+
+	proxyInvalidTCPProxyIPFilter := fixture.NewProxy("roots/invalid-tcpproxy-ip-filter").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services:       []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+				IPFilterPolicy: &contour_api_v1.IPFilterPolicy{Allow: []string{"::1/200"}},
+			},
+		})
+
+	run(t, "invalid tcpproxy IP filter policy", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyInvalidTCPProxyIPFilter},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyInvalidTCPProxyIPFilter.Name, Namespace: proxyInvalidTCPProxyIPFilter.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+					`Spec.TCPProxy.IPFilterPolicy is invalid: invalid allow entry: "::1/200" is not an IP address or CIDR range`),
+		},
+	})
+
 	invalidResponseTimeout := &contour_api_v1.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: fixture.ServiceRootsKuard.Namespace,
@@ -2481,6 +3739,68 @@ func TestDAGStatus(t *testing.T) {
 				Valid(),
 		},
 	})
+
+	proxyHSTSInsecure := fixture.NewProxy("roots/hsts-insecure").
+		WithFQDN("example.com").
+		WithHSTSPolicy(contour_api_v1.HSTSPolicy{MaxAge: "24h"}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "HSTS policy requires TLS", testcase{
+		objs: []interface{}{fixture.ServiceRootsKuard, proxyHSTSInsecure},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyHSTSInsecure.Name, Namespace: proxyHSTSInsecure.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeHSTSError, "HSTSPolicyNotPermitted",
+					"Spec.VirtualHost.HSTSPolicy can only be defined for root HTTPProxies that terminate TLS"),
+		},
+	})
+
+	proxyHSTSPreload := fixture.NewProxy("roots/hsts-preload").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithHSTSPolicy(contour_api_v1.HSTSPolicy{MaxAge: "24h", IncludeSubDomains: true, Preload: true}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+			}},
+		})
+
+	run(t, "HSTS preload requires a max age of one year", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyHSTSPreload},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyHSTSPreload.Name, Namespace: proxyHSTSPreload.Namespace}: fixture.NewValidCondition().
+				WithError(contour_api_v1.ConditionTypeHSTSError, "HSTSPolicyNotValid",
+					"Spec.VirtualHost.HSTSPolicy is invalid: preload requires includeSubDomains and a maxAge of at least 8760h0m0s"),
+		},
+	})
+
+	proxyHSTSConflict := fixture.NewProxy("roots/hsts-conflict").
+		WithFQDN("example.com").
+		WithCertificate(fixture.SecretRootsCert.Name).
+		WithHSTSPolicy(contour_api_v1.HSTSPolicy{MaxAge: "24h"}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: fixture.ServiceRootsKuard.Name, Port: 8080}},
+				ResponseHeadersPolicy: &contour_api_v1.HeadersPolicy{
+					Set: []contour_api_v1.HeaderValue{{
+						Name:  "strict-transport-security",
+						Value: "max-age=60",
+					}},
+				},
+			}},
+		})
+
+	run(t, "route response header conflicts with HSTS policy", testcase{
+		objs: []interface{}{fixture.SecretRootsCert, fixture.ServiceRootsKuard, proxyHSTSConflict},
+		want: map[types.NamespacedName]contour_api_v1.DetailedCondition{
+			{Name: proxyHSTSConflict.Name, Namespace: proxyHSTSConflict.Namespace}: fixture.NewValidCondition().
+				WithWarning(contour_api_v1.ConditionTypeHSTSError, "HSTSPolicyConflict",
+					"route.responseHeadersPolicy Strict-Transport-Security header is overridden by the HSTS policy on TLS responses"),
+		},
+	})
 }
 
 func TestGatewayAPIDAGStatus(t *testing.T) {
@@ -2527,7 +3847,9 @@ func TestGatewayAPIDAGStatus(t *testing.T) {
 					&IngressProcessor{
 						FieldLogger: fixture.NewTestLogger(t),
 					},
-					&HTTPProxyProcessor{},
+					&HTTPProxyProcessor{
+						Clock: fixture.Clock,
+					},
 					&GatewayAPIProcessor{
 						FieldLogger: fixture.NewTestLogger(t),
 					},
@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/projectcontour/contour/internal/dag"
+	v1 "k8s.io/api/core/v1"
 )
 
 // Clustername returns the name of the CDS cluster for this service.
@@ -45,6 +46,14 @@ func Clustername(cluster *dag.Cluster) string {
 	if uv := cluster.UpstreamValidation; uv != nil {
 		buf += uv.CACertificate.Object.ObjectMeta.Name
 		buf += uv.SubjectName
+		for _, sn := range uv.SubjectNames {
+			buf += sn.MatchType + sn.Value
+		}
+	}
+
+	// A UDP port may have the same number as a TCP port.
+	if service.Weighted.ServicePort.Protocol == v1.ProtocolUDP {
+		buf += string(v1.ProtocolUDP)
 	}
 
 	// This isn't a crypto hash, we just want a unique name.
@@ -28,3 +28,11 @@ func Secretname(s *dag.Secret) string {
 	name := s.Name()
 	return Hashname(60, ns, name, fmt.Sprintf("%x", hash[:5]))
 }
+
+// SessionTicketKeysSecretname returns the name of the SDS secret
+// that holds the TLS session ticket keys of this secret. Unlike the
+// other secret names, it doesn't change when the keys are rotated,
+// so that Envoy updates the keys without changing its listeners.
+func SessionTicketKeysSecretname(s *dag.Secret) string {
+	return Hashname(60, s.Namespace(), s.Name(), dag.SessionTicketKeysKey)
+}
@@ -44,14 +44,14 @@ func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni st
 		Sni: sni,
 	}
 
-	if peerValidationContext.GetCACertificate() != nil && len(peerValidationContext.GetSubjectName()) > 0 {
+	if peerValidationContext.GetCACertificate() != nil && len(peerValidationContext.GetSubjectNames()) > 0 {
 		// We have to explicitly assign the value from validationContext
 		// to context.CommonTlsContext.ValidationContextType because the
 		// latter is an interface. Returning nil from validationContext
 		// directly into this field boxes the nil into the unexported
 		// type of this grpc OneOf field which causes proto marshaling
 		// to explode later on.
-		vc := validationContext(peerValidationContext.GetCACertificate(), peerValidationContext.GetSubjectName(), false)
+		vc := validationContext(peerValidationContext.GetCACertificate(), peerValidationContext.GetSubjectNames(), false)
 		if vc != nil {
 			context.CommonTlsContext.ValidationContextType = vc
 		}
@@ -60,7 +60,7 @@ func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni st
 	return context
 }
 
-func validationContext(ca []byte, subjectName string, skipVerifyPeerCert bool) *envoy_v3_tls.CommonTlsContext_ValidationContext {
+func validationContext(ca []byte, subjectNames []dag.SubjectNameMatch, skipVerifyPeerCert bool) *envoy_v3_tls.CommonTlsContext_ValidationContext {
 	vc := &envoy_v3_tls.CommonTlsContext_ValidationContext{
 		ValidationContext: &envoy_v3_tls.CertificateValidationContext{
 			TrustChainVerification: envoy_v3_tls.CertificateValidationContext_VERIFY_TRUST_CHAIN,
@@ -80,44 +80,89 @@ func validationContext(ca []byte, subjectName string, skipVerifyPeerCert bool) *
 		}
 	}
 
-	if len(subjectName) > 0 {
-		vc.ValidationContext.MatchSubjectAltNames = []*matcher.StringMatcher{{
-			MatchPattern: &matcher.StringMatcher_Exact{
-				Exact: subjectName,
-			}},
-		}
+	for _, name := range subjectNames {
+		vc.ValidationContext.MatchSubjectAltNames = append(vc.ValidationContext.MatchSubjectAltNames, subjectAltNameMatcher(name))
 	}
 
 	return vc
 }
 
-// DownstreamTLSContext creates a new DownstreamTlsContext.
-func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_v3_tls.TlsParameters_TlsProtocol, cipherSuites []string, peerValidationContext *dag.PeerValidationContext, alpnProtos ...string) *envoy_v3_tls.DownstreamTlsContext {
+// subjectAltNameMatcher returns a matcher.StringMatcher for the supplied
+// subject alternative name match. Envoy checks the matcher against both
+// the DNS and URI SANs of the peer certificate.
+func subjectAltNameMatcher(name dag.SubjectNameMatch) *matcher.StringMatcher {
+	switch name.MatchType {
+	case dag.SubjectNameMatchTypePrefix:
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Prefix{
+				Prefix: name.Value,
+			},
+		}
+	case dag.SubjectNameMatchTypeRegex:
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_SafeRegex{
+				SafeRegex: SafeRegexMatch(name.Value),
+			},
+		}
+	default:
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Exact{
+				Exact: name.Value,
+			},
+		}
+	}
+}
+
+// DownstreamTLSContext creates a new DownstreamTlsContext. If there
+// are multiple server secrets, Envoy selects one based on the key
+// types that the client supports.
+func DownstreamTLSContext(serverSecrets []*dag.Secret, tlsMinProtoVersion, tlsMaxProtoVersion envoy_v3_tls.TlsParameters_TlsProtocol, cipherSuites []string, peerValidationContext *dag.PeerValidationContext, alpnProtos ...string) *envoy_v3_tls.DownstreamTlsContext {
 	context := &envoy_v3_tls.DownstreamTlsContext{
 		CommonTlsContext: &envoy_v3_tls.CommonTlsContext{
 			TlsParams: &envoy_v3_tls.TlsParameters{
 				TlsMinimumProtocolVersion: tlsMinProtoVersion,
-				TlsMaximumProtocolVersion: envoy_v3_tls.TlsParameters_TLSv1_3,
+				TlsMaximumProtocolVersion: tlsMaxProtoVersion,
 				CipherSuites:              cipherSuites,
 			},
-			TlsCertificateSdsSecretConfigs: []*envoy_v3_tls.SdsSecretConfig{{
-				Name:      envoy.Secretname(serverSecret),
-				SdsConfig: ConfigSource("contour"),
-			}},
 			AlpnProtocols: alpnProtos,
 		},
 	}
+	for _, secret := range serverSecrets {
+		context.CommonTlsContext.TlsCertificateSdsSecretConfigs = append(context.CommonTlsContext.TlsCertificateSdsSecretConfigs,
+			&envoy_v3_tls.SdsSecretConfig{
+				Name:      envoy.Secretname(secret),
+				SdsConfig: ConfigSource("contour"),
+			})
+	}
 	if peerValidationContext != nil {
-		vc := validationContext(peerValidationContext.GetCACertificate(), "", peerValidationContext.SkipClientCertValidation)
+		vc := validationContext(peerValidationContext.GetCACertificate(), nil, peerValidationContext.SkipClientCertValidation)
 		if vc != nil {
+			if len(peerValidationContext.CRL) > 0 {
+				vc.ValidationContext.Crl = &envoy_api_v3_core.DataSource{
+					Specifier: &envoy_api_v3_core.DataSource_InlineBytes{
+						InlineBytes: peerValidationContext.CRL,
+					},
+				}
+			}
 			context.CommonTlsContext.ValidationContextType = vc
-			context.RequireClientCertificate = protobuf.Bool(true)
+			context.RequireClientCertificate = protobuf.Bool(!peerValidationContext.OptionalClientCertificate)
 		}
 	}
 
 	return context
 }
 
+// SessionTicketKeys returns the configuration for a DownstreamTlsContext
+// to fetch its TLS session ticket keys from the supplied secret over SDS.
+func SessionTicketKeys(secret *dag.Secret) *envoy_v3_tls.DownstreamTlsContext_SessionTicketKeysSdsSecretConfig {
+	return &envoy_v3_tls.DownstreamTlsContext_SessionTicketKeysSdsSecretConfig{
+		SessionTicketKeysSdsSecretConfig: &envoy_v3_tls.SdsSecretConfig{
+			Name:      envoy.SessionTicketKeysSecretname(secret),
+			SdsConfig: ConfigSource("contour"),
+		},
+	}
+}
+
 func http2ProtocolOptions() map[string]*any.Any {
 	return map[string]*any.Any{
 		"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": protobuf.MustMarshalAny(
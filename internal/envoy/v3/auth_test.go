@@ -96,6 +96,72 @@ func TestUpstreamTLSContext(t *testing.T) {
 				},
 			},
 		},
+		"no alpn, ca and multiple altnames": {
+			validation: &dag.PeerValidationContext{
+				CACertificate: secret,
+				SubjectName:   "www.example.com",
+				SubjectNames: []dag.SubjectNameMatch{{
+					MatchType: dag.SubjectNameMatchTypePrefix,
+					Value:     "spiffe://cluster.local/ns/default/",
+				}, {
+					MatchType: dag.SubjectNameMatchTypeRegex,
+					Value:     `spiffe://cluster\.local/ns/[^/]+/sa/backend`,
+				}},
+			},
+			want: &envoy_v3_tls.UpstreamTlsContext{
+				CommonTlsContext: &envoy_v3_tls.CommonTlsContext{
+					ValidationContextType: &envoy_v3_tls.CommonTlsContext_ValidationContext{
+						ValidationContext: &envoy_v3_tls.CertificateValidationContext{
+							TrustedCa: &envoy_api_v3_core.DataSource{
+								Specifier: &envoy_api_v3_core.DataSource_InlineBytes{
+									InlineBytes: []byte("ca"),
+								},
+							},
+							MatchSubjectAltNames: []*matcher.StringMatcher{{
+								MatchPattern: &matcher.StringMatcher_Exact{
+									Exact: "www.example.com",
+								},
+							}, {
+								MatchPattern: &matcher.StringMatcher_Prefix{
+									Prefix: "spiffe://cluster.local/ns/default/",
+								},
+							}, {
+								MatchPattern: &matcher.StringMatcher_SafeRegex{
+									SafeRegex: SafeRegexMatch(`spiffe://cluster\.local/ns/[^/]+/sa/backend`),
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+		"no alpn, ca and uri altname only": {
+			validation: &dag.PeerValidationContext{
+				CACertificate: secret,
+				SubjectNames: []dag.SubjectNameMatch{{
+					MatchType: dag.SubjectNameMatchTypeExact,
+					Value:     "spiffe://cluster.local/ns/default/sa/backend",
+				}},
+			},
+			want: &envoy_v3_tls.UpstreamTlsContext{
+				CommonTlsContext: &envoy_v3_tls.CommonTlsContext{
+					ValidationContextType: &envoy_v3_tls.CommonTlsContext_ValidationContext{
+						ValidationContext: &envoy_v3_tls.CertificateValidationContext{
+							TrustedCa: &envoy_api_v3_core.DataSource{
+								Specifier: &envoy_api_v3_core.DataSource_InlineBytes{
+									InlineBytes: []byte("ca"),
+								},
+							},
+							MatchSubjectAltNames: []*matcher.StringMatcher{{
+								MatchPattern: &matcher.StringMatcher_Exact{
+									Exact: "spiffe://cluster.local/ns/default/sa/backend",
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
 		"external name sni": {
 			externalName: "projectcontour.local",
 			want: &envoy_v3_tls.UpstreamTlsContext{
@@ -129,6 +129,14 @@ func ExtensionCluster(ext *dag.ExtensionCluster) *envoy_cluster_v3.Cluster {
 	// TODO(jpeach): Externalname service support in https://github.com/projectcontour/contour/issues/2875
 
 	switch ext.Protocol {
+	case "tls":
+		cluster.TransportSocket = UpstreamTLSTransportSocket(
+			UpstreamTLSContext(
+				ext.UpstreamValidation,
+				ext.SNI,
+				ext.ClientCertificate,
+			),
+		)
 	case "h2":
 		cluster.TypedExtensionProtocolOptions = http2ProtocolOptions()
 		cluster.TransportSocket = UpstreamTLSTransportSocket(
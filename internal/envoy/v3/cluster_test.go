@@ -551,6 +551,24 @@ func TestClustername(t *testing.T) {
 			},
 			want: "default/backend/80/da39a3ee5e",
 		},
+		"udp": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Weighted: dag.WeightedService{
+						Weight:           1,
+						ServiceName:      "dns",
+						ServiceNamespace: "default",
+						ServicePort: v1.ServicePort{
+							Name:       "dns",
+							Protocol:   "UDP",
+							Port:       53,
+							TargetPort: intstr.FromInt(5353),
+						},
+					},
+				},
+			},
+			want: "default/dns/53/e9a6f622e3",
+		},
 		"far too long": {
 			cluster: &dag.Cluster{
 				Upstream: &dag.Service{
@@ -14,9 +14,11 @@
 package v3
 
 import (
+	"crypto/sha1" // nolint:gosec
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"sort"
 	"strings"
 	"time"
@@ -24,18 +26,26 @@ import (
 	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	envoy_compressor_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	envoy_config_filter_http_header_to_metadata_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/header_to_metadata/v3"
+	envoy_config_filter_http_jwt_authn_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
 	envoy_config_filter_http_local_ratelimit_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
 	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	envoy_config_filter_http_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	envoy_extensions_filters_http_router_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_config_filter_network_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/rbac/v3"
 	tcp "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	udp "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/udp/udp_proxy/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
@@ -140,6 +150,75 @@ func Listener(name, address string, port int, lf []*envoy_listener_v3.ListenerFi
 	return l
 }
 
+// QUICListener returns a new envoy_listener_v3.Listener for HTTP/3
+// over QUIC on the supplied UDP address and port.
+func QUICListener(name, address string, port int) *envoy_listener_v3.Listener {
+	addr := SocketAddress(address, port)
+	addr.GetSocketAddress().Protocol = envoy_core_v3.SocketAddress_UDP
+
+	return &envoy_listener_v3.Listener{
+		Name:    name,
+		Address: addr,
+		// Each worker needs its own socket so that the packets
+		// of a QUIC connection are always received by the same
+		// worker.
+		ReusePort: true,
+		UdpListenerConfig: &envoy_listener_v3.UdpListenerConfig{
+			UdpListenerName: "quiche_quic_listener",
+			ConfigType: &envoy_listener_v3.UdpListenerConfig_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_listener_v3.QuicProtocolOptions{}),
+			},
+		},
+	}
+}
+
+// UDPListener returns a new envoy_listener_v3.Listener that forwards
+// the datagrams received on the supplied UDP address and port with
+// the supplied UDP proxy.
+func UDPListener(name, address string, port int, proxy *dag.UDPProxy) *envoy_listener_v3.Listener {
+	addr := SocketAddress(address, port)
+	addr.GetSocketAddress().Protocol = envoy_core_v3.SocketAddress_UDP
+
+	return &envoy_listener_v3.Listener{
+		Name:    name,
+		Address: addr,
+		// Each worker needs its own socket so that the datagrams
+		// of a session are always received by the same worker.
+		ReusePort: true,
+		ListenerFilters: ListenerFilters(
+			UDPProxy(name, proxy),
+		),
+	}
+}
+
+// UDPProxy returns a new udp_proxy listener filter that forwards
+// datagrams to the cluster of the supplied UDP proxy.
+func UDPProxy(statPrefix string, proxy *dag.UDPProxy) *envoy_listener_v3.ListenerFilter {
+	config := &udp.UdpProxyConfig{
+		StatPrefix: statPrefix,
+		RouteSpecifier: &udp.UdpProxyConfig_Cluster{
+			Cluster: envoy.Clustername(proxy.Cluster),
+		},
+	}
+	if proxy.SessionIdleTimeout > 0 {
+		config.IdleTimeout = protobuf.Duration(proxy.SessionIdleTimeout)
+	}
+	if proxy.HashSourceIP {
+		config.HashPolicies = []*udp.UdpProxyConfig_HashPolicy{{
+			PolicySpecifier: &udp.UdpProxyConfig_HashPolicy_SourceIp{
+				SourceIp: true,
+			},
+		}}
+	}
+
+	return &envoy_listener_v3.ListenerFilter{
+		Name: "envoy.filters.udp_listener.udp_proxy",
+		ConfigType: &envoy_listener_v3.ListenerFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(config),
+		},
+	}
+}
+
 type httpConnectionManagerBuilder struct {
 	routeConfigName               string
 	metricsPrefix                 string
@@ -154,6 +233,7 @@ type httpConnectionManagerBuilder struct {
 	codec                         HTTPVersionType // Note the zero value is AUTO, which is the default we want.
 	allowChunkedLength            bool
 	numTrustedHops                uint32
+	forwardClientCertificate      *dag.ForwardClientCertificate
 }
 
 // RouteConfigName sets the name of the RDS element that contains
@@ -231,6 +311,14 @@ func (b *httpConnectionManagerBuilder) NumTrustedHops(num uint32) *httpConnectio
 	return b
 }
 
+// ForwardClientCertificate configures how the connection manager
+// sets the x-forwarded-client-cert header. If fcc is nil, the
+// header is removed from requests.
+func (b *httpConnectionManagerBuilder) ForwardClientCertificate(fcc *dag.ForwardClientCertificate) *httpConnectionManagerBuilder {
+	b.forwardClientCertificate = fcc
+	return b
+}
+
 func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBuilder {
 
 	// Add a default set of ordered http filters.
@@ -266,6 +354,15 @@ func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBu
 				},
 			},
 		},
+		&http.HttpFilter{
+			Name: "csrf",
+			ConfigType: &http.HttpFilter_TypedConfig{
+				// Enforcing no requests disables the filter
+				// globally, but it can be enabled on a
+				// per-vhost/route basis.
+				TypedConfig: protobuf.MustMarshalAny(csrfPolicy(&dag.CSRFPolicy{})),
+			},
+		},
 		&http.HttpFilter{
 			Name: "local_ratelimit",
 			ConfigType: &http.HttpFilter_TypedConfig{
@@ -291,6 +388,15 @@ func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBu
 	return b
 }
 
+// AddFilters appends each of filters in order, as AddFilter does.
+func (b *httpConnectionManagerBuilder) AddFilters(filters ...*http.HttpFilter) *httpConnectionManagerBuilder {
+	for _, f := range filters {
+		b.AddFilter(f)
+	}
+
+	return b
+}
+
 // AddFilter appends f to the list of filters for this HTTPConnectionManager. f
 // may be nil, in which case it is ignored. Note that Router filters
 // (filters with TypeUrl `type.googleapis.com/envoy.extensions.filters.http.router.v3.Router`)
@@ -416,6 +522,17 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 		cm.CommonHttpProtocolOptions.MaxConnectionDuration = protobuf.Duration(b.maxConnectionDuration.Duration())
 	}
 
+	if fcc := b.forwardClientCertificate; fcc != nil {
+		cm.ForwardClientCertDetails = forwardClientCertDetails(fcc.Mode)
+		cm.SetCurrentClientCertDetails = &http.HttpConnectionManager_SetCurrentClientCertDetails{
+			Subject: protobuf.Bool(fcc.Subject),
+			Uri:     fcc.URI,
+			Dns:     fcc.DNS,
+			Cert:    fcc.Cert,
+			Chain:   fcc.Chain,
+		}
+	}
+
 	if len(b.accessLoggers) > 0 {
 		cm.AccessLog = b.accessLoggers
 	}
@@ -436,6 +553,19 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_listener_v3.Filter {
 	}
 }
 
+func forwardClientCertDetails(mode string) http.HttpConnectionManager_ForwardClientCertDetails {
+	switch mode {
+	case dag.ForwardClientCertificateForward:
+		return http.HttpConnectionManager_FORWARD_ONLY
+	case dag.ForwardClientCertificateAppend:
+		return http.HttpConnectionManager_APPEND_FORWARD
+	case dag.ForwardClientCertificateSanitizeSet:
+		return http.HttpConnectionManager_SANITIZE_SET
+	default:
+		return http.HttpConnectionManager_SANITIZE
+	}
+}
+
 // HTTPConnectionManager creates a new HTTP Connection Manager filter
 // for the supplied route, access log, and client request timeout.
 func HTTPConnectionManager(routename string, accesslogger []*accesslog.AccessLog, requestTimeout time.Duration, xffNumTrustedHops uint32) *envoy_listener_v3.Filter {
@@ -604,27 +734,84 @@ end
 
 // FilterExternalAuthz returns an `ext_authz` filter configured with the
 // requested parameters.
-func FilterExternalAuthz(authzClusterName string, failOpen bool, timeout timeout.Setting) *http.HttpFilter {
-	authConfig := envoy_config_filter_http_ext_authz_v3.ExtAuthz{
-		Services: &envoy_config_filter_http_ext_authz_v3.ExtAuthz_GrpcService{
-			GrpcService: &envoy_core_v3.GrpcService{
-				TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
-					EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
-						ClusterName: authzClusterName,
+func FilterExternalAuthz(auth *dag.ExternalAuthorization) *http.HttpFilter {
+	return &http.HttpFilter{
+		Name: "envoy.filters.http.ext_authz",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(externalAuthz(auth)),
+		},
+	}
+}
+
+// FilterExternalAuthzSelected returns an `ext_authz` filter for the
+// given authorization server that only authorizes the requests for
+// which FilterAuthzServerSelector selected the server.
+func FilterExternalAuthzSelected(auth *dag.ExternalAuthorization) *http.HttpFilter {
+	authConfig := externalAuthz(auth)
+	authConfig.FilterEnabledMetadata = &matcher.MetadataMatcher{
+		Filter: authzServerMetadataNamespace,
+		Path: []*matcher.MetadataMatcher_PathSegment{{
+			Segment: &matcher.MetadataMatcher_PathSegment_Key{
+				Key: authzServerMetadataKey,
+			},
+		}},
+		Value: &matcher.ValueMatcher{
+			MatchPattern: &matcher.ValueMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Exact{
+						Exact: AuthorizationServerName(auth),
 					},
 				},
-				Timeout: envoy.Timeout(timeout),
-				// We don't need to configure metadata here, since we allow
-				// operators to specify authorization context parameters at
-				// the virtual host and route.
-				InitialMetadata: []*envoy_core_v3.HeaderValue{},
 			},
 		},
+	}
+
+	return &http.HttpFilter{
+		Name: "envoy.filters.http.ext_authz",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(authConfig),
+		},
+	}
+}
+
+// FilterAuthzServerSelector returns a `header_to_metadata` filter
+// that records the authorization server that is selected by the
+// virtual host or route configuration (see RouteAuthzServer) in the
+// request's dynamic metadata. The filter has no configuration of its
+// own, so requests that match no configuration select no server.
+func FilterAuthzServerSelector() *http.HttpFilter {
+	return &http.HttpFilter{
+		Name: "envoy.filters.http.header_to_metadata",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(
+				&envoy_config_filter_http_header_to_metadata_v3.Config{},
+			),
+		},
+	}
+}
+
+// AuthorizationServerName returns a name that identifies the
+// configuration of an authorization server.
+func AuthorizationServerName(auth *dag.ExternalAuthorization) string {
+	// The hash covers every setting of the filter, so that
+	// routes that use the same extension with different
+	// settings get separate filters.
+	hash := sha1.Sum(protobuf.MustMarshalAny(externalAuthz(auth)).Value) // nolint:gosec
+	return envoy.Hashname(60, auth.AuthorizationService.Name, fmt.Sprintf("%x", hash[:5]))
+}
+
+const (
+	authzServerMetadataNamespace = "envoy.filters.http.header_to_metadata"
+	authzServerMetadataKey       = "authorization_server"
+)
+
+func externalAuthz(auth *dag.ExternalAuthorization) *envoy_config_filter_http_ext_authz_v3.ExtAuthz {
+	authConfig := &envoy_config_filter_http_ext_authz_v3.ExtAuthz{
 		// Pretty sure we always want this. Why have an
 		// external auth service if it is not going to affect
 		// routing decisions?
 		ClearRouteCache:  true,
-		FailureModeAllow: failOpen,
+		FailureModeAllow: auth.AuthorizationFailOpen,
 		StatusOnError: &envoy_type.HttpStatus{
 			Code: envoy_type.StatusCode_Forbidden,
 		},
@@ -635,10 +822,367 @@ func FilterExternalAuthz(authzClusterName string, failOpen bool, timeout timeout
 		TransportApiVersion: envoy_core_v3.ApiVersion_V3,
 	}
 
+	if httpAuth := auth.AuthorizationService.HTTPAuthorization; httpAuth != nil {
+		authConfig.Services = httpAuthzService(auth, httpAuth)
+	} else {
+		// Note that gRPC authorization servers are always sent
+		// all the request headers, so the allowed headers do
+		// not apply here.
+		authConfig.Services = &envoy_config_filter_http_ext_authz_v3.ExtAuthz_GrpcService{
+			GrpcService: &envoy_core_v3.GrpcService{
+				TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+						ClusterName: auth.AuthorizationService.Name,
+					},
+				},
+				Timeout: envoy.Timeout(auth.AuthorizationResponseTimeout),
+				// We don't need to configure metadata here, since we allow
+				// operators to specify authorization context parameters at
+				// the virtual host and route.
+				InitialMetadata: []*envoy_core_v3.HeaderValue{},
+			},
+		}
+	}
+
+	if body := auth.AuthorizationServerWithRequestBody; body != nil {
+		authConfig.WithRequestBody = &envoy_config_filter_http_ext_authz_v3.BufferSettings{
+			MaxRequestBytes:     body.MaxRequestBytes,
+			AllowPartialMessage: body.AllowPartialMessage,
+			PackAsBytes:         body.PackAsBytes,
+		}
+	}
+
+	return authConfig
+}
+
+// httpAuthzService returns the `ext_authz` service that sends
+// authorization requests to an HTTP authorization server.
+func httpAuthzService(auth *dag.ExternalAuthorization, httpAuth *dag.HTTPAuthorizationService) *envoy_config_filter_http_ext_authz_v3.ExtAuthz_HttpService {
+	// Unlike the gRPC service, the HTTP service requires a
+	// timeout, so use the gRPC default if none is set.
+	requestTimeout := envoy.Timeout(auth.AuthorizationResponseTimeout)
+	if requestTimeout == nil {
+		requestTimeout = protobuf.Duration(200 * time.Millisecond)
+	}
+
+	var headersToAdd []*envoy_core_v3.HeaderValue
+	for name, value := range httpAuth.HeadersToAdd {
+		headersToAdd = append(headersToAdd, &envoy_core_v3.HeaderValue{
+			Key:   name,
+			Value: value,
+		})
+	}
+	sort.Slice(headersToAdd, func(i, j int) bool {
+		return headersToAdd[i].Key < headersToAdd[j].Key
+	})
+
+	return &envoy_config_filter_http_ext_authz_v3.ExtAuthz_HttpService{
+		HttpService: &envoy_config_filter_http_ext_authz_v3.HttpService{
+			ServerUri: &envoy_core_v3.HttpUri{
+				// The URI is only used for logging, since the
+				// requests are sent to the cluster.
+				Uri: "http://" + auth.AuthorizationService.Name,
+				HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{
+					Cluster: auth.AuthorizationService.Name,
+				},
+				Timeout: requestTimeout,
+			},
+			PathPrefix: httpAuth.PathPrefix,
+			AuthorizationRequest: &envoy_config_filter_http_ext_authz_v3.AuthorizationRequest{
+				AllowedHeaders: exactHeaderNames(auth.AuthorizationAllowedHeaders),
+				HeadersToAdd:   headersToAdd,
+			},
+			AuthorizationResponse: &envoy_config_filter_http_ext_authz_v3.AuthorizationResponse{
+				AllowedUpstreamHeaders: exactHeaderNames(httpAuth.AllowedUpstreamHeaders),
+				AllowedClientHeaders:   exactHeaderNames(httpAuth.AllowedDownstreamHeaders),
+			},
+		},
+	}
+}
+
+// exactHeaderNames returns a matcher for the given header names,
+// or nil if there are none.
+func exactHeaderNames(names []string) *matcher.ListStringMatcher {
+	if len(names) == 0 {
+		return nil
+	}
+
+	var patterns []*matcher.StringMatcher
+	for _, name := range names {
+		patterns = append(patterns, &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Exact{Exact: name},
+		})
+	}
+
+	return &matcher.ListStringMatcher{Patterns: patterns}
+}
+
+// JWTAllowMissingRequirement returns the name of the `jwt_authn`
+// requirement that verifies JWTs with the named provider, but
+// allows requests that do not have a JWT.
+func JWTAllowMissingRequirement(provider string) string {
+	return provider + "/allow-missing"
+}
+
+// FilterJWTAuthN returns a `jwt_authn` filter configured with the
+// requested providers. The filter does not verify JWTs by itself;
+// routes select a requirement by name with RouteJWTRequirement.
+func FilterJWTAuthN(providers []dag.JWTProvider) *http.HttpFilter {
+	if len(providers) == 0 {
+		return nil
+	}
+
+	jwtConfig := envoy_config_filter_http_jwt_authn_v3.JwtAuthentication{
+		Providers:      map[string]*envoy_config_filter_http_jwt_authn_v3.JwtProvider{},
+		RequirementMap: map[string]*envoy_config_filter_http_jwt_authn_v3.JwtRequirement{},
+	}
+
+	for _, provider := range providers {
+		jwtProvider := &envoy_config_filter_http_jwt_authn_v3.JwtProvider{
+			Issuer:    provider.Issuer,
+			Audiences: provider.Audiences,
+			Forward:   provider.ForwardJWT,
+		}
+
+		// Claims are copied to headers from the
+		// verified payload by FilterJWTClaimsToHeaders.
+		if len(provider.ClaimsToHeaders) > 0 {
+			jwtProvider.PayloadInMetadata = provider.Name
+		}
+
+		if provider.RemoteJWKS != nil {
+			remoteJWKS := &envoy_config_filter_http_jwt_authn_v3.RemoteJwks{
+				HttpUri: &envoy_core_v3.HttpUri{
+					Uri: provider.RemoteJWKS.URI,
+					HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{
+						Cluster: provider.RemoteJWKS.Cluster.Name,
+					},
+					Timeout: protobuf.Duration(provider.RemoteJWKS.Timeout),
+				},
+			}
+			if provider.RemoteJWKS.CacheDuration > 0 {
+				remoteJWKS.CacheDuration = protobuf.Duration(provider.RemoteJWKS.CacheDuration)
+			}
+
+			jwtProvider.JwksSourceSpecifier = &envoy_config_filter_http_jwt_authn_v3.JwtProvider_RemoteJwks{
+				RemoteJwks: remoteJWKS,
+			}
+		} else {
+			jwtProvider.JwksSourceSpecifier = &envoy_config_filter_http_jwt_authn_v3.JwtProvider_LocalJwks{
+				LocalJwks: &envoy_core_v3.DataSource{
+					Specifier: &envoy_core_v3.DataSource_InlineString{
+						InlineString: provider.LocalJWKS,
+					},
+				},
+			}
+		}
+
+		jwtConfig.Providers[provider.Name] = jwtProvider
+
+		requireProvider := &envoy_config_filter_http_jwt_authn_v3.JwtRequirement{
+			RequiresType: &envoy_config_filter_http_jwt_authn_v3.JwtRequirement_ProviderName{
+				ProviderName: provider.Name,
+			},
+		}
+
+		jwtConfig.RequirementMap[provider.Name] = requireProvider
+		jwtConfig.RequirementMap[JWTAllowMissingRequirement(provider.Name)] = &envoy_config_filter_http_jwt_authn_v3.JwtRequirement{
+			RequiresType: &envoy_config_filter_http_jwt_authn_v3.JwtRequirement_RequiresAny{
+				RequiresAny: &envoy_config_filter_http_jwt_authn_v3.JwtRequirementOrList{
+					Requirements: []*envoy_config_filter_http_jwt_authn_v3.JwtRequirement{
+						requireProvider,
+						{
+							RequiresType: &envoy_config_filter_http_jwt_authn_v3.JwtRequirement_AllowMissing{
+								AllowMissing: &empty.Empty{},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return &http.HttpFilter{
+		Name: "envoy.filters.http.jwt_authn",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&jwtConfig),
+		},
+	}
+}
+
+// FilterJWTClaimsToHeaders returns a Lua filter that copies claims
+// from the JWT payloads verified by the `jwt_authn` filter into
+// request headers. The headers are always removed from the client
+// request so that they cannot be spoofed. It returns nil if no
+// provider copies claims to headers.
+func FilterJWTClaimsToHeaders(providers []dag.JWTProvider) *http.HttpFilter {
+	var headers, claims strings.Builder
+
+	for _, provider := range providers {
+		if len(provider.ClaimsToHeaders) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&claims, "\t[%q] = {\n", provider.Name)
+		for _, c := range provider.ClaimsToHeaders {
+			fmt.Fprintf(&headers, "\t%q,\n", strings.ToLower(c.Header))
+			fmt.Fprintf(&claims, "\t\t{%q, %q},\n", c.Claim, strings.ToLower(c.Header))
+		}
+		fmt.Fprintf(&claims, "\t},\n")
+	}
+
+	if claims.Len() == 0 {
+		return nil
+	}
+
+	code := `
+local headers = {
+%s}
+
+local claims = {
+%s}
+
+function envoy_on_request(request_handle)
+	for _, header in ipairs(headers) do
+		request_handle:headers():remove(header)
+	end
+
+	local payloads = request_handle:streamInfo():dynamicMetadata():get("envoy.filters.http.jwt_authn")
+	if payloads == nil then
+		return
+	end
+
+	for provider, mappings in pairs(claims) do
+		local payload = payloads[provider]
+		if payload ~= nil then
+			for _, mapping in ipairs(mappings) do
+				local value = payload[mapping[1]]
+				local kind = type(value)
+				if kind == "string" or kind == "number" or kind == "boolean" then
+					request_handle:headers():replace(mapping[2], tostring(value))
+				end
+			end
+		end
+	end
+end
+	`
+
+	return &http.HttpFilter{
+		Name: "envoy.filters.http.lua",
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
+				InlineCode: fmt.Sprintf(code, headers.String(), claims.String()),
+			}),
+		},
+	}
+}
+
+// FilterBasicAuth returns an `ext_authz` filter that sends the
+// requests to routes that require HTTP Basic authentication (see
+// RouteBasicAuth) to Contour, which verifies their credentials.
+func FilterBasicAuth() *http.HttpFilter {
 	return &http.HttpFilter{
 		Name: "envoy.filters.http.ext_authz",
 		ConfigType: &http.HttpFilter_TypedConfig{
-			TypedConfig: protobuf.MustMarshalAny(&authConfig),
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_http_ext_authz_v3.ExtAuthz{
+				Services: &envoy_config_filter_http_ext_authz_v3.ExtAuthz_GrpcService{
+					GrpcService: &envoy_core_v3.GrpcService{
+						TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+							EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+								ClusterName: "contour",
+							},
+						},
+					},
+				},
+				// Credentials that cannot be verified must
+				// not be accepted.
+				FailureModeAllow: false,
+				StatusOnError: &envoy_type.HttpStatus{
+					Code: envoy_type.StatusCode_ServiceUnavailable,
+				},
+				TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+			}),
+		},
+	}
+}
+
+// FilterIPFilter returns an RBAC filter that enforces the IP filter
+// policies of routes. The filter has no rules of its own, so only
+// routes with a RouteIPFilter configuration are restricted.
+func FilterIPFilter() *http.HttpFilter {
+	return &http.HttpFilter{
+		Name: wellknown.HTTPRoleBasedAccessControl,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_http_rbac_v3.RBAC{}),
+		},
+	}
+}
+
+// IPFilter returns an RBAC network filter that only permits
+// connections from the clients permitted by the IP filter policy.
+func IPFilter(statPrefix string, policy *dag.IPFilterPolicy) *envoy_listener_v3.Filter {
+	return &envoy_listener_v3.Filter{
+		Name: wellknown.RoleBasedAccessControl,
+		ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_network_rbac_v3.RBAC{
+				StatPrefix: statPrefix,
+				Rules:      ipFilterRules(policy),
+			}),
+		},
+	}
+}
+
+// ipFilterRules returns the RBAC rules that only permit clients
+// that are in one of the allowed ranges, if any, and not in any
+// of the denied ranges. The client address is the remote address,
+// which Envoy derives from the X-Forwarded-For header when it
+// trusts one or more hops.
+func ipFilterRules(policy *dag.IPFilterPolicy) *envoy_rbac_v3.RBAC {
+	ranges := func(cidrs []*net.IPNet) *envoy_rbac_v3.Principal {
+		var ids []*envoy_rbac_v3.Principal
+		for _, cidr := range cidrs {
+			prefixLen, _ := cidr.Mask.Size()
+			ids = append(ids, &envoy_rbac_v3.Principal{
+				Identifier: &envoy_rbac_v3.Principal_RemoteIp{
+					RemoteIp: &envoy_core_v3.CidrRange{
+						AddressPrefix: cidr.IP.String(),
+						PrefixLen:     protobuf.UInt32(uint32(prefixLen)),
+					},
+				},
+			})
+		}
+		return &envoy_rbac_v3.Principal{
+			Identifier: &envoy_rbac_v3.Principal_OrIds{
+				OrIds: &envoy_rbac_v3.Principal_Set{Ids: ids},
+			},
+		}
+	}
+
+	var principals []*envoy_rbac_v3.Principal
+	if len(policy.Allow) > 0 {
+		principals = append(principals, ranges(policy.Allow))
+	}
+	if len(policy.Deny) > 0 {
+		principals = append(principals, &envoy_rbac_v3.Principal{
+			Identifier: &envoy_rbac_v3.Principal_NotId{
+				NotId: ranges(policy.Deny),
+			},
+		})
+	}
+
+	return &envoy_rbac_v3.RBAC{
+		Action: envoy_rbac_v3.RBAC_ALLOW,
+		Policies: map[string]*envoy_rbac_v3.Policy{
+			"ip-filter": {
+				Permissions: []*envoy_rbac_v3.Permission{{
+					Rule: &envoy_rbac_v3.Permission_Any{Any: true},
+				}},
+				Principals: []*envoy_rbac_v3.Principal{{
+					Identifier: &envoy_rbac_v3.Principal_AndIds{
+						AndIds: &envoy_rbac_v3.Principal_Set{Ids: principals},
+					},
+				}},
+			},
 		},
 	}
 }
@@ -659,6 +1203,17 @@ func FilterChainTLS(domain string, downstream *envoy_tls_v3.DownstreamTlsContext
 	return fc
 }
 
+// FilterChainQUIC returns a QUIC envoy_listener_v3.FilterChain for the supplied domain.
+func FilterChainQUIC(domain string, downstream *envoy_tls_v3.DownstreamTlsContext, filters []*envoy_listener_v3.Filter) *envoy_listener_v3.FilterChain {
+	return &envoy_listener_v3.FilterChain{
+		Filters: filters,
+		FilterChainMatch: &envoy_listener_v3.FilterChainMatch{
+			ServerNames: []string{domain},
+		},
+		TransportSocket: DownstreamQUICTransportSocket(downstream),
+	}
+}
+
 // FilterChainTLSFallback returns a TLS enabled envoy_listener_v3.FilterChain conifgured for FallbackCertificate.
 func FilterChainTLSFallback(downstream *envoy_tls_v3.DownstreamTlsContext, filters []*envoy_listener_v3.Filter) *envoy_listener_v3.FilterChain {
 	fc := &envoy_listener_v3.FilterChain{
@@ -14,19 +14,32 @@
 package v3
 
 import (
+	"net"
+	"strings"
 	"testing"
 	"time"
 
 	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	envoy_compressor_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
+	envoy_config_filter_http_csrf_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/csrf/v3"
+	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	envoy_config_filter_http_jwt_authn_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
 	envoy_config_filter_http_local_ratelimit_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_config_filter_network_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/rbac/v3"
 	envoy_tcp_proxy_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	envoy_udp_proxy_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/udp/udp_proxy/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
@@ -273,7 +286,7 @@ func TestDownstreamTLSContext(t *testing.T) {
 		want *envoy_tls_v3.DownstreamTlsContext
 	}{
 		"TLS context without client authentication": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, nil, "h2", "http/1.1"),
+			DownstreamTLSContext([]*dag.Secret{serverSecret}, envoy_tls_v3.TlsParameters_TLSv1_2, envoy_tls_v3.TlsParameters_TLSv1_3, cipherSuites, nil, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
@@ -283,7 +296,7 @@ func TestDownstreamTLSContext(t *testing.T) {
 			},
 		},
 		"TLS context with client authentication": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContext, "h2", "http/1.1"),
+			DownstreamTLSContext([]*dag.Secret{serverSecret}, envoy_tls_v3.TlsParameters_TLSv1_2, envoy_tls_v3.TlsParameters_TLSv1_3, cipherSuites, peerValidationContext, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
@@ -295,7 +308,7 @@ func TestDownstreamTLSContext(t *testing.T) {
 			},
 		},
 		"Downstream validation shall not support subjectName validation": {
-			DownstreamTLSContext(serverSecret, envoy_tls_v3.TlsParameters_TLSv1_2, cipherSuites, peerValidationContextWithSubjectName, "h2", "http/1.1"),
+			DownstreamTLSContext([]*dag.Secret{serverSecret}, envoy_tls_v3.TlsParameters_TLSv1_2, envoy_tls_v3.TlsParameters_TLSv1_3, cipherSuites, peerValidationContextWithSubjectName, "h2", "http/1.1"),
 			&envoy_tls_v3.DownstreamTlsContext{
 				CommonTlsContext: &envoy_tls_v3.CommonTlsContext{
 					TlsParams:                      tlsParams,
@@ -384,6 +397,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -475,6 +502,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -567,6 +608,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -660,6 +715,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -752,6 +821,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -845,6 +928,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -936,6 +1033,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -1028,6 +1139,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -1121,6 +1246,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -1215,6 +1354,20 @@ func TestHTTPConnectionManager(t *testing.T) {
 									TypeUrl: HTTPFilterCORS,
 								},
 							},
+						}, {
+							Name: "csrf",
+							ConfigType: &http.HttpFilter_TypedConfig{
+								TypedConfig: protobuf.MustMarshalAny(
+									&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+										FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+											DefaultValue: &envoy_type_v3.FractionalPercent{
+												Numerator:   0,
+												Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+											},
+										},
+									},
+								),
+							},
 						}, {
 							Name: "local_ratelimit",
 							ConfigType: &http.HttpFilter_TypedConfig{
@@ -1368,6 +1521,95 @@ func TestTCPProxy(t *testing.T) {
 	}
 }
 
+func TestUDPListener(t *testing.T) {
+	c := &dag.Cluster{
+		Upstream: &dag.Service{
+			Weighted: dag.WeightedService{
+				Weight:           1,
+				ServiceName:      "dns",
+				ServiceNamespace: "default",
+				ServicePort: v1.ServicePort{
+					Name:       "dns",
+					Protocol:   "UDP",
+					Port:       53,
+					TargetPort: intstr.FromInt(5353),
+				},
+			},
+		},
+	}
+
+	udpAddress := func(address string, port int) *envoy_core_v3.Address {
+		addr := SocketAddress(address, port)
+		addr.GetSocketAddress().Protocol = envoy_core_v3.SocketAddress_UDP
+		return addr
+	}
+
+	tests := map[string]struct {
+		proxy *dag.UDPProxy
+		want  *envoy_listener_v3.Listener
+	}{
+		"defaults": {
+			proxy: &dag.UDPProxy{
+				ListenerName: "dns",
+				Cluster:      c,
+			},
+			want: &envoy_listener_v3.Listener{
+				Name:      "dns",
+				Address:   udpAddress("0.0.0.0", 5353),
+				ReusePort: true,
+				ListenerFilters: []*envoy_listener_v3.ListenerFilter{{
+					Name: "envoy.filters.udp_listener.udp_proxy",
+					ConfigType: &envoy_listener_v3.ListenerFilter_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&envoy_udp_proxy_v3.UdpProxyConfig{
+							StatPrefix: "dns",
+							RouteSpecifier: &envoy_udp_proxy_v3.UdpProxyConfig_Cluster{
+								Cluster: envoy.Clustername(c),
+							},
+						}),
+					},
+				}},
+			},
+		},
+		"session idle timeout and source IP hash": {
+			proxy: &dag.UDPProxy{
+				ListenerName:       "dns",
+				Cluster:            c,
+				SessionIdleTimeout: 30 * time.Second,
+				HashSourceIP:       true,
+			},
+			want: &envoy_listener_v3.Listener{
+				Name:      "dns",
+				Address:   udpAddress("0.0.0.0", 5353),
+				ReusePort: true,
+				ListenerFilters: []*envoy_listener_v3.ListenerFilter{{
+					Name: "envoy.filters.udp_listener.udp_proxy",
+					ConfigType: &envoy_listener_v3.ListenerFilter_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&envoy_udp_proxy_v3.UdpProxyConfig{
+							StatPrefix: "dns",
+							RouteSpecifier: &envoy_udp_proxy_v3.UdpProxyConfig_Cluster{
+								Cluster: envoy.Clustername(c),
+							},
+							IdleTimeout: protobuf.Duration(30 * time.Second),
+							HashPolicies: []*envoy_udp_proxy_v3.UdpProxyConfig_HashPolicy{{
+								PolicySpecifier: &envoy_udp_proxy_v3.UdpProxyConfig_HashPolicy_SourceIp{
+									SourceIp: true,
+								},
+							}},
+						}),
+					},
+				}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := UDPListener("dns", "0.0.0.0", 5353, tc.proxy)
+			protobuf.ExpectEqual(t, tc.want, got)
+		})
+	}
+}
+
 // TestBuilderValidation tests that validation checks that
 // DefaultFilters adds the required HTTP connection manager filters.
 func TestBuilderValidation(t *testing.T) {
@@ -1492,7 +1734,7 @@ func TestAddFilter(t *testing.T) {
 		},
 		"Add to the default filters": {
 			builder: HTTPConnectionManagerBuilder().DefaultFilters(),
-			add:     FilterExternalAuthz("test", false, timeout.Setting{}),
+			add:     FilterExternalAuthz(&dag.ExternalAuthorization{AuthorizationService: &dag.ExtensionCluster{Name: "test"}}),
 			want: []*http.HttpFilter{
 				{
 					Name: "compressor",
@@ -1523,6 +1765,21 @@ func TestAddFilter(t *testing.T) {
 						},
 					},
 				},
+				{
+					Name: "csrf",
+					ConfigType: &http.HttpFilter_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(
+							&envoy_config_filter_http_csrf_v3.CsrfPolicy{
+								FilterEnabled: &envoy_core_v3.RuntimeFractionalPercent{
+									DefaultValue: &envoy_type_v3.FractionalPercent{
+										Numerator:   0,
+										Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+									},
+								},
+							},
+						),
+					},
+				},
 				{
 					Name: "local_ratelimit",
 					ConfigType: &http.HttpFilter_TypedConfig{
@@ -1533,7 +1790,7 @@ func TestAddFilter(t *testing.T) {
 						),
 					},
 				},
-				FilterExternalAuthz("test", false, timeout.Setting{}),
+				FilterExternalAuthz(&dag.ExternalAuthorization{AuthorizationService: &dag.ExtensionCluster{Name: "test"}}),
 				{
 					Name: "router",
 					ConfigType: &http.HttpFilter_TypedConfig{
@@ -1564,3 +1821,227 @@ func TestAddFilter(t *testing.T) {
 		})
 	})
 }
+
+func TestFilterJWTAuthN(t *testing.T) {
+	tests := map[string]struct {
+		providers []dag.JWTProvider
+		want      *http.HttpFilter
+	}{
+		"no providers": {
+			providers: nil,
+			want:      nil,
+		},
+		"local and remote providers": {
+			providers: []dag.JWTProvider{{
+				Name:       "local",
+				Issuer:     "issuer.example.com",
+				Audiences:  []string{"example"},
+				LocalJWKS:  `{"keys":[]}`,
+				ForwardJWT: true,
+			}, {
+				Name: "remote",
+				RemoteJWKS: &dag.RemoteJWKS{
+					URI:           "https://jwks.example.com/jwks.json",
+					Cluster:       &dag.ExtensionCluster{Name: "extension/auth/jwks"},
+					Timeout:       time.Second,
+					CacheDuration: time.Hour,
+				},
+				ClaimsToHeaders: []dag.JWTClaimToHeader{{Claim: "sub", Header: "X-Subject"}},
+			}},
+			want: &http.HttpFilter{
+				Name: "envoy.filters.http.jwt_authn",
+				ConfigType: &http.HttpFilter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_http_jwt_authn_v3.JwtAuthentication{
+						Providers: map[string]*envoy_config_filter_http_jwt_authn_v3.JwtProvider{
+							"local": {
+								Issuer:    "issuer.example.com",
+								Audiences: []string{"example"},
+								Forward:   true,
+								JwksSourceSpecifier: &envoy_config_filter_http_jwt_authn_v3.JwtProvider_LocalJwks{
+									LocalJwks: &envoy_core_v3.DataSource{
+										Specifier: &envoy_core_v3.DataSource_InlineString{
+											InlineString: `{"keys":[]}`,
+										},
+									},
+								},
+							},
+							"remote": {
+								PayloadInMetadata: "remote",
+								JwksSourceSpecifier: &envoy_config_filter_http_jwt_authn_v3.JwtProvider_RemoteJwks{
+									RemoteJwks: &envoy_config_filter_http_jwt_authn_v3.RemoteJwks{
+										HttpUri: &envoy_core_v3.HttpUri{
+											Uri: "https://jwks.example.com/jwks.json",
+											HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{
+												Cluster: "extension/auth/jwks",
+											},
+											Timeout: protobuf.Duration(time.Second),
+										},
+										CacheDuration: protobuf.Duration(time.Hour),
+									},
+								},
+							},
+						},
+						RequirementMap: map[string]*envoy_config_filter_http_jwt_authn_v3.JwtRequirement{
+							"local":                jwtRequirement("local", false),
+							"local/allow-missing":  jwtRequirement("local", true),
+							"remote":               jwtRequirement("remote", false),
+							"remote/allow-missing": jwtRequirement("remote", true),
+						},
+					}),
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			protobuf.ExpectEqual(t, tc.want, FilterJWTAuthN(tc.providers))
+		})
+	}
+}
+
+func jwtRequirement(provider string, allowMissing bool) *envoy_config_filter_http_jwt_authn_v3.JwtRequirement {
+	requirement := &envoy_config_filter_http_jwt_authn_v3.JwtRequirement{
+		RequiresType: &envoy_config_filter_http_jwt_authn_v3.JwtRequirement_ProviderName{
+			ProviderName: provider,
+		},
+	}
+
+	if !allowMissing {
+		return requirement
+	}
+
+	return &envoy_config_filter_http_jwt_authn_v3.JwtRequirement{
+		RequiresType: &envoy_config_filter_http_jwt_authn_v3.JwtRequirement_RequiresAny{
+			RequiresAny: &envoy_config_filter_http_jwt_authn_v3.JwtRequirementOrList{
+				Requirements: []*envoy_config_filter_http_jwt_authn_v3.JwtRequirement{
+					requirement,
+					{
+						RequiresType: &envoy_config_filter_http_jwt_authn_v3.JwtRequirement_AllowMissing{
+							AllowMissing: &empty.Empty{},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFilterJWTClaimsToHeaders(t *testing.T) {
+	assert.Nil(t, FilterJWTClaimsToHeaders([]dag.JWTProvider{{Name: "provider"}}))
+
+	got := FilterJWTClaimsToHeaders([]dag.JWTProvider{{
+		Name:            "provider",
+		ClaimsToHeaders: []dag.JWTClaimToHeader{{Claim: "sub", Header: "X-Subject"}},
+	}})
+	require.NotNil(t, got)
+
+	var code lua.Lua
+	require.NoError(t, ptypes.UnmarshalAny(got.GetTypedConfig(), &code))
+	assert.Contains(t, code.InlineCode, "\t\"x-subject\",\n")
+	assert.Contains(t, code.InlineCode, "\t[\"provider\"] = {\n\t\t{\"sub\", \"x-subject\"},\n\t},\n")
+}
+
+func TestIPFilter(t *testing.T) {
+	_, allow, _ := net.ParseCIDR("10.0.0.0/8")
+	_, deny, _ := net.ParseCIDR("2001:db8::1/128")
+
+	remoteIPs := func(prefix string, length uint32) *envoy_rbac_v3.Principal {
+		return &envoy_rbac_v3.Principal{
+			Identifier: &envoy_rbac_v3.Principal_OrIds{
+				OrIds: &envoy_rbac_v3.Principal_Set{
+					Ids: []*envoy_rbac_v3.Principal{{
+						Identifier: &envoy_rbac_v3.Principal_RemoteIp{
+							RemoteIp: &envoy_core_v3.CidrRange{
+								AddressPrefix: prefix,
+								PrefixLen:     protobuf.UInt32(length),
+							},
+						},
+					}},
+				},
+			},
+		}
+	}
+
+	got := IPFilter("ingress_https", &dag.IPFilterPolicy{
+		Allow: []*net.IPNet{allow},
+		Deny:  []*net.IPNet{deny},
+	})
+
+	want := &envoy_listener_v3.Filter{
+		Name: wellknown.RoleBasedAccessControl,
+		ConfigType: &envoy_listener_v3.Filter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_network_rbac_v3.RBAC{
+				StatPrefix: "ingress_https",
+				Rules: &envoy_rbac_v3.RBAC{
+					Action: envoy_rbac_v3.RBAC_ALLOW,
+					Policies: map[string]*envoy_rbac_v3.Policy{
+						"ip-filter": {
+							Permissions: []*envoy_rbac_v3.Permission{{
+								Rule: &envoy_rbac_v3.Permission_Any{Any: true},
+							}},
+							Principals: []*envoy_rbac_v3.Principal{{
+								Identifier: &envoy_rbac_v3.Principal_AndIds{
+									AndIds: &envoy_rbac_v3.Principal_Set{
+										Ids: []*envoy_rbac_v3.Principal{
+											remoteIPs("10.0.0.0", 8),
+											{
+												Identifier: &envoy_rbac_v3.Principal_NotId{
+													NotId: remoteIPs("2001:db8::1", 128),
+												},
+											},
+										},
+									},
+								},
+							}},
+						},
+					},
+				},
+			}),
+		},
+	}
+
+	protobuf.ExpectEqual(t, want, got)
+}
+
+func TestFilterExternalAuthzSelected(t *testing.T) {
+	auth := &dag.ExternalAuthorization{
+		AuthorizationService: &dag.ExtensionCluster{Name: "extension/auth/signature"},
+		AuthorizationServerWithRequestBody: &dag.AuthorizationServerBufferSettings{
+			MaxRequestBytes: 1024,
+		},
+	}
+
+	name := AuthorizationServerName(auth)
+	assert.True(t, strings.HasPrefix(name, "extension/auth/signature/"), name)
+
+	// Servers that use the same extension with different
+	// settings have different names.
+	failOpen := *auth
+	failOpen.AuthorizationFailOpen = true
+	assert.NotEqual(t, name, AuthorizationServerName(&failOpen))
+
+	got := FilterExternalAuthzSelected(auth)
+	assert.Equal(t, "envoy.filters.http.ext_authz", got.Name)
+
+	config := &envoy_config_filter_http_ext_authz_v3.ExtAuthz{}
+	assert.NoError(t, ptypes.UnmarshalAny(got.GetTypedConfig(), config))
+	protobuf.ExpectEqual(t, &envoy_config_filter_http_ext_authz_v3.BufferSettings{
+		MaxRequestBytes: 1024,
+	}, config.WithRequestBody)
+	protobuf.ExpectEqual(t, &matcher.MetadataMatcher{
+		Filter: "envoy.filters.http.header_to_metadata",
+		Path: []*matcher.MetadataMatcher_PathSegment{{
+			Segment: &matcher.MetadataMatcher_PathSegment_Key{
+				Key: "authorization_server",
+			},
+		}},
+		Value: &matcher.ValueMatcher{
+			MatchPattern: &matcher.ValueMatcher_StringMatch{
+				StringMatch: &matcher.StringMatcher{
+					MatchPattern: &matcher.StringMatcher_Exact{Exact: name},
+				},
+			},
+		},
+	}, config.FilterEnabledMetadata)
+}
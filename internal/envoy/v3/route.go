@@ -21,8 +21,13 @@ import (
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_config_filter_http_csrf_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/csrf/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	envoy_config_filter_http_header_to_metadata_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/header_to_metadata/v3"
+	envoy_config_filter_http_jwt_authn_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	envoy_config_filter_http_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/golang/protobuf/ptypes/any"
 	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/projectcontour/contour/internal/dag"
@@ -56,6 +61,76 @@ func RouteAuthzContext(settings map[string]string) *any.Any {
 	)
 }
 
+// RouteAuthzServer returns a per-route config that selects the
+// authorization server whose `ext_authz` filter authorizes requests
+// (see FilterExternalAuthzSelected). It can also be used as a
+// virtual host config to select the default server.
+func RouteAuthzServer(auth *dag.ExternalAuthorization) *any.Any {
+	selected := &envoy_config_filter_http_header_to_metadata_v3.Config_KeyValuePair{
+		MetadataNamespace: authzServerMetadataNamespace,
+		Key:               authzServerMetadataKey,
+		Value:             AuthorizationServerName(auth),
+	}
+
+	// The rule sets the same value whether or not the header is
+	// present, so that every request selects the server.
+	return protobuf.MustMarshalAny(
+		&envoy_config_filter_http_header_to_metadata_v3.Config{
+			RequestRules: []*envoy_config_filter_http_header_to_metadata_v3.Config_Rule{{
+				Header:          ":authority",
+				OnHeaderPresent: selected,
+				OnHeaderMissing: selected,
+			}},
+		},
+	)
+}
+
+// RouteJWTRequirement returns a per-route config that verifies JWTs
+// with the named requirement of the `jwt_authn` filter.
+func RouteJWTRequirement(requirement string) *any.Any {
+	return protobuf.MustMarshalAny(
+		&envoy_config_filter_http_jwt_authn_v3.PerRouteConfig{
+			RequirementSpecifier: &envoy_config_filter_http_jwt_authn_v3.PerRouteConfig_RequirementName{
+				RequirementName: requirement,
+			},
+		},
+	)
+}
+
+// RouteIPFilter returns a per-route config for FilterIPFilter that
+// only permits the clients permitted by the IP filter policy.
+func RouteIPFilter(policy *dag.IPFilterPolicy) *any.Any {
+	return protobuf.MustMarshalAny(
+		&envoy_config_filter_http_rbac_v3.RBACPerRoute{
+			Rbac: &envoy_config_filter_http_rbac_v3.RBAC{
+				Rules: ipFilterRules(policy),
+			},
+		},
+	)
+}
+
+const (
+	// BasicAuthSecretKey is the check request context entry
+	// that names the Secret holding the credentials that
+	// RouteBasicAuth accepts, as "namespace/name".
+	BasicAuthSecretKey = "basic_auth_secret"
+
+	// BasicAuthRealmKey is the check request context entry
+	// that holds the authentication realm of RouteBasicAuth.
+	BasicAuthRealmKey = "basic_auth_realm"
+)
+
+// RouteBasicAuth returns a per-route config for FilterBasicAuth
+// that requires the credentials of the given basic authentication.
+// Only the name of the credentials Secret is passed on, so that
+// password hashes never leave Contour.
+func RouteBasicAuth(auth *dag.BasicAuth) *any.Any {
+	return RouteAuthzContext(map[string]string{
+		BasicAuthSecretKey: auth.Secret.String(),
+		BasicAuthRealmKey:  auth.Realm,
+	})
+}
+
 const prefixPathMatchSegmentRegex = `((\/).*)?`
 
 var _ = regexp.MustCompile(prefixPathMatchSegmentRegex)
@@ -257,6 +332,83 @@ func HeaderValueList(hvm map[string]string, app bool) []*envoy_core_v3.HeaderVal
 	return hvs
 }
 
+// HSTSHeaders returns the Strict-Transport-Security response
+// header for the supplied HSTS policy.
+func HSTSHeaders(policy *dag.HSTSPolicy) []*envoy_core_v3.HeaderValueOption {
+	value := fmt.Sprintf("max-age=%d", int64(policy.MaxAge.Seconds()))
+	if policy.IncludeSubDomains {
+		value += "; includeSubDomains"
+	}
+	if policy.Preload {
+		value += "; preload"
+	}
+
+	return HeaderValueList(map[string]string{"Strict-Transport-Security": value}, false)
+}
+
+// AltSvcHeaders returns the response headers that advertise HTTP/3
+// on the supplied UDP port.
+func AltSvcHeaders(port int) []*envoy_core_v3.HeaderValueOption {
+	value := fmt.Sprintf(`h3=":%d"; ma=86400`, port)
+
+	return HeaderValueList(map[string]string{"alt-svc": value}, false)
+}
+
+// CSRFConfig returns a per-route or per-virtual host config for
+// the CSRF filter.
+func CSRFConfig(policy *dag.CSRFPolicy) *any.Any {
+	return protobuf.MustMarshalAny(csrfPolicy(policy))
+}
+
+func csrfPolicy(policy *dag.CSRFPolicy) *envoy_config_filter_http_csrf_v3.CsrfPolicy {
+	percent := func(numerator uint32) *envoy_core_v3.RuntimeFractionalPercent {
+		return &envoy_core_v3.RuntimeFractionalPercent{
+			DefaultValue: &envoy_type_v3.FractionalPercent{
+				Numerator:   numerator,
+				Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+			},
+		}
+	}
+
+	c := &envoy_config_filter_http_csrf_v3.CsrfPolicy{
+		FilterEnabled: percent(policy.EnforcementPercentage),
+	}
+	if policy.Shadow {
+		c.ShadowEnabled = percent(100)
+	}
+
+	for _, origin := range policy.AdditionalOrigins {
+		c.AdditionalOrigins = append(c.AdditionalOrigins, originMatcher(origin))
+	}
+
+	return c
+}
+
+// originMatcher returns a matcher.StringMatcher for the supplied
+// origin match.
+func originMatcher(origin dag.OriginMatch) *matcher.StringMatcher {
+	switch origin.MatchType {
+	case dag.OriginMatchTypeSuffix:
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Suffix{
+				Suffix: origin.Value,
+			},
+		}
+	case dag.OriginMatchTypeRegex:
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_SafeRegex{
+				SafeRegex: SafeRegexMatch(origin.Value),
+			},
+		}
+	default:
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Exact{
+				Exact: origin.Value,
+			},
+		}
+	}
+}
+
 // weightedClusters returns a route.WeightedCluster for multiple services.
 func weightedClusters(clusters []*dag.Cluster) *envoy_route_v3.WeightedCluster {
 	var wc envoy_route_v3.WeightedCluster
@@ -19,7 +19,10 @@ import (
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_config_filter_http_csrf_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/csrf/v3"
+	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/fixture"
@@ -27,6 +30,7 @@ import (
 	"github.com/projectcontour/contour/internal/timeout"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -1004,6 +1008,74 @@ func TestCORSPolicy(t *testing.T) {
 	}
 }
 
+func TestCSRFConfig(t *testing.T) {
+	percent := func(numerator uint32) *envoy_core_v3.RuntimeFractionalPercent {
+		return &envoy_core_v3.RuntimeFractionalPercent{
+			DefaultValue: &envoy_type_v3.FractionalPercent{
+				Numerator:   numerator,
+				Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		policy *dag.CSRFPolicy
+		want   *envoy_config_filter_http_csrf_v3.CsrfPolicy
+	}{
+		"enforced": {
+			policy: &dag.CSRFPolicy{
+				EnforcementPercentage: 100,
+			},
+			want: &envoy_config_filter_http_csrf_v3.CsrfPolicy{
+				FilterEnabled: percent(100),
+			},
+		},
+		"shadow": {
+			policy: &dag.CSRFPolicy{
+				EnforcementPercentage: 10,
+				Shadow:                true,
+			},
+			want: &envoy_config_filter_http_csrf_v3.CsrfPolicy{
+				FilterEnabled: percent(10),
+				ShadowEnabled: percent(100),
+			},
+		},
+		"additional origins": {
+			policy: &dag.CSRFPolicy{
+				EnforcementPercentage: 100,
+				AdditionalOrigins: []dag.OriginMatch{
+					{MatchType: dag.OriginMatchTypeExact, Value: "www.example.com"},
+					{MatchType: dag.OriginMatchTypeSuffix, Value: ".example.net"},
+					{MatchType: dag.OriginMatchTypeRegex, Value: "[a-z]+\\.example\\.org"},
+				},
+			},
+			want: &envoy_config_filter_http_csrf_v3.CsrfPolicy{
+				FilterEnabled: percent(100),
+				AdditionalOrigins: []*matcher.StringMatcher{{
+					MatchPattern: &matcher.StringMatcher_Exact{
+						Exact: "www.example.com",
+					},
+				}, {
+					MatchPattern: &matcher.StringMatcher_Suffix{
+						Suffix: ".example.net",
+					},
+				}, {
+					MatchPattern: &matcher.StringMatcher_SafeRegex{
+						SafeRegex: SafeRegexMatch("[a-z]+\\.example\\.org"),
+					},
+				}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := CSRFConfig(tc.policy)
+			protobuf.ExpectEqual(t, protobuf.MustMarshalAny(tc.want), got)
+		})
+	}
+}
+
 func TestUpgradeHTTPS(t *testing.T) {
 	got := UpgradeHTTPS()
 	want := &envoy_route_v3.Route_Redirect{
@@ -1180,3 +1252,26 @@ func TestRouteMatch(t *testing.T) {
 }
 
 func virtualhosts(v ...*envoy_route_v3.VirtualHost) []*envoy_route_v3.VirtualHost { return v }
+
+func TestRouteBasicAuth(t *testing.T) {
+	got := RouteBasicAuth(&dag.BasicAuth{
+		Realm:  "example.com",
+		Secret: types.NamespacedName{Namespace: "default", Name: "htpasswd"},
+		Users:  map[string]string{"alice": "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="},
+	})
+
+	want := protobuf.MustMarshalAny(
+		&envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute{
+			Override: &envoy_config_filter_http_ext_authz_v3.ExtAuthzPerRoute_CheckSettings{
+				CheckSettings: &envoy_config_filter_http_ext_authz_v3.CheckSettings{
+					ContextExtensions: map[string]string{
+						"basic_auth_secret": "default/htpasswd",
+						"basic_auth_realm":  "example.com",
+					},
+				},
+			},
+		},
+	)
+
+	protobuf.ExpectEqual(t, want, got)
+}
@@ -22,7 +22,7 @@ import (
 
 // Secret creates new envoy_tls_v3.Secret from secret.
 func Secret(s *dag.Secret) *envoy_tls_v3.Secret {
-	return &envoy_tls_v3.Secret{
+	secret := &envoy_tls_v3.Secret{
 		Name: envoy.Secretname(s),
 		Type: &envoy_tls_v3.Secret_TlsCertificate{
 			TlsCertificate: &envoy_tls_v3.TlsCertificate{
@@ -39,4 +39,36 @@ func Secret(s *dag.Secret) *envoy_tls_v3.Secret {
 			},
 		},
 	}
+
+	if staple := s.OCSPStaple(); len(staple) > 0 {
+		secret.GetTlsCertificate().OcspStaple = &envoy_core_v3.DataSource{
+			Specifier: &envoy_core_v3.DataSource_InlineBytes{
+				InlineBytes: staple,
+			},
+		}
+	}
+
+	return secret
+}
+
+// SessionTicketKeysSecret creates a new envoy_tls_v3.Secret that
+// holds the TLS session ticket keys of the supplied secret.
+func SessionTicketKeysSecret(s *dag.Secret) *envoy_tls_v3.Secret {
+	var keys []*envoy_core_v3.DataSource
+	for _, key := range s.SessionTicketKeys() {
+		keys = append(keys, &envoy_core_v3.DataSource{
+			Specifier: &envoy_core_v3.DataSource_InlineBytes{
+				InlineBytes: key,
+			},
+		})
+	}
+
+	return &envoy_tls_v3.Secret{
+		Name: envoy.SessionTicketKeysSecretname(s),
+		Type: &envoy_tls_v3.Secret_SessionTicketKeys{
+			SessionTicketKeys: &envoy_tls_v3.TlsSessionTicketKeys{
+				Keys: keys,
+			},
+		},
+	}
 }
@@ -27,3 +27,16 @@ func ParseTLSVersion(version string) envoy_tls_v3.TlsParameters_TlsProtocol {
 		return envoy_tls_v3.TlsParameters_TLS_AUTO
 	}
 }
+
+// ParseOCSPStaplePolicy returns the Envoy OCSP stapling policy for the
+// supplied policy name. Unknown names are treated as lenient stapling.
+func ParseOCSPStaplePolicy(policy string) envoy_tls_v3.DownstreamTlsContext_OcspStaplePolicy {
+	switch policy {
+	case "strict":
+		return envoy_tls_v3.DownstreamTlsContext_STRICT_STAPLING
+	case "must-staple":
+		return envoy_tls_v3.DownstreamTlsContext_MUST_STAPLE
+	default:
+		return envoy_tls_v3.DownstreamTlsContext_LENIENT_STAPLING
+	}
+}
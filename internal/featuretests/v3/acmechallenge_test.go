@@ -0,0 +1,354 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	networking_v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestACMEChallenge(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+	rh.OnAdd(fixture.NewService("solver").
+		WithPorts(v1.ServicePort{Name: "http", Port: 8089}))
+
+	// The TLS secret has not been issued yet.
+	p1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "secret",
+					ACMEChallenge: &contour_api_v1.ACMEChallenge{
+						ServiceName: "solver",
+						Port:        8089,
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(p1)
+
+	// Challenges are routed to the solver even though the
+	// HTTPProxy is invalid.
+	c.Request(routeType, "ingress_http").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/.well-known/acme-challenge/"),
+						Action: routeCluster("default/solver/8089/da39a3ee5e"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p1).HasError(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+		`Spec.VirtualHost.TLS Secret "secret" is invalid: Secret not found`)
+
+	sec1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec1)
+
+	// Once the certificate has been issued, challenges are still
+	// served over plain HTTP and everything else is upgraded.
+	c.Request(routeType, "ingress_http").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/.well-known/acme-challenge/"),
+						Action: routeCluster("default/solver/8089/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: envoy_v3.UpgradeHTTPS(),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p1).IsValid()
+
+	// Without a solver Service, challenges are routed according
+	// to cert-manager's solver Ingresses, whatever their class.
+	p2 := p1.DeepCopy()
+	p2.Spec.VirtualHost.TLS.ACMEChallenge = &contour_api_v1.ACMEChallenge{}
+	rh.OnUpdate(p1, p2)
+
+	solverIngress := func(namespace string) *networking_v1.Ingress {
+		return &networking_v1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cm-acme-http-solver-abcde",
+				Namespace: namespace,
+				Labels: map[string]string{
+					"acme.cert-manager.io/http01-solver": "true",
+				},
+				Annotations: map[string]string{
+					"kubernetes.io/ingress.class": "nginx",
+				},
+			},
+			Spec: networking_v1.IngressSpec{
+				Rules: []networking_v1.IngressRule{{
+					Host: "www.example.com",
+					IngressRuleValue: networking_v1.IngressRuleValue{
+						HTTP: &networking_v1.HTTPIngressRuleValue{
+							Paths: []networking_v1.HTTPIngressPath{{
+								Path: "/.well-known/acme-challenge/token",
+								Backend: networking_v1.IngressBackend{
+									Service: &networking_v1.IngressServiceBackend{
+										Name: "solver",
+										Port: networking_v1.ServiceBackendPort{Number: 8089},
+									},
+								},
+							}, {
+								// Solver Ingresses may only route challenges.
+								Path: "/",
+								Backend: networking_v1.IngressBackend{
+									Service: &networking_v1.IngressServiceBackend{
+										Name: "solver",
+										Port: networking_v1.ServiceBackendPort{Number: 8089},
+									},
+								},
+							}},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	// Solver Ingresses in other namespaces are ignored.
+	rh.OnAdd(solverIngress("other"))
+
+	c.Request(routeType, "ingress_http").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: envoy_v3.UpgradeHTTPS(),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p2).IsValid()
+
+	rh.OnAdd(solverIngress("default"))
+
+	c.Request(routeType, "ingress_http").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match: envoy_v3.RouteMatch(&dag.Route{
+							PathMatchCondition: &dag.ExactMatchCondition{Path: "/.well-known/acme-challenge/token"},
+						}),
+						Action: routeCluster("default/solver/8089/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: envoy_v3.UpgradeHTTPS(),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p2).IsValid()
+
+	// Removing the solver Ingress removes the challenge route.
+	rh.OnDelete(solverIngress("default"))
+
+	c.Request(routeType, "ingress_http").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: envoy_v3.UpgradeHTTPS(),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+}
+
+func TestACMEChallengeWithRoutePriority(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+	rh.OnAdd(fixture.NewService("solver").
+		WithPorts(v1.ServicePort{Name: "http", Port: 8089}))
+	rh.OnAdd(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	})
+
+	p1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "secret",
+					ACMEChallenge: &contour_api_v1.ACMEChallenge{
+						ServiceName: "solver",
+						Port:        8089,
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Priority: 10,
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(p1)
+
+	// Challenges are not upgraded to HTTPS by the prioritised route.
+	c.Request(routeType, "ingress_http").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/.well-known/acme-challenge/"),
+						Action: routeCluster("default/solver/8089/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: envoy_v3.UpgradeHTTPS(),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p1).IsValid()
+}
+
+func TestACMEChallengeInvalidHTTPProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+	rh.OnAdd(fixture.NewService("solver").
+		WithPorts(v1.ServicePort{Name: "http", Port: 8089}))
+	rh.OnAdd(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	})
+
+	p1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "secret",
+					ACMEChallenge: &contour_api_v1.ACMEChallenge{
+						ServiceName: "solver",
+						Port:        8089,
+					},
+				},
+				IPFilterPolicy: &contour_api_v1.IPFilterPolicy{},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(p1)
+
+	// Challenges are not routed for HTTPProxies that are
+	// invalid for reasons other than their certificate.
+	c.Request(routeType, "ingress_http").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(p1).HasError(contour_api_v1.ConditionTypeIPFilterError, "IPFilterPolicyNotValid",
+		"Spec.VirtualHost.IPFilterPolicy is invalid: at least one allow or deny entry must be specified")
+
+	p2 := p1.DeepCopy()
+	p2.Spec.VirtualHost.IPFilterPolicy = nil
+	rh.OnUpdate(p1, p2)
+
+	p3 := p2.DeepCopy()
+	p3.Name = "other"
+	rh.OnAdd(p3)
+
+	// Nor are they routed for an fqdn that is claimed by
+	// more than one HTTPProxy.
+	c.Request(routeType, "ingress_http").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(p2).HasError(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateVhost",
+		`fqdn "www.example.com" is used in multiple HTTPProxies: default/other, default/simple`)
+}
@@ -23,15 +23,21 @@ import (
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_config_filter_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/golang/protobuf/ptypes/any"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/featuretests"
 	"github.com/projectcontour/contour/internal/fixture"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
 )
 
 const defaultResponseTimeout = time.Minute * 60
@@ -224,6 +230,40 @@ func authzFallbackIncompat(t *testing.T, rh cache.ResourceEventHandler, c *Conto
 	}).Status(p).HasError(contour_api_v1.ConditionTypeTLSError, "TLSIncompatibleFeatures", "Spec.Virtualhost.TLS fallback & client authorization are incompatible")
 }
 
+func authzBasicAuthIncompat(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	rh.OnAdd(&corev1.Secret{
+		ObjectMeta: fixture.ObjectMeta("htpasswd"),
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			dag.BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n"),
+		},
+	})
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN("echo.projectcontour.io").
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services:  []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				BasicAuth: &contour_api_v1.BasicAuth{SecretName: "htpasswd"},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   listenerType,
+		Resources: resources(t, staticListener()),
+	}).Status(p).HasError(contour_api_v1.ConditionTypeBasicAuthError, "BasicAuthNotPermitted",
+		"route cannot require basic authentication when the virtual host has an authorization server")
+}
+
 func authzOverrideDisabled(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
 	const enabled = "enabled.projectcontour.io"
 	const disabled = "disabled.projectcontour.io"
@@ -513,15 +553,419 @@ func authzInvalidReference(t *testing.T, rh cache.ResourceEventHandler, c *Conto
 	}).Status(invalid).IsValid()
 }
 
+func authzWithRequestBody(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "body.projectcontour.io"
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+			WithRequestBody: &contour_api_v1.AuthorizationServerBufferSettings{
+				MaxRequestBytes:     8192,
+				AllowPartialMessage: true,
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn,
+						&corev1.Secret{
+							ObjectMeta: fixture.ObjectMeta("certificate"),
+							Type:       "kubernetes.io/tls",
+							Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+						},
+						authzFilterFor(
+							fqdn,
+							&envoy_config_filter_http_ext_authz_v3.ExtAuthz{
+								Services:               grpcCluster("extension/auth/extension"),
+								ClearRouteCache:        true,
+								IncludePeerCertificate: true,
+								StatusOnError: &envoy_type.HttpStatus{
+									Code: envoy_type.StatusCode_Forbidden,
+								},
+								WithRequestBody: &envoy_config_filter_http_ext_authz_v3.BufferSettings{
+									MaxRequestBytes:     8192,
+									AllowPartialMessage: true,
+								},
+								TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+							},
+						),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	}).Status(p).IsValid()
+}
+
+func authzRouteOverride(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "webhooks.projectcontour.io"
+
+	rh.OnAdd(fixture.NewService("auth/signature-server").
+		WithPorts(corev1.ServicePort{Port: 8081}))
+
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("auth/signature"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "signature-server", Port: 8081},
+			},
+		},
+	})
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/webhooks")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				Authorization: &contour_api_v1.AuthorizationServer{
+					ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+						Namespace: "auth",
+						Name:      "signature",
+					},
+					ResponseTimeout: "5s",
+					FailOpen:        true,
+					WithRequestBody: &contour_api_v1.AuthorizationServerBufferSettings{
+						MaxRequestBytes: 65536,
+						PackAsBytes:     true,
+					},
+				},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	vhostAuth := &dag.ExternalAuthorization{
+		AuthorizationService:         &dag.ExtensionCluster{Name: "extension/auth/extension"},
+		AuthorizationResponseTimeout: timeout.DurationSetting(defaultResponseTimeout),
+	}
+	routeAuth := &dag.ExternalAuthorization{
+		AuthorizationService:         &dag.ExtensionCluster{Name: "extension/auth/signature"},
+		AuthorizationResponseTimeout: timeout.DurationSetting(5 * time.Second),
+		AuthorizationFailOpen:        true,
+		AuthorizationServerWithRequestBody: &dag.AuthorizationServerBufferSettings{
+			MaxRequestBytes: 65536,
+			PackAsBytes:     true,
+		},
+	}
+
+	// Each server has its own filter, which only authorizes the
+	// requests that select it.
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn,
+						&corev1.Secret{
+							ObjectMeta: fixture.ObjectMeta("certificate"),
+							Type:       "kubernetes.io/tls",
+							Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+						},
+						envoy_v3.HTTPConnectionManagerBuilder().
+							AddFilter(envoy_v3.FilterMisdirectedRequests(fqdn)).
+							DefaultFilters().
+							AddFilter(envoy_v3.FilterAuthzServerSelector()).
+							AddFilter(envoy_v3.FilterExternalAuthzSelected(vhostAuth)).
+							AddFilter(envoy_v3.FilterExternalAuthzSelected(routeAuth)).
+							RouteConfigName(path.Join("https", fqdn)).
+							MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+							AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+							Get(),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	}).Status(p).IsValid()
+
+	vhost := envoy_v3.VirtualHost(fqdn,
+		&envoy_route_v3.Route{
+			Match:                routePrefix("/webhooks"),
+			Action:               routeCluster("default/app-server/80/da39a3ee5e"),
+			TypedPerFilterConfig: map[string]*any.Any{"envoy.filters.http.header_to_metadata": envoy_v3.RouteAuthzServer(routeAuth)},
+		},
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/app-server/80/da39a3ee5e"),
+		},
+	)
+	vhost.TypedPerFilterConfig = map[string]*any.Any{
+		"envoy.filters.http.header_to_metadata": envoy_v3.RouteAuthzServer(vhostAuth),
+	}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration(path.Join("https", fqdn), vhost),
+			envoy_v3.RouteConfiguration(
+				"ingress_http",
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/webhooks"),
+						Action: withRedirect(),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: withRedirect(),
+					},
+				),
+			),
+		),
+	})
+}
+
+func authzInvalidRouteOverride(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "webhooks.projectcontour.io"
+
+	route := contour_api_v1.Route{
+		Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+		Authorization: &contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "missing",
+			},
+		},
+	}
+
+	invalid := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "extension",
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{route},
+		})
+
+	rh.OnAdd(invalid)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   routeType,
+		Resources: resources(t, envoy_v3.RouteConfiguration("ingress_http")),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeAuthError, "AuthorizationServerNotValid",
+		`route.authorization is invalid: extension service "auth/missing" not found`)
+
+	// The route can only override an authorization server
+	// that the virtual host configures.
+	route.Authorization.ExtensionServiceRef.Name = "extension"
+	unauthorized := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{route},
+		})
+
+	rh.OnUpdate(invalid, unauthorized)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   routeType,
+		Resources: resources(t, envoy_v3.RouteConfiguration("ingress_http")),
+	}).Status(unauthorized).HasError(contour_api_v1.ConditionTypeAuthError, "AuthorizationServerNotPermitted",
+		"route.authorization requires the root HTTPProxy to configure Spec.VirtualHost.Authorization")
+}
+
+func authzHTTPService(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "http.projectcontour.io"
+
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("auth/gateway"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Protocol: pointer.StringPtr("http/1.1"),
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "oidc-server", Port: 8081},
+			},
+			HTTPAuthorization: &v1alpha1.HTTPAuthorizationService{
+				PathPrefix: "/verify",
+				HeadersToAdd: []contour_api_v1.HeaderValue{{
+					Name:  "X-Auth-Source",
+					Value: "contour",
+				}},
+				AllowedUpstreamHeaders:   []string{"X-User"},
+				AllowedDownstreamHeaders: []string{"Location", "Set-Cookie"},
+			},
+		},
+	})
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "gateway",
+			},
+			ResponseTimeout: "1s",
+			AllowedHeaders:  []string{"Cookie", "Authorization"},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	exact := func(names ...string) *matcher.ListStringMatcher {
+		l := &matcher.ListStringMatcher{}
+		for _, n := range names {
+			l.Patterns = append(l.Patterns, &matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_Exact{Exact: n},
+			})
+		}
+		return l
+	}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn,
+						&corev1.Secret{
+							ObjectMeta: fixture.ObjectMeta("certificate"),
+							Type:       "kubernetes.io/tls",
+							Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+						},
+						authzFilterFor(
+							fqdn,
+							&envoy_config_filter_http_ext_authz_v3.ExtAuthz{
+								Services: &envoy_config_filter_http_ext_authz_v3.ExtAuthz_HttpService{
+									HttpService: &envoy_config_filter_http_ext_authz_v3.HttpService{
+										ServerUri: &envoy_core_v3.HttpUri{
+											Uri: "http://extension/auth/gateway",
+											HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{
+												Cluster: "extension/auth/gateway",
+											},
+											Timeout: protobuf.Duration(time.Second),
+										},
+										PathPrefix: "/verify",
+										AuthorizationRequest: &envoy_config_filter_http_ext_authz_v3.AuthorizationRequest{
+											AllowedHeaders: exact("cookie", "authorization"),
+											HeadersToAdd: []*envoy_core_v3.HeaderValue{{
+												Key:   "x-auth-source",
+												Value: "contour",
+											}},
+										},
+										AuthorizationResponse: &envoy_config_filter_http_ext_authz_v3.AuthorizationResponse{
+											AllowedUpstreamHeaders: exact("x-user"),
+											AllowedClientHeaders:   exact("location", "set-cookie"),
+										},
+									},
+								},
+								ClearRouteCache:        true,
+								IncludePeerCertificate: true,
+								StatusOnError: &envoy_type.HttpStatus{
+									Code: envoy_type.StatusCode_Forbidden,
+								},
+								TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+							},
+						),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	}).Status(p).IsValid()
+}
+
+func authzGRPCProtocol(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "grpc.projectcontour.io"
+
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("auth/http1"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Protocol: pointer.StringPtr("http/1.1"),
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "oidc-server", Port: 8081},
+			},
+		},
+	})
+
+	invalid := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithAuthServer(contour_api_v1.AuthorizationServer{
+			ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+				Namespace: "auth",
+				Name:      "http1",
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+
+	rh.OnAdd(invalid)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   listenerType,
+		Resources: resources(t, staticListener()),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeAuthError, "AuthorizationServerNotValid",
+		`Spec.Virtualhost.Authorization is invalid: extension service "auth/http1": GRPC authorization requires the h2 or h2c protocol, not "http/1.1"`)
+}
+
 func TestAuthorization(t *testing.T) {
 	subtests := map[string]func(*testing.T, cache.ResourceEventHandler, *Contour){
 		"MissingExtension":       authzInvalidReference,
 		"MergeRouteContext":      authzMergeRouteContext,
 		"OverrideDisabled":       authzOverrideDisabled,
 		"FallbackIncompat":       authzFallbackIncompat,
+		"BasicAuthIncompat":      authzBasicAuthIncompat,
 		"FailOpen":               authzFailOpen,
 		"ResponseTimeout":        authzResponseTimeout,
 		"InvalidResponseTimeout": authzInvalidResponseTimeout,
+		"WithRequestBody":        authzWithRequestBody,
+		"RouteOverride":          authzRouteOverride,
+		"InvalidRouteOverride":   authzInvalidRouteOverride,
+		"HTTPService":            authzHTTPService,
+		"GRPCProtocol":           authzGRPCProtocol,
 	}
 
 	for n, f := range subtests {
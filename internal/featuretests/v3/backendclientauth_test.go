@@ -50,6 +50,7 @@ func proxyClientCertificateOpt(t *testing.T) func(eh *contour.EventHandler) {
 				FieldLogger:       log.WithField("context", "IngressProcessor"),
 			},
 			&dag.HTTPProxyProcessor{
+				Clock:             fixture.Clock,
 				ClientCertificate: &secret,
 			},
 			&dag.ExtensionServiceProcessor{
@@ -0,0 +1,166 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"path"
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestBasicAuth(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	const fqdn = "dashboard.projectcontour.io"
+
+	rh.OnAdd(fixture.NewService("app-server").
+		WithPorts(corev1.ServicePort{Port: 80}))
+
+	sec := &corev1.Secret{
+		ObjectMeta: fixture.ObjectMeta("certificate"),
+		Type:       "kubernetes.io/tls",
+		Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec)
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithBasicAuth(contour_api_v1.BasicAuth{SecretName: "htpasswd"}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/public")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				BasicAuth:  &contour_api_v1.BasicAuth{Disabled: true},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/admin")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				BasicAuth:  &contour_api_v1.BasicAuth{Realm: "admin"},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+	rh.OnAdd(p)
+
+	// The proxy is invalid until its Secret is present.
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+	}).Status(p).HasError(contour_api_v1.ConditionTypeBasicAuthError, "SecretNotValid",
+		`Spec.VirtualHost.BasicAuth Secret "htpasswd" is invalid: Secret not found`)
+
+	// Adding the Secret triggers a rebuild.
+	rh.OnAdd(&corev1.Secret{
+		ObjectMeta: fixture.ObjectMeta("htpasswd"),
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			dag.BasicAuthKey: []byte("alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\nbob:$apr1$xxYYzz12$5fkORe2Wk90T9mxxflF9k0\n"),
+		},
+	})
+
+	secret := types.NamespacedName{Namespace: "default", Name: "htpasswd"}
+
+	// Only the Secret name and realm are passed to the
+	// authorization service, not the password hashes.
+	vhost := envoy_v3.VirtualHost(fqdn,
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/public"),
+			Action: routeCluster("default/app-server/80/da39a3ee5e"),
+		},
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/admin"),
+			Action: routeCluster("default/app-server/80/da39a3ee5e"),
+			TypedPerFilterConfig: map[string]*any.Any{
+				"envoy.filters.http.ext_authz": envoy_v3.RouteBasicAuth(&dag.BasicAuth{Realm: "admin", Secret: secret}),
+			},
+		},
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/app-server/80/da39a3ee5e"),
+			TypedPerFilterConfig: map[string]*any.Any{
+				"envoy.filters.http.ext_authz": envoy_v3.RouteBasicAuth(&dag.BasicAuth{Realm: fqdn, Secret: secret}),
+			},
+		},
+	)
+	vhost.TypedPerFilterConfig = map[string]*any.Any{
+		"envoy.filters.http.ext_authz": envoy_v3.RouteAuthzDisabled(),
+	}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration(path.Join("https", fqdn), vhost),
+			envoy_v3.RouteConfiguration(
+				"ingress_http",
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/public"),
+						Action: withRedirect(),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/admin"),
+						Action: withRedirect(),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: withRedirect(),
+					},
+				),
+			),
+		),
+	}).Status(p).IsValid()
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn, sec,
+						envoy_v3.HTTPConnectionManagerBuilder().
+							AddFilter(envoy_v3.FilterMisdirectedRequests(fqdn)).
+							DefaultFilters().
+							AddFilter(envoy_v3.FilterBasicAuth()).
+							RouteConfigName(path.Join("https", fqdn)).
+							MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+							AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+							Get(),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCSRFPolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("app-server").
+		WithPorts(corev1.ServicePort{Port: 80}))
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN("app.projectcontour.io").
+		WithCSRFPolicy(contour_api_v1.CSRFPolicy{
+			AdditionalOrigins: []contour_api_v1.CSRFOriginMatch{
+				{Suffix: ".projectcontour.io"},
+			},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/api")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				CSRFPolicy: &contour_api_v1.CSRFPolicy{
+					Shadow: true,
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+	rh.OnAdd(p)
+
+	csrf := func(policy *dag.CSRFPolicy) map[string]*any.Any {
+		return map[string]*any.Any{
+			"envoy.filters.http.csrf": envoy_v3.CSRFConfig(policy),
+		}
+	}
+
+	vhost := envoy_v3.VirtualHost("app.projectcontour.io",
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/api"),
+			Action: routeCluster("default/app-server/80/da39a3ee5e"),
+			TypedPerFilterConfig: csrf(&dag.CSRFPolicy{
+				Shadow: true,
+			}),
+		},
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/app-server/80/da39a3ee5e"),
+		},
+	)
+	vhost.TypedPerFilterConfig = csrf(&dag.CSRFPolicy{
+		EnforcementPercentage: 100,
+		AdditionalOrigins: []dag.OriginMatch{
+			{MatchType: dag.OriginMatchTypeSuffix, Value: ".projectcontour.io"},
+		},
+	})
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http", vhost),
+		),
+	}).Status(p).IsValid()
+
+	// An invalid route policy is rejected.
+	invalid := fixture.NewProxy("proxy").
+		WithFQDN("app.projectcontour.io").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				CSRFPolicy: &contour_api_v1.CSRFPolicy{
+					AdditionalOrigins: []contour_api_v1.CSRFOriginMatch{{}},
+				},
+			}},
+		})
+	rh.OnUpdate(p, invalid)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("ingress_http"),
+		),
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeRouteError, "CSRFPolicyNotValid",
+		"route.csrfPolicy is invalid: additional origin 0: exactly one of exact, suffix or regex must be specified")
+}
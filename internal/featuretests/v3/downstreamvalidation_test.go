@@ -152,4 +152,157 @@ func TestDownstreamTLSCertificateValidation(t *testing.T) {
 		),
 		TypeUrl: listenerType,
 	}).Status(proxy).IsValid()
+
+	optional := fixture.NewProxy("example.com").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: serverTLSSecret.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate:             clientCASecret.Name,
+						OptionalClientCertificate: true,
+						ForwardClientCertificate: &contour_api_v1.ForwardClientCertificate{
+							Subject: true,
+							URI:     true,
+						},
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnUpdate(proxy, optional)
+
+	ingressHTTPSOptional := &envoy_listener_v3.Listener{
+		Name:    "ingress_https",
+		Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+		ListenerFilters: envoy_v3.ListenerFilters(
+			envoy_v3.TLSInspector(),
+		),
+		FilterChains: appendFilterChains(
+			filterchaintls("example.com", serverTLSSecret,
+				envoy_v3.HTTPConnectionManagerBuilder().
+					AddFilter(envoy_v3.FilterMisdirectedRequests("example.com")).
+					DefaultFilters().
+					ForwardClientCertificate(&dag.ForwardClientCertificate{
+						Mode:    dag.ForwardClientCertificateSanitizeSet,
+						Subject: true,
+						URI:     true,
+					}).
+					RouteConfigName("https/example.com").
+					MetricsPrefix("ingress_https").
+					AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+					Get(),
+				&dag.PeerValidationContext{
+					CACertificate: &dag.Secret{
+						Object: clientCASecret,
+					},
+					OptionalClientCertificate: true,
+				},
+				"h2", "http/1.1",
+			),
+		),
+		SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+	}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			defaultHTTPListener(),
+			ingressHTTPSOptional,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(optional).IsValid()
+
+	invalid := optional.DeepCopy()
+	invalid.Spec.VirtualHost.TLS.ClientValidation.ForwardClientCertificate.Mode = "Forward"
+	rh.OnUpdate(optional, invalid)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(invalid).HasError(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+		`Spec.VirtualHost.TLS client validation is invalid: client certificate details cannot be added in "Forward" mode`)
+
+	crlConfigMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "crl",
+			Namespace: "default",
+		},
+		Data: map[string]string{
+			dag.CRLKey: fixture.CRL,
+		},
+	}
+	rh.OnAdd(crlConfigMap)
+
+	withCRL := fixture.NewProxy("example.com").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: serverTLSSecret.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: clientCASecret.Name,
+						CertificateRevocationList: &contour_api_v1.CertificateRevocationList{
+							ConfigMapName: crlConfigMap.Name,
+						},
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnUpdate(invalid, withCRL)
+
+	ingressHTTPSCRL := &envoy_listener_v3.Listener{
+		Name:    "ingress_https",
+		Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+		ListenerFilters: envoy_v3.ListenerFilters(
+			envoy_v3.TLSInspector(),
+		),
+		FilterChains: appendFilterChains(
+			filterchaintls("example.com", serverTLSSecret,
+				httpsFilterFor("example.com"),
+				&dag.PeerValidationContext{
+					CACertificate: &dag.Secret{
+						Object: clientCASecret,
+					},
+					CRL: []byte(fixture.CRL),
+				},
+				"h2", "http/1.1",
+			),
+		),
+		SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+	}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			defaultHTTPListener(),
+			ingressHTTPSCRL,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(withCRL).IsValid()
+
+	// Removing the CRL ConfigMap invalidates the client validation.
+	rh.OnDelete(crlConfigMap)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(withCRL).HasError(contour_api_v1.ConditionTypeTLSError, "ClientValidationInvalid",
+		`Spec.VirtualHost.TLS client validation is invalid: crl is invalid: ConfigMap "crl" is invalid: ConfigMap not found`)
 }
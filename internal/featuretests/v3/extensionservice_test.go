@@ -97,6 +97,48 @@ func extCleartext(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
 	})
 }
 
+func extHTTP1(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("ns/ext"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Protocol: pointer.StringPtr("http/1.1"),
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "svc1", Port: 8081},
+			},
+			HTTPAuthorization: &v1alpha1.HTTPAuthorizationService{
+				PathPrefix: "/auth",
+			},
+		},
+	})
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			cluster("extension/ns/ext", "extension/ns/ext", "extension_ns_ext"),
+		),
+	})
+}
+
+func extInvalidHTTPAuthorization(_ *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("ns/ext"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Protocol: pointer.StringPtr("http/1.1"),
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "svc1", Port: 8081},
+			},
+			HTTPAuthorization: &v1alpha1.HTTPAuthorizationService{
+				AllowedUpstreamHeaders: []string{"x user"},
+			},
+		},
+	})
+
+	// Should have no clusters because the allowed header is invalid.
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+	})
+}
+
 func extUpstreamValidation(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
 	ext := &v1alpha1.ExtensionService{
 		ObjectMeta: fixture.ObjectMeta("ns/ext"),
@@ -167,6 +209,63 @@ func extUpstreamValidation(t *testing.T, rh cache.ResourceEventHandler, c *Conto
 	})
 }
 
+func extUpstreamValidationSubjectNames(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: fixture.ObjectMeta("ns/ext"),
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []v1alpha1.ExtensionServiceTarget{
+				{Name: "svc1", Port: 8081},
+			},
+			UpstreamValidation: &contour_api_v1.UpstreamValidation{
+				CACertificate: "cacert",
+				SubjectNames: []contour_api_v1.SubjectNameMatch{
+					{Prefix: "spiffe://cluster.local/"},
+					{Exact: "ext.projectcontour.io"},
+				},
+			},
+		},
+	})
+
+	// Without a subject name, the SNI is the first exact
+	// name of the subject names.
+	tlsSocket := envoy_v3.UpstreamTLSTransportSocket(
+		&envoy_v3_tls.UpstreamTlsContext{
+			Sni: "ext.projectcontour.io",
+			CommonTlsContext: &envoy_v3_tls.CommonTlsContext{
+				AlpnProtocols: []string{"h2"},
+				ValidationContextType: &envoy_v3_tls.CommonTlsContext_ValidationContext{
+					ValidationContext: &envoy_v3_tls.CertificateValidationContext{
+						TrustedCa: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_InlineBytes{
+								InlineBytes: []byte(featuretests.CERTIFICATE),
+							},
+						},
+						MatchSubjectAltNames: []*matcher.StringMatcher{{
+							MatchPattern: &matcher.StringMatcher_Prefix{
+								Prefix: "spiffe://cluster.local/",
+							},
+						}, {
+							MatchPattern: &matcher.StringMatcher_Exact{
+								Exact: "ext.projectcontour.io",
+							},
+						}},
+					},
+				},
+			},
+		},
+	)
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			DefaultCluster(
+				h2cCluster(cluster("extension/ns/ext", "extension/ns/ext", "extension_ns_ext")),
+				&envoy_cluster_v3.Cluster{TransportSocket: tlsSocket},
+			),
+		),
+	})
+}
+
 func extExternalName(_ *testing.T, rh cache.ResourceEventHandler, c *Contour) {
 	rh.OnAdd(fixture.NewService("ns/external").
 		WithSpec(corev1.ServiceSpec{
@@ -322,14 +421,17 @@ func extInvalidLoadBalancerPolicy(t *testing.T, rh cache.ResourceEventHandler, c
 
 func TestExtensionService(t *testing.T) {
 	subtests := map[string]func(*testing.T, cache.ResourceEventHandler, *Contour){
-		"Basic":                     extBasic,
-		"Cleartext":                 extCleartext,
-		"UpstreamValidation":        extUpstreamValidation,
-		"ExternalName":              extExternalName,
-		"MissingService":            extMissingService,
-		"InconsistentProto":         extInconsistentProto,
-		"InvalidTimeout":            extInvalidTimeout,
-		"InvalidLoadBalancerPolicy": extInvalidLoadBalancerPolicy,
+		"Basic":                          extBasic,
+		"Cleartext":                      extCleartext,
+		"HTTP1":                          extHTTP1,
+		"InvalidHTTPAuthorization":       extInvalidHTTPAuthorization,
+		"UpstreamValidation":             extUpstreamValidation,
+		"UpstreamValidationSubjectNames": extUpstreamValidationSubjectNames,
+		"ExternalName":                   extExternalName,
+		"MissingService":                 extMissingService,
+		"InconsistentProto":              extInconsistentProto,
+		"InvalidTimeout":                 extInvalidTimeout,
+		"InvalidLoadBalancerPolicy":      extInvalidLoadBalancerPolicy,
 	}
 
 	for n, f := range subtests {
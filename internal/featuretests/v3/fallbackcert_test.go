@@ -36,6 +36,7 @@ func TestFallbackCertificate(t *testing.T) {
 		eh.Builder.Processors = []dag.Processor{
 			&dag.IngressProcessor{},
 			&dag.HTTPProxyProcessor{
+				Clock: fixture.Clock,
 				FallbackCertificate: &types.NamespacedName{
 					Name:      "fallbacksecret",
 					Namespace: "admin",
@@ -83,10 +83,17 @@ func setup(t *testing.T, opts ...interface{}) (cache.ResourceEventHandler, *Cont
 		}
 	}
 
+	routes := &xdscache_v3.RouteCache{}
+	for _, opt := range opts {
+		if opt, ok := opt.(func(*xdscache_v3.RouteCache)); ok {
+			opt(routes)
+		}
+	}
+
 	resources := []xdscache.ResourceCache{
 		xdscache_v3.NewListenerCache(conf, statsAddress, statsPort),
 		&xdscache_v3.SecretCache{},
-		&xdscache_v3.RouteCache{},
+		routes,
 		&xdscache_v3.ClusterCache{},
 		et,
 	}
@@ -127,7 +134,9 @@ func setup(t *testing.T, opts ...interface{}) (cache.ResourceEventHandler, *Cont
 		&dag.ExtensionServiceProcessor{
 			FieldLogger: log.WithField("context", "ExtensionServiceProcessor"),
 		},
-		&dag.HTTPProxyProcessor{},
+		&dag.HTTPProxyProcessor{
+			Clock: fixture.Clock,
+		},
 		&dag.GatewayAPIProcessor{
 			FieldLogger: log.WithField("context", "GatewayAPIProcessor"),
 		},
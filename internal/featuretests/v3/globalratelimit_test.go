@@ -671,6 +671,7 @@ func TestGlobalRateLimiting(t *testing.T) {
 				func(eh *contour.EventHandler) {
 					eh.Builder.Processors = []dag.Processor{
 						&dag.HTTPProxyProcessor{
+							Clock: fixture.Clock,
 							FallbackCertificate: &types.NamespacedName{
 								Name:      "fallback-cert",
 								Namespace: "default",
@@ -0,0 +1,139 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHSTSPolicy(t *testing.T) {
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.Processors = []dag.Processor{
+			&dag.IngressProcessor{},
+			&dag.HTTPProxyProcessor{
+				Clock: fixture.Clock,
+				HSTSPolicy: &dag.HSTSPolicy{
+					MaxAge: 24 * time.Hour,
+				},
+			},
+			&dag.ListenerProcessor{},
+		}
+	})
+	defer done()
+
+	rh.OnAdd(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	})
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+
+	// The global policy applies to virtual hosts that terminate
+	// TLS, and is not added to responses served over HTTP.
+	p1 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: "secret",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				PermitInsecure: true,
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(p1)
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("https/example.com",
+				hstsVirtualHost("max-age=86400"),
+			),
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/backend/80/da39a3ee5e"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p1).IsValid()
+
+	// The virtual host policy overrides the global policy.
+	p2 := p1.DeepCopy()
+	p2.Spec.VirtualHost.HSTSPolicy = &contour_api_v1.HSTSPolicy{
+		MaxAge:            "8760h",
+		IncludeSubDomains: true,
+		Preload:           true,
+	}
+	rh.OnUpdate(p1, p2)
+
+	c.Request(routeType, "https/example.com").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("https/example.com",
+				hstsVirtualHost("max-age=31536000; includeSubDomains; preload"),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p2).IsValid()
+
+	// Invalid policies are reported in the status.
+	p3 := p2.DeepCopy()
+	p3.Spec.VirtualHost.HSTSPolicy.MaxAge = "-1s"
+	rh.OnUpdate(p2, p3)
+
+	c.Request(routeType, "https/example.com").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			&envoy_route_v3.RouteConfiguration{Name: "https/example.com"},
+		),
+		TypeUrl: routeType,
+	}).Status(p3).HasError(contour_api_v1.ConditionTypeHSTSError, "HSTSPolicyNotValid",
+		`Spec.VirtualHost.HSTSPolicy is invalid: invalid maxAge "-1s": must not be negative`)
+}
+
+func hstsVirtualHost(value string) *envoy_route_v3.VirtualHost {
+	vh := envoy_v3.VirtualHost("example.com",
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/backend/80/da39a3ee5e"),
+		},
+	)
+	vh.ResponseHeadersToAdd = envoy_v3.HeaderValueList(map[string]string{
+		"Strict-Transport-Security": value,
+	}, false)
+	return vh
+}
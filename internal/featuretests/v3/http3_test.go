@@ -0,0 +1,200 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"path"
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHTTP3(t *testing.T) {
+	rh, c, done := setup(t,
+		func(conf *xdscache_v3.ListenerConfig) {
+			conf.HTTP3 = true
+		},
+		func(routes *xdscache_v3.RouteCache) {
+			routes.HTTP3AdvertisedPort = 443
+		},
+	)
+	defer done()
+
+	sec1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec1)
+
+	clientCASecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "client-ca",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			dag.CACertificateKey: []byte(featuretests.CERTIFICATE),
+		},
+	}
+	rh.OnAdd(clientCASecret)
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+
+	p1 := fixture.NewProxy("simple").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(p1)
+
+	// Client certificates are not validated over QUIC, so this
+	// virtual host is not served over HTTP/3.
+	p2 := fixture.NewProxy("client-validation").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "secure.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+					ClientValidation: &contour_api_v1.DownstreamValidation{
+						CACertificate: clientCASecret.Name,
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(p2)
+
+	// QUIC requires TLS 1.3, so neither is this virtual host.
+	p3 := fixture.NewProxy("tls-1-2").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "legacy.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:             sec1.Name,
+					MaximumProtocolVersion: "1.2",
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(p3)
+
+	http3 := envoy_v3.QUICListener("ingress_http3", "0.0.0.0", 8443)
+	http3.FilterChains = []*envoy_listener_v3.FilterChain{
+		envoy_v3.FilterChainQUIC("www.example.com",
+			envoy_v3.DownstreamTLSContext(
+				[]*dag.Secret{{Object: sec1}},
+				envoy_tls_v3.TlsParameters_TLSv1_2,
+				envoy_tls_v3.TlsParameters_TLSv1_3,
+				nil,
+				nil),
+			envoy_v3.Filters(
+				envoy_v3.HTTPConnectionManagerBuilder().
+					Codec(envoy_v3.HTTPVersion3).
+					AddFilter(envoy_v3.FilterMisdirectedRequests("www.example.com")).
+					DefaultFilters().
+					RouteConfigName(path.Join("https", "www.example.com")).
+					MetricsPrefix(xdscache_v3.ENVOY_HTTP3_LISTENER).
+					AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+					Get(),
+			),
+		),
+	}
+
+	c.Request(listenerType, "ingress_http3").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			http3,
+		),
+		TypeUrl: listenerType,
+	}).Status(p1).IsValid()
+
+	// HTTP/3 is advertised in responses from the virtual host
+	// that is served over it.
+	altSvcVirtualHost := envoy_v3.VirtualHost("www.example.com",
+		&envoy_route_v3.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/backend/80/da39a3ee5e"),
+		},
+	)
+	altSvcVirtualHost.ResponseHeadersToAdd = envoy_v3.HeaderValueList(map[string]string{
+		"alt-svc": `h3=":443"; ma=86400`,
+	}, false)
+
+	c.Request(routeType, "https/legacy.example.com", "https/secure.example.com", "https/www.example.com").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("https/legacy.example.com",
+				envoy_v3.VirtualHost("legacy.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/backend/80/da39a3ee5e"),
+					},
+				),
+			),
+			envoy_v3.RouteConfiguration("https/secure.example.com",
+				envoy_v3.VirtualHost("secure.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/backend/80/da39a3ee5e"),
+					},
+				),
+			),
+			envoy_v3.RouteConfiguration("https/www.example.com",
+				altSvcVirtualHost,
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p2).IsValid().Status(p3).IsValid()
+
+	// The QUIC listener is removed with the last virtual host
+	// that is served over HTTP/3.
+	rh.OnDelete(p1)
+
+	c.Request(listenerType, "ingress_http3").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"net"
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/fixture"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestIPFilterPolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	cidr := func(s string) *net.IPNet {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	rh.OnAdd(fixture.NewService("app-server").
+		WithPorts(corev1.ServicePort{Port: 80}))
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN("app.projectcontour.io").
+		WithIPFilterPolicy(contour_api_v1.IPFilterPolicy{Deny: []string{"203.0.113.0/24"}}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/public")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/admin")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				IPFilterPolicy: &contour_api_v1.IPFilterPolicy{
+					Allow: []string{"10.0.0.0/8", "2001:db8::/32"},
+					Deny:  []string{"10.1.2.3"},
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+	rh.OnAdd(p)
+
+	ipFilter := func(policy *dag.IPFilterPolicy) map[string]*any.Any {
+		return map[string]*any.Any{
+			"envoy.filters.http.rbac": envoy_v3.RouteIPFilter(policy),
+		}
+	}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration(
+				"ingress_http",
+				envoy_v3.VirtualHost("app.projectcontour.io",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/public"),
+						Action: routeCluster("default/app-server/80/da39a3ee5e"),
+						TypedPerFilterConfig: ipFilter(&dag.IPFilterPolicy{
+							Deny: []*net.IPNet{cidr("203.0.113.0/24")},
+						}),
+					},
+					// The route policy narrows the virtual host policy.
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/admin"),
+						Action: routeCluster("default/app-server/80/da39a3ee5e"),
+						TypedPerFilterConfig: ipFilter(&dag.IPFilterPolicy{
+							Allow: []*net.IPNet{cidr("10.0.0.0/8"), cidr("2001:db8::/32")},
+							Deny:  []*net.IPNet{cidr("203.0.113.0/24"), cidr("10.1.2.3/32")},
+						}),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/app-server/80/da39a3ee5e"),
+						TypedPerFilterConfig: ipFilter(&dag.IPFilterPolicy{
+							Deny: []*net.IPNet{cidr("203.0.113.0/24")},
+						}),
+					},
+				),
+			),
+		),
+	}).Status(p).IsValid()
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_http",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManagerBuilder().
+						DefaultFilters().
+						AddFilter(envoy_v3.FilterIPFilter()).
+						RouteConfigName("ingress_http").
+						MetricsPrefix("ingress_http").
+						AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+						Get(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	})
+
+	rh.OnDelete(p)
+
+	// A TCPProxy is protected by an RBAC network filter.
+	tcp := fixture.NewProxy("tcp").
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "tcp.projectcontour.io",
+				TLS:  &contour_api_v1.TLS{Passthrough: true},
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				IPFilterPolicy: &contour_api_v1.IPFilterPolicy{
+					Allow: []string{"192.168.0.0/16"},
+				},
+			},
+		})
+	rh.OnAdd(tcp)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				FilterChains: []*envoy_listener_v3.FilterChain{{
+					Filters: envoy_v3.Filters(
+						envoy_v3.IPFilter("ingress_https", &dag.IPFilterPolicy{
+							Allow: []*net.IPNet{cidr("192.168.0.0/16")},
+						}),
+						tcpproxy("ingress_https", "default/app-server/80/da39a3ee5e"),
+					),
+					FilterChainMatch: &envoy_listener_v3.FilterChainMatch{
+						ServerNames: []string{"tcp.projectcontour.io"},
+					},
+				}},
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	}).Status(tcp).IsValid()
+}
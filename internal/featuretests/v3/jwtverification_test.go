@@ -0,0 +1,295 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_config_filter_http_jwt_authn_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const jwks = `{"keys":[]}`
+
+func jwtFilterFor(vhost string, providers []dag.JWTProvider) *envoy_listener_v3.Filter {
+	return envoy_v3.HTTPConnectionManagerBuilder().
+		AddFilter(envoy_v3.FilterMisdirectedRequests(vhost)).
+		DefaultFilters().
+		AddFilter(envoy_v3.FilterJWTAuthN(providers)).
+		AddFilter(envoy_v3.FilterJWTClaimsToHeaders(providers)).
+		RouteConfigName(path.Join("https", vhost)).
+		MetricsPrefix(xdscache_v3.ENVOY_HTTPS_LISTENER).
+		AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+		Get()
+}
+
+func jwtRemoteJWKS(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "jwt.projectcontour.io"
+
+	p := fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithJWTProviders(contour_api_v1.JWTProvider{
+			Name:      "provider",
+			Issuer:    "issuer.projectcontour.io",
+			Audiences: []string{"contour"},
+			RemoteJWKS: &contour_api_v1.RemoteJWKS{
+				URI: "https://issuer.projectcontour.io/jwks.json",
+				ExtensionServiceRef: contour_api_v1.ExtensionServiceReference{
+					Namespace: "auth",
+					Name:      "jwks",
+				},
+				CacheDuration: "1h",
+			},
+			ClaimsToHeaders: []contour_api_v1.JWTClaimToHeader{{
+				Claim:  "sub",
+				Header: "X-JWT-Subject",
+			}},
+			Default: true,
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	providers := []dag.JWTProvider{{
+		Name:      "provider",
+		Issuer:    "issuer.projectcontour.io",
+		Audiences: []string{"contour"},
+		RemoteJWKS: &dag.RemoteJWKS{
+			URI:           "https://issuer.projectcontour.io/jwks.json",
+			Cluster:       &dag.ExtensionCluster{Name: "extension/auth/jwks"},
+			Timeout:       time.Second,
+			CacheDuration: time.Hour,
+		},
+		ClaimsToHeaders: []dag.JWTClaimToHeader{{
+			Claim:  "sub",
+			Header: "X-JWT-Subject",
+		}},
+	}}
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls(fqdn,
+						&corev1.Secret{
+							ObjectMeta: fixture.ObjectMeta("certificate"),
+							Type:       "kubernetes.io/tls",
+							Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+						},
+						jwtFilterFor(fqdn, providers),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener()),
+	}).Status(p).IsValid()
+}
+
+func jwtRoutePolicies(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	const fqdn = "jwt.projectcontour.io"
+
+	rh.OnAdd(fixture.NewProxy("proxy").
+		WithFQDN(fqdn).
+		WithCertificate("certificate").
+		WithJWTProviders(contour_api_v1.JWTProvider{
+			Name:      "provider",
+			LocalJWKS: &contour_api_v1.LocalJWKS{Inline: jwks},
+			Default:   true,
+		}, contour_api_v1.JWTProvider{
+			Name:      "other",
+			LocalJWKS: &contour_api_v1.LocalJWKS{SecretName: "jwks"},
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/optional")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				JWTVerificationPolicy: &contour_api_v1.JWTVerificationPolicy{
+					Mode: "optional",
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/disabled")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				JWTVerificationPolicy: &contour_api_v1.JWTVerificationPolicy{
+					Mode: "disabled",
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/other")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+				JWTVerificationPolicy: &contour_api_v1.JWTVerificationPolicy{
+					Mode:     "required",
+					Provider: "other",
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services:   []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		}),
+	)
+
+	requirement := func(name string) map[string]*any.Any {
+		return withFilterConfig("envoy.filters.http.jwt_authn",
+			&envoy_config_filter_http_jwt_authn_v3.PerRouteConfig{
+				RequirementSpecifier: &envoy_config_filter_http_jwt_authn_v3.PerRouteConfig_RequirementName{
+					RequirementName: name,
+				},
+			})
+	}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration(
+				path.Join("https", fqdn),
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:                routePrefix("/other"),
+						Action:               routeCluster("default/app-server/80/da39a3ee5e"),
+						TypedPerFilterConfig: requirement("other"),
+					},
+					&envoy_route_v3.Route{
+						Match:                routePrefix("/optional"),
+						Action:               routeCluster("default/app-server/80/da39a3ee5e"),
+						TypedPerFilterConfig: requirement("provider/allow-missing"),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/disabled"),
+						Action: routeCluster("default/app-server/80/da39a3ee5e"),
+					},
+					&envoy_route_v3.Route{
+						Match:                routePrefix("/"),
+						Action:               routeCluster("default/app-server/80/da39a3ee5e"),
+						TypedPerFilterConfig: requirement("provider"),
+					},
+				),
+			),
+			envoy_v3.RouteConfiguration(
+				"ingress_http",
+				envoy_v3.VirtualHost(fqdn,
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/other"),
+						Action: withRedirect(),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/optional"),
+						Action: withRedirect(),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/disabled"),
+						Action: withRedirect(),
+					},
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: withRedirect(),
+					},
+				),
+			),
+		),
+	})
+}
+
+func jwtRequiresTLS(t *testing.T, rh cache.ResourceEventHandler, c *Contour) {
+	p := fixture.NewProxy("proxy").
+		WithFQDN("jwt.projectcontour.io").
+		WithJWTProviders(contour_api_v1.JWTProvider{
+			Name:      "provider",
+			LocalJWKS: &contour_api_v1.LocalJWKS{Inline: jwks},
+			Default:   true,
+		}).
+		WithSpec(contour_api_v1.HTTPProxySpec{
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{Name: "app-server", Port: 80}},
+			}},
+		})
+
+	rh.OnAdd(p)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl:   listenerType,
+		Resources: resources(t, staticListener()),
+	}).Status(p).HasError(contour_api_v1.ConditionTypeJWTVerificationError, "JWTVerificationNotPermitted",
+		"Spec.VirtualHost.JWTProviders can only be defined for root HTTPProxies that terminate TLS")
+}
+
+func TestJWTVerification(t *testing.T) {
+	subtests := map[string]func(*testing.T, cache.ResourceEventHandler, *Contour){
+		"RemoteJWKS":    jwtRemoteJWKS,
+		"RoutePolicies": jwtRoutePolicies,
+		"RequiresTLS":   jwtRequiresTLS,
+	}
+
+	for n, f := range subtests {
+		f := f
+		t.Run(n, func(t *testing.T) {
+			rh, c, done := setup(t)
+			defer done()
+
+			rh.OnAdd(fixture.NewService("auth/jwks-server").
+				WithPorts(corev1.ServicePort{Port: 8443}))
+
+			rh.OnAdd(&v1alpha1.ExtensionService{
+				ObjectMeta: fixture.ObjectMeta("auth/jwks"),
+				Spec: v1alpha1.ExtensionServiceSpec{
+					Services: []v1alpha1.ExtensionServiceTarget{
+						{Name: "jwks-server", Port: 8443},
+					},
+				},
+			})
+
+			rh.OnAdd(fixture.NewService("app-server").
+				WithPorts(corev1.ServicePort{Port: 80}))
+
+			rh.OnAdd(&corev1.Secret{
+				ObjectMeta: fixture.ObjectMeta("certificate"),
+				Type:       "kubernetes.io/tls",
+				Data:       featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+			})
+
+			rh.OnAdd(&corev1.Secret{
+				ObjectMeta: fixture.ObjectMeta("jwks"),
+				Type:       corev1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					dag.JWKSKey: []byte(jwks),
+				},
+			})
+
+			f(t, rh, c)
+		})
+	}
+}
@@ -17,14 +17,17 @@ import (
 	"testing"
 
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
 	"github.com/projectcontour/contour/internal/dag"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/featuretests"
 	"github.com/projectcontour/contour/internal/fixture"
 	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	"github.com/projectcontour/contour/pkg/config"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -400,7 +403,8 @@ func TestHTTPProxyTLSListener(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: secret1},
+					[]*dag.Secret{{Object: secret1}},
+					envoy_tls_v3.TlsParameters_TLSv1_3,
 					envoy_tls_v3.TlsParameters_TLSv1_3,
 					nil,
 					nil,
@@ -481,8 +485,9 @@ func TestTLSListenerCipherSuites(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: secret1},
+					[]*dag.Secret{{Object: secret1}},
 					envoy_tls_v3.TlsParameters_TLSv1_2,
+					envoy_tls_v3.TlsParameters_TLSv1_3,
 					[]string{"ECDHE-ECDSA-AES256-GCM-SHA384"},
 					nil,
 					"h2", "http/1.1"),
@@ -1085,8 +1090,9 @@ func TestHTTPProxyMinimumTLSVersion(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: secret1},
+					[]*dag.Secret{{Object: secret1}},
 					envoy_tls_v3.TlsParameters_TLSv1_2,
+					envoy_tls_v3.TlsParameters_TLSv1_3,
 					nil,
 					nil,
 					"h2", "http/1.1"),
@@ -1144,7 +1150,8 @@ func TestHTTPProxyMinimumTLSVersion(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: secret1},
+					[]*dag.Secret{{Object: secret1}},
+					envoy_tls_v3.TlsParameters_TLSv1_3,
 					envoy_tls_v3.TlsParameters_TLSv1_3,
 					nil,
 					nil,
@@ -1271,3 +1278,154 @@ func TestHTTPProxyXffNumTrustedHops(t *testing.T) {
 		TypeUrl: listenerType,
 	})
 }
+
+func TestLDSAdditionalListeners(t *testing.T) {
+	rh, c, done := setup(t,
+		func(conf *xdscache_v3.ListenerConfig) {
+			conf.HTTPListeners = map[string]xdscache_v3.Listener{
+				"ingress_http": {
+					Name:    "ingress_http",
+					Address: "0.0.0.0",
+					Port:    8080,
+				},
+				"internal": {
+					Name:    "internal",
+					Address: "127.0.0.1",
+					Port:    9080,
+				},
+			}
+			conf.HTTPSListeners = map[string]xdscache_v3.Listener{
+				"ingress_https": {
+					Name:    "ingress_https",
+					Address: "0.0.0.0",
+					Port:    8443,
+				},
+				"public": {
+					Name:          "public",
+					Address:       "0.0.0.0",
+					Port:          9443,
+					UseProxyProto: true,
+				},
+			}
+		},
+		func(eh *contour.EventHandler) {
+			eh.Builder.Processors = []dag.Processor{
+				&dag.HTTPProxyProcessor{
+					Clock: fixture.Clock,
+					AdditionalListeners: map[string]config.ListenerProtocol{
+						"internal": config.HTTPListenerProtocol,
+						"public":   config.HTTPSListenerProtocol,
+					},
+				},
+				&dag.ListenerProcessor{},
+			}
+		},
+	)
+	defer done()
+
+	s1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(s1)
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+
+	p1 := fixture.NewProxy("internal").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:     "www.example.com",
+				Listener: "internal",
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(p1)
+
+	// The same fqdn can be served on another listener.
+	p2 := fixture.NewProxy("public").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:     "www.example.com",
+				Listener: "public",
+				TLS: &contour_api_v1.TLS{
+					SecretName: s1.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(p2)
+
+	// Only the listeners that have virtual hosts bound to them
+	// are added.
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			&envoy_listener_v3.Listener{
+				Name:    "internal",
+				Address: envoy_v3.SocketAddress("127.0.0.1", 9080),
+				FilterChains: envoy_v3.FilterChains(
+					envoy_v3.HTTPConnectionManager("internal", envoy_v3.FileAccessLogEnvoy("/dev/stdout"), 0, 0),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			&envoy_listener_v3.Listener{
+				Name:    "public",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 9443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.ProxyProtocol(),
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					filterchaintls("www.example.com", s1,
+						envoy_v3.HTTPConnectionManagerBuilder().
+							AddFilter(envoy_v3.FilterMisdirectedRequests("www.example.com")).
+							DefaultFilters().
+							RouteConfigName("public/www.example.com").
+							MetricsPrefix("public").
+							AccessLoggers(envoy_v3.FileAccessLogEnvoy("/dev/stdout")).
+							Get(),
+						nil, "h2", "http/1.1"),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(p1).IsValid()
+
+	c.Request(routeType, "internal", "public/www.example.com").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("internal",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/backend/80/da39a3ee5e"),
+					},
+				),
+			),
+			envoy_v3.RouteConfiguration("public/www.example.com",
+				envoy_v3.VirtualHost("www.example.com",
+					&envoy_route_v3.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/backend/80/da39a3ee5e"),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p2).IsValid()
+}
@@ -0,0 +1,130 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMultipleCertificates(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rsaSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret-rsa",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(rsaSecret)
+
+	ecdsaSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret-ecdsa",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: featuretests.Secretdata(fixture.EC_CERTIFICATE, fixture.EC_PRIVATE_KEY),
+	}
+	rh.OnAdd(ecdsaSecret)
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+
+	p1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:            rsaSecret.Name,
+					AdditionalSecretNames: []string{ecdsaSecret.Name},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(p1)
+
+	c.Request(listenerType, "ingress_https").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					envoy_v3.FilterChainTLS(
+						"www.example.com",
+						envoy_v3.DownstreamTLSContext(
+							[]*dag.Secret{{Object: rsaSecret}, {Object: ecdsaSecret}},
+							envoy_tls_v3.TlsParameters_TLSv1_2,
+							envoy_tls_v3.TlsParameters_TLSv1_3,
+							nil,
+							nil,
+							"h2", "http/1.1"),
+						envoy_v3.Filters(httpsFilterFor("www.example.com")),
+					),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+		),
+		TypeUrl: listenerType,
+	}).Status(p1).IsValid()
+
+	// Both secrets are served by SDS.
+	c.Request(secretType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			secret(ecdsaSecret),
+			secret(rsaSecret),
+		),
+		TypeUrl: secretType,
+	})
+
+	// A second RSA certificate is rejected.
+	rsaSecret2 := rsaSecret.DeepCopy()
+	rsaSecret2.Name = "secret-rsa-2"
+	rh.OnAdd(rsaSecret2)
+
+	p2 := p1.DeepCopy()
+	p2.Spec.VirtualHost.TLS.AdditionalSecretNames = []string{rsaSecret2.Name}
+	rh.OnUpdate(p1, p2)
+
+	c.Request(secretType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		TypeUrl: secretType,
+	}).Status(p2).HasError(contour_api_v1.ConditionTypeTLSError, "SecretNotValid",
+		`Spec.VirtualHost.TLS Secrets "secret-rsa" and "secret-rsa-2" both have RSA certificates`)
+}
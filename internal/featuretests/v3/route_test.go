@@ -1347,6 +1347,7 @@ func TestRouteWithTLS_InsecurePaths_DisablePermitInsecureTrue(t *testing.T) {
 			&dag.IngressProcessor{},
 			&dag.HTTPProxyProcessor{
 				DisablePermitInsecure: true,
+				Clock:                 fixture.Clock,
 			},
 			&dag.ListenerProcessor{},
 		}
@@ -1731,6 +1732,7 @@ func TestHTTPProxyRouteWithTLS_InsecurePaths_DisablePermitInsecureTrue(t *testin
 			&dag.IngressProcessor{},
 			&dag.HTTPProxyProcessor{
 				DisablePermitInsecure: true,
+				Clock:                 fixture.Clock,
 			},
 			&dag.ListenerProcessor{},
 		}
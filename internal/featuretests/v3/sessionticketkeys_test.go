@@ -0,0 +1,192 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSessionTicketKeys(t *testing.T) {
+	globalKeys := types.NamespacedName{
+		Name:      "ticket-keys",
+		Namespace: "admin",
+	}
+
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.Source.ConfiguredSecretRefs = []*types.NamespacedName{&globalKeys}
+		eh.Builder.Processors = []dag.Processor{
+			&dag.IngressProcessor{},
+			&dag.HTTPProxyProcessor{
+				Clock:             fixture.Clock,
+				SessionTicketKeys: &globalKeys,
+			},
+			&dag.ListenerProcessor{},
+		}
+	})
+	defer done()
+
+	sessionTicketKeysSecret := func(name, namespace string, key byte) *v1.Secret {
+		keys := make([]byte, dag.SessionTicketKeyLength)
+		for i := range keys {
+			keys[i] = key
+		}
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				dag.SessionTicketKeysKey: keys,
+			},
+		}
+	}
+
+	sec1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec1)
+
+	keys1 := sessionTicketKeysSecret(globalKeys.Name, globalKeys.Namespace, 1)
+	rh.OnAdd(keys1)
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+
+	p1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: "default",
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "www.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec1.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(p1)
+
+	httpsListener := func(keys *v1.Secret) *envoy_listener_v3.Listener {
+		downstreamTLS := envoy_v3.DownstreamTLSContext(
+			[]*dag.Secret{{Object: sec1}},
+			envoy_tls_v3.TlsParameters_TLSv1_2,
+			envoy_tls_v3.TlsParameters_TLSv1_3,
+			nil,
+			nil,
+			"h2", "http/1.1")
+		downstreamTLS.SessionTicketKeysType = envoy_v3.SessionTicketKeys(&dag.Secret{Object: keys})
+
+		return &envoy_listener_v3.Listener{
+			Name:    "ingress_https",
+			Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+			ListenerFilters: envoy_v3.ListenerFilters(
+				envoy_v3.TLSInspector(),
+			),
+			FilterChains: []*envoy_listener_v3.FilterChain{
+				envoy_v3.FilterChainTLS(
+					"www.example.com",
+					downstreamTLS,
+					envoy_v3.Filters(httpsFilterFor("www.example.com")),
+				),
+			},
+			SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+		}
+	}
+
+	// The virtual host uses the configured session ticket keys.
+	c.Request(listenerType, "ingress_https").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			httpsListener(keys1),
+		),
+		TypeUrl: listenerType,
+	}).Status(p1).IsValid()
+
+	c.Request(secretType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.SessionTicketKeysSecret(&dag.Secret{Object: keys1}),
+			secret(sec1),
+		),
+		TypeUrl: secretType,
+	})
+
+	// Rotating the keys updates the SDS secret but not the listener.
+	keys2 := sessionTicketKeysSecret(globalKeys.Name, globalKeys.Namespace, 2)
+	rh.OnUpdate(keys1, keys2)
+
+	c.Request(listenerType, "ingress_https").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			httpsListener(keys1),
+		),
+		TypeUrl: listenerType,
+	})
+
+	c.Request(secretType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.SessionTicketKeysSecret(&dag.Secret{Object: keys2}),
+			secret(sec1),
+		),
+		TypeUrl: secretType,
+	})
+
+	// The session ticket keys of the virtual host replace the
+	// configured session ticket keys.
+	vhostKeys := sessionTicketKeysSecret("vhost-ticket-keys", "default", 3)
+	rh.OnAdd(vhostKeys)
+
+	p2 := p1.DeepCopy()
+	p2.Spec.VirtualHost.TLS.SessionTicketKeysSecretName = vhostKeys.Name
+	rh.OnUpdate(p1, p2)
+
+	c.Request(listenerType, "ingress_https").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			httpsListener(vhostKeys),
+		),
+		TypeUrl: listenerType,
+	}).Status(p2).IsValid()
+
+	c.Request(secretType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			secret(sec1),
+			envoy_v3.SessionTicketKeysSecret(&dag.Secret{Object: vhostKeys}),
+		),
+		TypeUrl: secretType,
+	})
+}
@@ -20,9 +20,13 @@ import (
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/featuretests"
 	"github.com/projectcontour/contour/internal/fixture"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	"github.com/projectcontour/contour/pkg/config"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -910,3 +914,82 @@ func TestTCPProxyInvalidLoadBalancerPolicy(t *testing.T) {
 		TypeUrl: clusterType,
 	})
 }
+
+func TestTCPProxyPlainListener(t *testing.T) {
+	rh, c, done := setup(t,
+		func(conf *xdscache_v3.ListenerConfig) {
+			conf.TCPListeners = map[string]xdscache_v3.Listener{
+				"mqtt": {
+					Name:    "mqtt",
+					Address: "0.0.0.0",
+					Port:    1883,
+				},
+			}
+		},
+		func(eh *contour.EventHandler) {
+			eh.Builder.Processors = []dag.Processor{
+				&dag.HTTPProxyProcessor{
+					Clock: fixture.Clock,
+					AdditionalListeners: map[string]config.ListenerProtocol{
+						"mqtt": config.TCPListenerProtocol,
+					},
+				},
+				&dag.ListenerProcessor{},
+			}
+		},
+	)
+	defer done()
+
+	svc := fixture.NewService("broker").
+		WithPorts(v1.ServicePort{Port: 1883, TargetPort: intstr.FromInt(1883)})
+	rh.OnAdd(svc)
+
+	hp1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mqtt",
+			Namespace: svc.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:     "mqtt.example.com",
+				Listener: "mqtt",
+			},
+			TCPProxy: &contour_api_v1.TCPProxy{
+				Services: []contour_api_v1.Service{{
+					Name: svc.Name,
+					Port: 1883,
+				}},
+			},
+		},
+	}
+	rh.OnAdd(hp1)
+
+	// The listener proxies all connections without TLS.
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			&envoy_listener_v3.Listener{
+				Name:          "mqtt",
+				Address:       envoy_v3.SocketAddress("0.0.0.0", 1883),
+				FilterChains:  envoy_v3.FilterChains(tcpproxy("mqtt", "default/broker/1883/da39a3ee5e")),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(hp1).IsValid()
+
+	// Another HTTPProxy on the same listener conflicts, even
+	// with a different fqdn.
+	hp2 := hp1.DeepCopy()
+	hp2.Name = "other"
+	hp2.Spec.VirtualHost.Fqdn = "other.example.com"
+	rh.OnAdd(hp2)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(hp1).HasError(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateListener",
+		`TCP listener "mqtt" is used in multiple HTTPProxies: default/mqtt, default/other`)
+}
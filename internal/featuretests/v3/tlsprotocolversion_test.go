@@ -127,7 +127,8 @@ func TestTLSMinimumProtocolVersion(t *testing.T) {
 			envoy_v3.FilterChainTLS(
 				"kuard.example.com",
 				envoy_v3.DownstreamTLSContext(
-					&dag.Secret{Object: sec1},
+					[]*dag.Secret{{Object: sec1}},
+					envoy_tls_v3.TlsParameters_TLSv1_3,
 					envoy_tls_v3.TlsParameters_TLSv1_3,
 					nil,
 					nil,
@@ -178,3 +179,80 @@ func TestTLSMinimumProtocolVersion(t *testing.T) {
 		TypeUrl: listenerType,
 	})
 }
+
+func TestTLSMaximumProtocolVersionAndCipherSuites(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	sec1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec1)
+
+	s1 := fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80})
+	rh.OnAdd(s1)
+
+	hp1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: s1.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "kuard.example.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName:             sec1.Name,
+					MaximumProtocolVersion: "1.2",
+					CipherSuites: []string{
+						"ECDHE-RSA-AES128-GCM-SHA256",
+						" AES128-SHA",
+						"ECDHE-RSA-AES128-GCM-SHA256",
+					},
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []contour_api_v1.Service{{
+					Name: s1.Name,
+					Port: 80,
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(hp1)
+
+	// The cipher suites are sanitized and replace the
+	// configured cipher suites for this virtual host.
+	c.Request(listenerType, "ingress_https").Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: []*envoy_listener_v3.FilterChain{
+					envoy_v3.FilterChainTLS(
+						"kuard.example.com",
+						envoy_v3.DownstreamTLSContext(
+							[]*dag.Secret{{Object: sec1}},
+							envoy_tls_v3.TlsParameters_TLSv1_2,
+							envoy_tls_v3.TlsParameters_TLSv1_2,
+							[]string{"ECDHE-RSA-AES128-GCM-SHA256", "AES128-SHA"},
+							nil,
+							"h2", "http/1.1"),
+						envoy_v3.Filters(httpsFilterFor("kuard.example.com")),
+					),
+				},
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+		),
+		TypeUrl: listenerType,
+	}).Status(hp1).IsValid()
+}
@@ -0,0 +1,158 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_endpoint_v3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
+	"github.com/projectcontour/contour/internal/featuretests"
+	"github.com/projectcontour/contour/internal/fixture"
+	xdscache_v3 "github.com/projectcontour/contour/internal/xdscache/v3"
+	"github.com/projectcontour/contour/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestUDPProxy(t *testing.T) {
+	rh, c, done := setup(t,
+		func(conf *xdscache_v3.ListenerConfig) {
+			conf.UDPListeners = map[string]xdscache_v3.Listener{
+				"dns": {
+					Name:    "dns",
+					Address: "0.0.0.0",
+					Port:    5353,
+				},
+			}
+		},
+		func(eh *contour.EventHandler) {
+			eh.Builder.Processors = []dag.Processor{
+				&dag.HTTPProxyProcessor{
+					Clock: fixture.Clock,
+					AdditionalListeners: map[string]config.ListenerProtocol{
+						"dns": config.UDPListenerProtocol,
+					},
+				},
+				&dag.ListenerProcessor{},
+			}
+		},
+	)
+	defer done()
+
+	// The TCP and UDP ports of the Service share the
+	// same number.
+	svc := fixture.NewService("coredns").
+		WithPorts(
+			v1.ServicePort{Name: "dns-tcp", Protocol: "TCP", Port: 53, TargetPort: intstr.FromInt(5353)},
+			v1.ServicePort{Name: "dns-udp", Protocol: "UDP", Port: 53, TargetPort: intstr.FromInt(5353)},
+		)
+	rh.OnAdd(svc)
+
+	rh.OnAdd(featuretests.Endpoints(svc.Namespace, svc.Name, v1.EndpointSubset{
+		Addresses: featuretests.Addresses("10.0.0.1"),
+		Ports: featuretests.Ports(
+			featuretests.Port("dns-tcp", 5353),
+			v1.EndpointPort{Name: "dns-udp", Protocol: "UDP", Port: 5353},
+		),
+	}))
+
+	hp1 := &contour_api_v1.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dns",
+			Namespace: svc.Namespace,
+		},
+		Spec: contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn:     "dns.example.com",
+				Listener: "dns",
+			},
+			UDPProxy: &contour_api_v1.UDPProxy{
+				ServiceName:        svc.Name,
+				Port:               53,
+				SessionIdleTimeout: "30s",
+				HashSourceIP:       true,
+			},
+		},
+	}
+	rh.OnAdd(hp1)
+
+	// The cluster selects endpoints by the hash of the source IP.
+	udpCluster := cluster("default/coredns/53/766b0b3fb6", "default/coredns/dns-udp", "default_coredns_53")
+	udpCluster.LbPolicy = envoy_cluster_v3.Cluster_RING_HASH
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.UDPListener("dns", "0.0.0.0", 5353, &dag.UDPProxy{
+				ListenerName: "dns",
+				Cluster: &dag.Cluster{
+					Upstream: &dag.Service{
+						Weighted: dag.WeightedService{
+							ServiceName:      svc.Name,
+							ServiceNamespace: svc.Namespace,
+							ServicePort:      svc.Spec.Ports[1],
+						},
+					},
+					LoadBalancerPolicy: dag.LoadBalancerPolicyRequestHash,
+				},
+				SessionIdleTimeout: 30 * time.Second,
+				HashSourceIP:       true,
+			}),
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(hp1).IsValid()
+
+	c.Request(clusterType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			udpCluster,
+		),
+		TypeUrl: clusterType,
+	})
+
+	// Only the UDP endpoint port is used.
+	c.Request(endpointType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			&envoy_endpoint_v3.ClusterLoadAssignment{
+				ClusterName: "default/coredns/dns-udp",
+				Endpoints: envoy_v3.WeightedEndpoints(1,
+					envoy_v3.SocketAddress("10.0.0.1", 5353),
+				),
+			},
+		),
+		TypeUrl: endpointType,
+	})
+
+	// Another HTTPProxy on the same listener conflicts, even
+	// with a different fqdn.
+	hp2 := hp1.DeepCopy()
+	hp2.Name = "other"
+	hp2.Spec.VirtualHost.Fqdn = "other.example.com"
+	rh.OnAdd(hp2)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(hp1).HasError(contour_api_v1.ConditionTypeVirtualHostError, "DuplicateListener",
+		`UDP listener "dns" is used in multiple HTTPProxies: default/dns, default/other`)
+}
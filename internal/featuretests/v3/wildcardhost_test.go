@@ -20,6 +20,7 @@ import (
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	envoy_discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
 	envoy_v3 "github.com/projectcontour/contour/internal/envoy/v3"
 	"github.com/projectcontour/contour/internal/featuretests"
 	"github.com/projectcontour/contour/internal/fixture"
@@ -30,8 +31,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-// Note: Wildcard hostnames are only supported on Ingress resources for now.
-
 // Test that Ingress v1 and v1beta1 without TLS secrets generate the
 // appropriate route config.
 func TestIngressWildcardHostHTTP(t *testing.T) {
@@ -280,3 +279,133 @@ func TestIngressWildcardHostHTTPSWildcardSecret(t *testing.T) {
 		TypeUrl: routeType,
 	})
 }
+
+// Test that a HTTPProxy with a wildcard fqdn and TLS generates a
+// wildcard SNI filter chain and guarded routes, and that a HTTPProxy
+// with an exact fqdn under the wildcard gets its own filter chain and
+// virtual host.
+func TestHTTPProxyWildcardFQDN(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	sec := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wildcard-tls-secret",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: featuretests.Secretdata(featuretests.CERTIFICATE, featuretests.RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec)
+
+	svc := fixture.NewService("svc").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)})
+	rh.OnAdd(svc)
+
+	exact := fixture.NewService("exact").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)})
+	rh.OnAdd(exact)
+
+	rh.OnAdd(fixture.NewProxy("wildcard").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "*.tenants.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: svc.Name,
+					Port: 80,
+				}},
+			}},
+		}),
+	)
+
+	rh.OnAdd(fixture.NewProxy("exact").WithSpec(
+		contour_api_v1.HTTPProxySpec{
+			VirtualHost: &contour_api_v1.VirtualHost{
+				Fqdn: "admin.tenants.com",
+				TLS: &contour_api_v1.TLS{
+					SecretName: sec.Name,
+				},
+			},
+			Routes: []contour_api_v1.Route{{
+				Services: []contour_api_v1.Service{{
+					Name: exact.Name,
+					Port: 80,
+				}},
+			}},
+		}),
+	)
+
+	c.Request(listenerType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			defaultHTTPListener(),
+			&envoy_listener_v3.Listener{
+				Name:    "ingress_https",
+				Address: envoy_v3.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy_v3.ListenerFilters(
+					envoy_v3.TLSInspector(),
+				),
+				FilterChains: appendFilterChains(
+					filterchaintls("*.tenants.com", sec,
+						httpsFilterFor("*.tenants.com"),
+						nil, "h2", "http/1.1"),
+					filterchaintls("admin.tenants.com", sec,
+						httpsFilterFor("admin.tenants.com"),
+						nil, "h2", "http/1.1"),
+				),
+				SocketOptions: envoy_v3.TCPKeepaliveSocketOptions(),
+			},
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	})
+
+	wildcardRoute := &envoy_route_v3.Route{
+		Match: &envoy_route_v3.RouteMatch{
+			PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
+				Prefix: "/",
+			},
+			Headers: []*envoy_route_v3.HeaderMatcher{{
+				Name: ":authority",
+				HeaderMatchSpecifier: &envoy_route_v3.HeaderMatcher_SafeRegexMatch{
+					SafeRegexMatch: &matcher.RegexMatcher{
+						EngineType: &matcher.RegexMatcher_GoogleRe2{
+							GoogleRe2: &matcher.RegexMatcher_GoogleRE2{},
+						},
+						Regex: "^[a-z0-9]([-a-z0-9]*[a-z0-9])?\\.tenants\\.com",
+					},
+				},
+			}},
+		},
+		Action: routeCluster("default/svc/80/da39a3ee5e"),
+	}
+
+	c.Request(routeType).Equals(&envoy_discovery_v3.DiscoveryResponse{
+		Resources: resources(t,
+			envoy_v3.RouteConfiguration("https/*.tenants.com",
+				envoy_v3.VirtualHost("*.tenants.com", wildcardRoute),
+			),
+			envoy_v3.RouteConfiguration("https/admin.tenants.com",
+				envoy_v3.VirtualHost("admin.tenants.com", &envoy_route_v3.Route{
+					Match:  routePrefix("/"),
+					Action: routeCluster("default/exact/80/da39a3ee5e"),
+				}),
+			),
+			envoy_v3.RouteConfiguration("ingress_http",
+				envoy_v3.VirtualHost("*.tenants.com", &envoy_route_v3.Route{
+					Match:  wildcardRoute.Match,
+					Action: envoy_v3.UpgradeHTTPS(),
+				}),
+				envoy_v3.VirtualHost("admin.tenants.com", &envoy_route_v3.Route{
+					Match:  routePrefix("/"),
+					Action: envoy_v3.UpgradeHTTPS(),
+				}),
+			),
+		),
+		TypeUrl: routeType,
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixture
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+)
+
+// Now is the fixed time that tests check certificate expiry against,
+// so that the certificates in this package never expire in tests.
+var Now = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock returns Now. It is the clock of the DAG processors in tests.
+func Clock() time.Time {
+	return Now
+}
+
+// SelfSignedCertificate returns a PEM encoded certificate for the
+// given DNS names that expires at notAfter. It is signed with, and
+// has the public key of, RSA_PRIVATE_KEY.
+func SelfSignedCertificate(notAfter time.Time, dnsNames ...string) string {
+	block, _ := pem.Decode([]byte(RSA_PRIVATE_KEY))
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		panic(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "contour"},
+		DNSNames:     dnsNames,
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -145,5 +145,19 @@ dsipT/w+MFInBLLiJcqLAfh0647vhjCFNGG4MZ6YGVwiIdvjZ1dH2Ujsdo8e0+Wi
 8cnx8JvuAJdr5HzMI6fvnMDzjzAskMgYUNhOUhM2g223JuoyyLY2/DL7dOYkFeSn
 b5qYn0JNERfPYdLwXNV1HCM9
 -----END PRIVATE KEY-----
+`
+
+	// CRL is an empty certificate revocation list issued by CERTIFICATE.
+	CRL = `-----BEGIN X509 CRL-----
+MIIBlDB+AgEBMA0GCSqGSIb3DQEBCwUAMCUxIzAhBgNVBAMTGmJvcmluZy13b3pu
+aWFrLmV4YW1wbGUuY29tFw0yMTAxMDEwMDAwMDBaFw0zMTAxMDEwMDAwMDBaMACg
+IzAhMB8GA1UdIwQYMBaAFMtwgx5YsWIvbBoUvoU+iY1lHuAUMA0GCSqGSIb3DQEB
+CwUAA4IBAQDTGV0BXkC9zgnBEUrxg/lgkAZDXv+W6Ipqx1xCq4x6MKWdNs4Awsan
+G+SShzQXesShNKH24oE/S1H4GFhDU04qCCMe0xyxqEomfCXaydAVF93yU+m4P6ld
+8RbKWm72C9A6L6zQVDuChNu0nD0HHslSTGDyFHh9V1KPHzPOzSTgJhA3sUC5xaoq
+5C+QdFH5WpT3iRzvUUuHpzaJLDHKYgeXzdF/Woj0ocQ8Z0R7M91T5wVsJVXZaPBJ
+Rz0GEJ4IEuF1k1uRdlC34gAXaExArZbCCdWmz0+i/XeWhWMGiqgKBcW9wL1zgWdv
+EOLGRnVcxdfsKRg7zqr2zebEOew83VzR
+-----END X509 CRL-----
 `
 )
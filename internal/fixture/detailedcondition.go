@@ -72,9 +72,14 @@ func (dcb *DetailedConditionBuilder) WithErrorf(errorType string, reason, format
 
 }
 
+// WithWarning adds a warning to a condition that is otherwise valid,
+// since warnings do not change the condition status.
 func (dcb *DetailedConditionBuilder) WithWarning(errorType, reason, message string) v1.DetailedCondition {
 
 	dc := (*v1.DetailedCondition)(dcb)
+	if dc.Status == "" {
+		dcb.Valid()
+	}
 	dc.AddWarning(errorType, reason, message)
 
 	return *dc
@@ -84,6 +89,9 @@ func (dcb *DetailedConditionBuilder) WithWarning(errorType, reason, message stri
 func (dcb *DetailedConditionBuilder) WithWarningf(warnType, reason, formatmsg string, args ...interface{}) v1.DetailedCondition {
 
 	dc := (*v1.DetailedCondition)(dcb)
+	if dc.Status == "" {
+		dcb.Valid()
+	}
 	dc.AddWarningf(warnType, reason, formatmsg, args...)
 
 	return *dc
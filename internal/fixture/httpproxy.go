@@ -89,3 +89,39 @@ func (b *ProxyBuilder) WithAuthServer(auth contour_api_v1.AuthorizationServer) *
 	b.Spec.VirtualHost.Authorization = &auth
 	return b
 }
+
+func (b *ProxyBuilder) WithJWTProviders(providers ...contour_api_v1.JWTProvider) *ProxyBuilder {
+	b.ensureVirtualHost()
+	b.Spec.VirtualHost.JWTProviders = providers
+	return b
+}
+
+func (b *ProxyBuilder) WithBasicAuth(auth contour_api_v1.BasicAuth) *ProxyBuilder {
+	b.ensureVirtualHost()
+	b.Spec.VirtualHost.BasicAuth = &auth
+	return b
+}
+
+func (b *ProxyBuilder) WithIPFilterPolicy(policy contour_api_v1.IPFilterPolicy) *ProxyBuilder {
+	b.ensureVirtualHost()
+	b.Spec.VirtualHost.IPFilterPolicy = &policy
+	return b
+}
+
+func (b *ProxyBuilder) WithOAuth2(oauth2 contour_api_v1.OAuth2) *ProxyBuilder {
+	b.ensureVirtualHost()
+	b.Spec.VirtualHost.OAuth2 = &oauth2
+	return b
+}
+
+func (b *ProxyBuilder) WithHSTSPolicy(policy contour_api_v1.HSTSPolicy) *ProxyBuilder {
+	b.ensureVirtualHost()
+	b.Spec.VirtualHost.HSTSPolicy = &policy
+	return b
+}
+
+func (b *ProxyBuilder) WithCSRFPolicy(policy contour_api_v1.CSRFPolicy) *ProxyBuilder {
+	b.ensureVirtualHost()
+	b.Spec.VirtualHost.CSRFPolicy = &policy
+	return b
+}
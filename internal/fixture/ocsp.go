@@ -0,0 +1,56 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixture
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// OCSPStaple returns a DER encoded OCSP response for the self-signed
+// CERTIFICATE, signed with RSA_PRIVATE_KEY. If serial is nil, the
+// response is for CERTIFICATE's serial number.
+func OCSPStaple(serial *big.Int, thisUpdate, nextUpdate time.Time) []byte {
+	block, _ := pem.Decode([]byte(CERTIFICATE))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		panic(err)
+	}
+
+	block, _ = pem.Decode([]byte(RSA_PRIVATE_KEY))
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		panic(err)
+	}
+
+	if serial == nil {
+		serial = cert.SerialNumber
+	}
+
+	staple, err := ocsp.CreateResponse(cert, cert, ocsp.Response{
+		Status:       ocsp.Good,
+		SerialNumber: serial,
+		ThisUpdate:   thisUpdate,
+		NextUpdate:   nextUpdate,
+	}, key)
+	if err != nil {
+		panic(err)
+	}
+
+	return staple
+}
@@ -40,3 +40,12 @@ var SecretRootsFallback = &v1.Secret{
 		v1.TLSPrivateKeyKey: []byte(RSA_PRIVATE_KEY),
 	},
 }
+
+var SecretRootsECDSACert = &v1.Secret{
+	ObjectMeta: ObjectMeta("roots/ssl-cert-ecdsa"),
+	Type:       v1.SecretTypeTLS,
+	Data: map[string][]byte{
+		v1.TLSCertKey:       []byte(EC_CERTIFICATE),
+		v1.TLSPrivateKeyKey: []byte(EC_PRIVATE_KEY),
+	},
+}
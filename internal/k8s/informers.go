@@ -94,6 +94,15 @@ func SecretsResources() []schema.GroupVersionResource {
 	}
 }
 
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+// ConfigMapsResources ...
+func ConfigMapsResources() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
+		corev1.SchemeGroupVersion.WithResource("configmaps"),
+	}
+}
+
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
 
 // EndpointsResources ...
@@ -33,13 +33,16 @@ type Metrics struct {
 	proxyValidGauge     *prometheus.GaugeVec
 	proxyOrphanedGauge  *prometheus.GaugeVec
 
+	certificateExpiryGauge *prometheus.GaugeVec
+
 	dagRebuildGauge             *prometheus.GaugeVec
 	dagRebuildTotal             prometheus.Counter
 	CacheHandlerOnUpdateSummary prometheus.Summary
 	EventHandlerOperations      *prometheus.CounterVec
 
 	// Keep a local cache of metrics for comparison on updates
-	proxyMetricCache *RouteMetric
+	proxyMetricCache       *RouteMetric
+	certificateMetricCache map[CertificateMeta]time.Time
 }
 
 // RouteMetric stores various metrics for HTTPProxy objects
@@ -56,6 +59,12 @@ type Meta struct {
 	VHost, Namespace string
 }
 
+// CertificateMeta holds the vhost and the namespace and name of
+// the Secret of a served certificate.
+type CertificateMeta struct {
+	VHost, Namespace, Name string
+}
+
 const (
 	BuildInfoGauge = "contour_build_info"
 
@@ -65,6 +74,8 @@ const (
 	HTTPProxyValidGauge     = "contour_httpproxy_valid"
 	HTTPProxyOrphanedGauge  = "contour_httpproxy_orphaned"
 
+	CertificateExpiryGauge = "contour_certificate_expiry_timestamp"
+
 	DAGRebuildGauge             = "contour_dagrebuild_timestamp"
 	DAGRebuildTotal             = "contour_dagrebuild_total"
 	cacheHandlerOnUpdateSummary = "contour_cachehandler_onupdate_duration_seconds"
@@ -122,6 +133,14 @@ func NewMetrics(registry *prometheus.Registry) *Metrics {
 			},
 			[]string{"namespace"},
 		),
+		certificateMetricCache: map[CertificateMeta]time.Time{},
+		certificateExpiryGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: CertificateExpiryGauge,
+				Help: "Timestamp at which the certificate of a Secret served by a vhost expires.",
+			},
+			[]string{"namespace", "name", "vhost"},
+		),
 		dagRebuildGauge: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: DAGRebuildGauge,
@@ -162,6 +181,7 @@ func (m *Metrics) register(registry *prometheus.Registry) {
 		m.proxyInvalidGauge,
 		m.proxyValidGauge,
 		m.proxyOrphanedGauge,
+		m.certificateExpiryGauge,
 		m.dagRebuildGauge,
 		m.dagRebuildTotal,
 		m.CacheHandlerOnUpdateSummary,
@@ -188,6 +208,7 @@ func (m *Metrics) Zero() {
 
 	m.SetDAGLastRebuilt(time.Now())
 	m.SetHTTPProxyMetric(zeroes)
+	m.SetCertificateExpiryMetric(map[CertificateMeta]time.Time{{}: time.Unix(0, 0)})
 	m.EventHandlerOperations.WithLabelValues("add", "Secret").Inc()
 
 	prometheus.NewTimer(m.CacheHandlerOnUpdateSummary).ObserveDuration()
@@ -253,6 +274,22 @@ func (m *Metrics) SetHTTPProxyMetric(metrics RouteMetric) {
 	}
 }
 
+// SetCertificateExpiryMetric sets the expiry times of the certificates
+// served by vhosts, and removes the metrics of certificates that are
+// no longer served.
+func (m *Metrics) SetCertificateExpiryMetric(expiries map[CertificateMeta]time.Time) {
+	for meta, notAfter := range expiries {
+		m.certificateExpiryGauge.WithLabelValues(meta.Namespace, meta.Name, meta.VHost).Set(float64(notAfter.Unix()))
+		delete(m.certificateMetricCache, meta)
+	}
+
+	for meta := range m.certificateMetricCache {
+		m.certificateExpiryGauge.DeleteLabelValues(meta.Namespace, meta.Name, meta.VHost)
+	}
+
+	m.certificateMetricCache = expiries
+}
+
 // Handler returns a http Handler for a metrics endpoint.
 func Handler(registry *prometheus.Registry) http.Handler {
 	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
@@ -689,3 +689,55 @@ func TestRemoveProxyMetric(t *testing.T) {
 		})
 	}
 }
+
+func TestSetCertificateExpiryMetric(t *testing.T) {
+	label := func(name, value string) *io_prometheus_client.LabelPair {
+		return &io_prometheus_client.LabelPair{Name: &name, Value: &value}
+	}
+	gauge := func(value float64) *io_prometheus_client.Gauge {
+		return &io_prometheus_client.Gauge{Value: &value}
+	}
+
+	r := prometheus.NewRegistry()
+	m := NewMetrics(r)
+
+	gather := func() []*io_prometheus_client.Metric {
+		t.Helper()
+
+		gathering, err := r.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := []*io_prometheus_client.Metric{}
+		for _, mf := range gathering {
+			if mf.GetName() == CertificateExpiryGauge {
+				got = mf.Metric
+			}
+		}
+		return got
+	}
+
+	m.SetCertificateExpiryMetric(map[CertificateMeta]time.Time{
+		{VHost: "foo.com", Namespace: "testns", Name: "foo"}: time.Unix(1600000000, 0),
+		{VHost: "bar.com", Namespace: "testns", Name: "bar"}: time.Unix(1700000000, 0),
+	})
+
+	assert.Equal(t, []*io_prometheus_client.Metric{{
+		Label: []*io_prometheus_client.LabelPair{label("name", "bar"), label("namespace", "testns"), label("vhost", "bar.com")},
+		Gauge: gauge(1700000000),
+	}, {
+		Label: []*io_prometheus_client.LabelPair{label("name", "foo"), label("namespace", "testns"), label("vhost", "foo.com")},
+		Gauge: gauge(1600000000),
+	}}, gather())
+
+	// The metrics of certificates that are no longer served are removed.
+	m.SetCertificateExpiryMetric(map[CertificateMeta]time.Time{
+		{VHost: "foo.com", Namespace: "testns", Name: "foo"}: time.Unix(1800000000, 0),
+	})
+
+	assert.Equal(t, []*io_prometheus_client.Metric{{
+		Label: []*io_prometheus_client.LabelPair{label("name", "foo"), label("namespace", "testns"), label("vhost", "foo.com")},
+		Gauge: gauge(1800000000),
+	}}, gather())
+}
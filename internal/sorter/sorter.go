@@ -128,15 +128,20 @@ func longestRouteByHeaderConditions(lhs, rhs *dag.Route) bool {
 }
 
 // Sorts the given Route slice in place. Routes are ordered first by
-// type (exact sorts before regex, sorts before prefix) and then
-// longest path match value, then by the length of the HeaderMatch
-// slice (if any). The HeaderMatch slice is also ordered by the matching
-// header name.
+// descending priority, then by type (exact sorts before regex, sorts
+// before prefix) and then longest path match value, then by the length
+// of the HeaderMatch slice (if any). The HeaderMatch slice is also
+// ordered by the matching header name.
 type routeSorter []*dag.Route
 
 func (s routeSorter) Len() int      { return len(s) }
 func (s routeSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 func (s routeSorter) Less(i, j int) bool {
+	if s[i].Priority != s[j].Priority {
+		// Sort highest priority first.
+		return s[i].Priority > s[j].Priority
+	}
+
 	switch a := s[i].PathMatchCondition.(type) {
 	case *dag.PrefixMatchCondition:
 		switch b := s[j].PathMatchCondition.(type) {
@@ -201,6 +201,43 @@ func TestSortRoutesPathMatch(t *testing.T) {
 	assert.Equal(t, want, have)
 }
 
+func TestSortRoutesPriority(t *testing.T) {
+	want := []*dag.Route{
+		// Note that a higher priority sorts before any match type.
+		{
+			PathMatchCondition: matchPrefixString("/"),
+			Priority:           10,
+		},
+		{
+			PathMatchCondition: matchRegex("/api/.*"),
+			Priority:           5,
+		},
+		{
+			PathMatchCondition: matchPrefixString("/api"),
+			HeaderMatchConditions: []dag.HeaderMatchCondition{
+				exactHeader("x-canary", "true"),
+			},
+			Priority: 5,
+		},
+		{
+			PathMatchCondition: matchExact("/api/v1"),
+		},
+		{
+			PathMatchCondition: matchPrefixString("/api"),
+		},
+		// Note that a negative priority sorts after the default.
+		{
+			PathMatchCondition: matchExact("/api/v2"),
+			Priority:           -1,
+		},
+	}
+
+	have := shuffleRoutes(want)
+
+	sort.Stable(For(have))
+	assert.Equal(t, want, have)
+}
+
 func TestSortRoutesLongestHeaders(t *testing.T) {
 	want := []*dag.Route{
 		{
@@ -15,6 +15,7 @@ package status
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	contour_api_v1 "github.com/projectcontour/contour/apis/projectcontour/v1"
@@ -40,6 +41,10 @@ type ProxyUpdate struct {
 	TransitionTime v1.Time
 	Vhost          string
 
+	// IncludedBy holds the root HTTPProxies that include this
+	// HTTPProxy.
+	IncludedBy map[types.NamespacedName]bool
+
 	// Conditions holds all the DetailedConditions to add to the object
 	// keyed by the Type (since that's what the apiserver will end up
 	// doing.)
@@ -69,8 +74,14 @@ func (c *Cache) commitProxy(pu *ProxyUpdate) {
 		return
 	}
 
-	_, ok := c.proxyUpdates[pu.Fullname]
+	existing, ok := c.proxyUpdates[pu.Fullname]
 	if ok {
+		// Retain the roots recorded by earlier visits to this proxy.
+		for root := range existing.IncludedBy {
+			pu.AddIncludedBy(root)
+		}
+		existing.IncludedBy = pu.IncludedBy
+
 		// When we're committing, if we already have a Valid Condition with an error, and we're trying to
 		// set the object back to Valid, skip the commit, as we've visited too far down.
 		// If this is removed, the status reporting for when a parent delegates to a child that delegates to itself
@@ -85,6 +96,15 @@ func (c *Cache) commitProxy(pu *ProxyUpdate) {
 	c.proxyUpdates[pu.Fullname] = pu
 }
 
+// AddIncludedBy records that the root HTTPProxy named root
+// includes this HTTPProxy.
+func (pu *ProxyUpdate) AddIncludedBy(root types.NamespacedName) {
+	if pu.IncludedBy == nil {
+		pu.IncludedBy = make(map[types.NamespacedName]bool)
+	}
+	pu.IncludedBy[root] = true
+}
+
 // ConditionFor returns a DetailedCondition for a given ConditionType.
 // Currently only "Valid" is used.
 func (pu *ProxyUpdate) ConditionFor(cond ConditionType) *projectcontour.DetailedCondition {
@@ -136,6 +156,12 @@ func (pu *ProxyUpdate) Mutate(obj interface{}) interface{} {
 
 	}
 
+	proxy.Status.IncludedBy = nil
+	for root := range pu.IncludedBy {
+		proxy.Status.IncludedBy = append(proxy.Status.IncludedBy, root.String())
+	}
+	sort.Strings(proxy.Status.IncludedBy)
+
 	// Set the old status fields using the Valid DetailedCondition's details.
 	// Other conditions are not relevant for these two fields.
 	validCond := proxy.Status.GetConditionFor(projectcontour.ValidConditionType)
@@ -21,6 +21,7 @@ import (
 	"github.com/projectcontour/contour/internal/k8s"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestConditionFor(t *testing.T) {
@@ -312,3 +313,32 @@ func TestStatusMutator(t *testing.T) {
 
 	run("Test updating existing Valid Condition", updateExistingValidCond)
 }
+
+func TestProxyIncludedBy(t *testing.T) {
+	proxy := &contour_api_v1.HTTPProxy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "child",
+			Namespace: "teams",
+		},
+		Status: contour_api_v1.HTTPProxyStatus{
+			IncludedBy: []string{"stale/root"},
+		},
+	}
+
+	cache := NewCache(types.NamespacedName{})
+
+	// Each root that includes the proxy records itself on a new
+	// update, and the cache retains the roots from earlier updates.
+	for _, root := range []string{"roots/b", "roots/a", "roots/b"} {
+		pu, commit := cache.ProxyAccessor(proxy)
+		pu.AddIncludedBy(k8s.NamespacedNameFrom(root))
+		pu.ConditionFor(ValidCondition)
+		commit()
+	}
+
+	updates := cache.GetProxyUpdates()
+	assert.Len(t, updates, 1)
+
+	got := updates[0].Mutate(proxy).(*contour_api_v1.HTTPProxy)
+	assert.Equal(t, []string{"roots/a", "roots/b"}, got.Status.IncludedBy)
+}
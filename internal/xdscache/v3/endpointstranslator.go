@@ -52,8 +52,10 @@ func RecalculateEndpoints(port v1.ServicePort, ep *v1.Endpoints) []*LoadBalancin
 		}
 
 		for _, p := range s.Ports {
-			if port.Protocol != p.Protocol && p.Protocol != v1.ProtocolTCP {
-				// NOTE: we only support "TCP", which is the default.
+			// Only take endpoint ports with the protocol of
+			// the service port, since a TCP and a UDP port
+			// may share the same number.
+			if portProtocol(port.Protocol) != portProtocol(p.Protocol) {
 				continue
 			}
 
@@ -79,6 +81,15 @@ func RecalculateEndpoints(port v1.ServicePort, ep *v1.Endpoints) []*LoadBalancin
 	return lb
 }
 
+// portProtocol returns the protocol of a service or endpoint
+// port, which defaults to TCP.
+func portProtocol(protocol v1.Protocol) v1.Protocol {
+	if protocol == "" {
+		return v1.ProtocolTCP
+	}
+	return protocol
+}
+
 // EndpointsCache is a cache of Endpoint and ServiceCluster objects.
 type EndpointsCache struct {
 	mu sync.Mutex // Protects all fields.
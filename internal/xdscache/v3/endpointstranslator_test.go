@@ -544,6 +544,74 @@ func TestEndpointsTranslatorRecomputeClusterLoadAssignment(t *testing.T) {
 				},
 			},
 		},
+		"UDP port": {
+			cluster: dag.ServiceCluster{
+				ClusterName: "default/dns/dns",
+				Services: []dag.WeightedService{{
+					Weight:           1,
+					ServiceName:      "dns",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{Name: "dns", Protocol: v1.ProtocolUDP},
+				}},
+			},
+			ep: endpoints("default", "dns", v1.EndpointSubset{
+				Addresses: addresses("192.168.183.24"),
+				Ports: ports(
+					udpPort("dns", 5353),
+				),
+			}),
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/dns/dns",
+					Endpoints: envoy_v3.WeightedEndpoints(1,
+						envoy_v3.SocketAddress("192.168.183.24", 5353)),
+				},
+			},
+		},
+		"UDP port ignores TCP endpoint port": {
+			cluster: dag.ServiceCluster{
+				ClusterName: "default/dns/dns",
+				Services: []dag.WeightedService{{
+					Weight:           1,
+					ServiceName:      "dns",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{Name: "dns", Protocol: v1.ProtocolUDP},
+				}},
+			},
+			ep: endpoints("default", "dns", v1.EndpointSubset{
+				Addresses: addresses("192.168.183.24"),
+				Ports: ports(
+					port("dns", 5353),
+				),
+			}),
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/dns/dns",
+				},
+			},
+		},
+		"TCP port ignores UDP endpoint port": {
+			cluster: dag.ServiceCluster{
+				ClusterName: "default/dns/dns",
+				Services: []dag.WeightedService{{
+					Weight:           1,
+					ServiceName:      "dns",
+					ServiceNamespace: "default",
+					ServicePort:      v1.ServicePort{Name: "dns"},
+				}},
+			},
+			ep: endpoints("default", "dns", v1.EndpointSubset{
+				Addresses: addresses("192.168.183.24"),
+				Ports: ports(
+					udpPort("dns", 5353),
+				),
+			}),
+			want: []proto.Message{
+				&envoy_endpoint_v3.ClusterLoadAssignment{
+					ClusterName: "default/dns/dns",
+				},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -824,6 +892,14 @@ func port(name string, port int32) v1.EndpointPort {
 	}
 }
 
+func udpPort(name string, port int32) v1.EndpointPort {
+	return v1.EndpointPort{
+		Name:     name,
+		Port:     port,
+		Protocol: "UDP",
+	}
+}
+
 func clusterloadassignments(clas ...*envoy_endpoint_v3.ClusterLoadAssignment) map[string]*envoy_endpoint_v3.ClusterLoadAssignment {
 	m := make(map[string]*envoy_endpoint_v3.ClusterLoadAssignment)
 	for _, cla := range clas {
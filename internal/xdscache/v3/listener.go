@@ -19,6 +19,7 @@ import (
 	"sync"
 
 	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	http "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
@@ -39,6 +40,7 @@ const (
 	ENVOY_HTTP_LISTENER            = "ingress_http"
 	ENVOY_FALLBACK_ROUTECONFIG     = "ingress_fallbackcert"
 	ENVOY_HTTPS_LISTENER           = "ingress_https"
+	ENVOY_HTTP3_LISTENER           = "ingress_http3"
 	DEFAULT_HTTP_ACCESS_LOG        = "/dev/stdout"
 	DEFAULT_HTTP_LISTENER_ADDRESS  = "0.0.0.0"
 	DEFAULT_HTTP_LISTENER_PORT     = 8080
@@ -51,6 +53,11 @@ type Listener struct {
 	Name    string
 	Address string
 	Port    int
+
+	// UseProxyProto configures the listener to expect a PROXY
+	// V1 or V2 preamble, even if ListenerConfig.UseProxyProto
+	// is not set.
+	UseProxyProto bool
 }
 
 // ListenerConfig holds configuration parameters for building Envoy Listeners.
@@ -74,6 +81,17 @@ type ListenerConfig struct {
 	// If not set, defaults to DEFAULT_HTTPS_ACCESS_LOG.
 	HTTPSAccessLog string
 
+	// Envoy's plain TCP listener addresses. Each proxies all of
+	// its connections to the TCP proxy of the single virtual host
+	// bound to it, and is only added if there is one. TCP proxies
+	// use the HTTPS access log.
+	TCPListeners map[string]Listener
+
+	// Envoy's UDP listener addresses. Each forwards all of its
+	// datagrams to the UDP proxy bound to it, and is only added
+	// if there is one.
+	UDPListeners map[string]Listener
+
 	// UseProxyProto configures all listeners to expect a PROXY
 	// V1 or V2 preamble.
 	// If not set, defaults to false.
@@ -93,6 +111,12 @@ type ListenerConfig struct {
 	// HTTPS, because we don't support h2c.
 	DefaultHTTPVersions []envoy_v3.HTTPVersionType
 
+	// HTTP3 enables a QUIC listener for HTTP/3 on the UDP port
+	// with the same address and number as the HTTPS listener.
+	// Secure virtual hosts that terminate TLS without client
+	// certificate validation are served over HTTP/3.
+	HTTP3 bool
+
 	// AccessLogType defines if Envoy logs should be output as Envoy's default or JSON.
 	// Valid values: 'envoy', 'json'
 	// If not set, defaults to 'envoy'
@@ -199,7 +223,7 @@ func (lvc *ListenerConfig) SecureListeners() map[string]*envoy_listener_v3.Liste
 			l.Name,
 			l.Address,
 			l.Port,
-			secureProxyProtocol(lvc.UseProxyProto),
+			secureProxyProtocol(lvc.UseProxyProto || l.UseProxyProto),
 		)
 	}
 
@@ -350,24 +374,44 @@ func (c *ListenerCache) OnChange(root *dag.DAG) {
 type listenerVisitor struct {
 	*ListenerConfig
 
-	listeners        map[string]*envoy_listener_v3.Listener
-	httpListenerName string // Name of dag.VirtualHost encountered.
+	listeners         map[string]*envoy_listener_v3.Listener
+	httpListenerNames map[string]bool // Names of the listeners of dag.VirtualHosts encountered.
+
+	// ipFilterFilter is the RBAC filter for the HTTP connection
+	// managers that are shared between virtual hosts. It is nil
+	// if no route has an IP filter policy.
+	ipFilterFilter *http.HttpFilter
 }
 
 func visitListeners(root dag.Vertex, lvc *ListenerConfig) map[string]*envoy_listener_v3.Listener {
 	lv := listenerVisitor{
-		ListenerConfig: lvc.DefaultListeners(),
-		listeners:      lvc.SecureListeners(),
+		ListenerConfig:    lvc.DefaultListeners(),
+		listeners:         lvc.SecureListeners(),
+		httpListenerNames: map[string]bool{},
+	}
+
+	if hasRoute(root, hasIPFilterPolicy) {
+		lv.ipFilterFilter = envoy_v3.FilterIPFilter()
+	}
+
+	if lvc.HTTP3 {
+		https := lv.HTTPSListeners[ENVOY_HTTPS_LISTENER]
+		lv.listeners[ENVOY_HTTP3_LISTENER] = envoy_v3.QUICListener(ENVOY_HTTP3_LISTENER, https.Address, https.Port)
 	}
 
 	lv.visit(root)
 
-	if httpListener, ok := lvc.HTTPListeners[lv.httpListenerName]; ok {
+	// Add the http listeners that have vhosts bound to them.
+	for name := range lv.httpListenerNames {
+		httpListener, ok := lvc.HTTPListeners[name]
+		if !ok {
+			continue
+		}
 
-		// Add a listener if there are vhosts bound to http.
 		cm := envoy_v3.HTTPConnectionManagerBuilder().
 			Codec(envoy_v3.CodecForVersions(lv.DefaultHTTPVersions...)).
 			DefaultFilters().
+			AddFilter(lv.ipFilterFilter).
 			RouteConfigName(httpListener.Name).
 			MetricsPrefix(httpListener.Name).
 			AccessLoggers(lvc.newInsecureAccessLog()).
@@ -386,18 +430,28 @@ func visitListeners(root dag.Vertex, lvc *ListenerConfig) map[string]*envoy_list
 			httpListener.Name,
 			httpListener.Address,
 			httpListener.Port,
-			proxyProtocol(lvc.UseProxyProto),
+			proxyProtocol(lvc.UseProxyProto || httpListener.UseProxyProto),
 			cm,
 		)
 	}
 
-	// Remove the https listener if there are no vhosts bound to it.
-	if len(lv.listeners[ENVOY_HTTPS_LISTENER].FilterChains) == 0 {
-		delete(lv.listeners, ENVOY_HTTPS_LISTENER)
-	} else {
-		// there's some https listeners, we need to sort the filter chains
-		// to ensure that the LDS entries are identical.
-		sort.Stable(sorter.For(lv.listeners[ENVOY_HTTPS_LISTENER].FilterChains))
+	// Remove the https and http3 listeners if there are no vhosts bound to them.
+	secureListenerNames := []string{ENVOY_HTTP3_LISTENER}
+	for name := range lvc.HTTPSListeners {
+		secureListenerNames = append(secureListenerNames, name)
+	}
+	for _, name := range secureListenerNames {
+		listener, ok := lv.listeners[name]
+		if !ok {
+			continue
+		}
+		if len(listener.FilterChains) == 0 {
+			delete(lv.listeners, name)
+		} else {
+			// there's some https listeners, we need to sort the filter chains
+			// to ensure that the LDS entries are identical.
+			sort.Stable(sorter.For(listener.FilterChains))
+		}
 	}
 
 	// support more params of envoy listener
@@ -406,6 +460,10 @@ func visitListeners(root dag.Vertex, lvc *ListenerConfig) map[string]*envoy_list
 	switch lvc.ConnectionBalancer {
 	case "exact":
 		for _, listener := range lv.listeners {
+			// Connections are only balanced on TCP listeners.
+			if listener.Address.GetSocketAddress().GetProtocol() == envoy_core_v3.SocketAddress_UDP {
+				continue
+			}
 			listener.ConnectionBalanceConfig = &envoy_listener_v3.Listener_ConnectionBalanceConfig{
 				BalanceType: &envoy_listener_v3.Listener_ConnectionBalanceConfig_ExactBalance_{
 					ExactBalance: &envoy_listener_v3.Listener_ConnectionBalanceConfig_ExactBalance{},
@@ -454,23 +512,28 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 
 	switch vh := vertex.(type) {
 	case *dag.VirtualHost:
-		// we only create on http listener so record the fact
-		// that we need to then double back at the end and add
-		// the listener properly
-		v.httpListenerName = vh.ListenerName
+		// record the http listeners that vhosts are bound to
+		// so that we can double back at the end and add the
+		// listeners properly
+		v.httpListenerNames[vh.ListenerName] = true
 	case *dag.SecureVirtualHost:
 		var alpnProtos []string
 		var filters []*envoy_listener_v3.Filter
+		var httpConnectionManager func(codec envoy_v3.HTTPVersionType, metricsPrefix string) *envoy_listener_v3.Filter
 
 		if vh.TCPProxy == nil {
-			var authFilter *http.HttpFilter
+			// IP filters and basic authentication are
+			// only enforced on routes that are configured
+			// for them, so the filters are only needed if
+			// some route is.
+			var ipFilterFilter *http.HttpFilter
+			if hasRoute(vh, hasIPFilterPolicy) {
+				ipFilterFilter = envoy_v3.FilterIPFilter()
+			}
 
-			if vh.AuthorizationService != nil {
-				authFilter = envoy_v3.FilterExternalAuthz(
-					vh.AuthorizationService.Name,
-					vh.AuthorizationFailOpen,
-					vh.AuthorizationResponseTimeout,
-				)
+			var basicAuthFilter *http.HttpFilter
+			if hasRoute(vh, func(r *dag.Route) bool { return r.BasicAuth != nil }) {
+				basicAuthFilter = envoy_v3.FilterBasicAuth()
 			}
 
 			// Create a uniquely named HTTP connection manager for
@@ -480,30 +543,43 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			// metrics prefix to keep compatibility with previous
 			// Contour versions since the metrics prefix will be
 			// coded into monitoring dashboards.
-			cm := envoy_v3.HTTPConnectionManagerBuilder().
-				Codec(envoy_v3.CodecForVersions(v.DefaultHTTPVersions...)).
-				AddFilter(envoy_v3.FilterMisdirectedRequests(vh.VirtualHost.Name)).
-				DefaultFilters().
-				AddFilter(authFilter).
-				RouteConfigName(path.Join("https", vh.VirtualHost.Name)).
-				MetricsPrefix(vh.ListenerName).
-				AccessLoggers(v.ListenerConfig.newSecureAccessLog()).
-				RequestTimeout(v.ListenerConfig.RequestTimeout).
-				ConnectionIdleTimeout(v.ListenerConfig.ConnectionIdleTimeout).
-				StreamIdleTimeout(v.ListenerConfig.StreamIdleTimeout).
-				DelayedCloseTimeout(v.ListenerConfig.DelayedCloseTimeout).
-				MaxConnectionDuration(v.ListenerConfig.MaxConnectionDuration).
-				ConnectionShutdownGracePeriod(v.ListenerConfig.ConnectionShutdownGracePeriod).
-				AllowChunkedLength(v.ListenerConfig.AllowChunkedLength).
-				NumTrustedHops(v.ListenerConfig.XffNumTrustedHops).
-				AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(v.RateLimitConfig))).
-				Get()
+			httpConnectionManager = func(codec envoy_v3.HTTPVersionType, metricsPrefix string) *envoy_listener_v3.Filter {
+				return envoy_v3.HTTPConnectionManagerBuilder().
+					Codec(codec).
+					AddFilter(envoy_v3.FilterMisdirectedRequests(vh.VirtualHost.Name)).
+					DefaultFilters().
+					AddFilter(ipFilterFilter).
+					AddFilter(envoy_v3.FilterJWTAuthN(vh.JWTProviders)).
+					AddFilter(envoy_v3.FilterJWTClaimsToHeaders(vh.JWTProviders)).
+					AddFilter(basicAuthFilter).
+					AddFilters(authorizationFilters(vh)...).
+					ForwardClientCertificate(vh.ForwardClientCertificate).
+					RouteConfigName(secureRouteConfigName(vh)).
+					MetricsPrefix(metricsPrefix).
+					AccessLoggers(v.ListenerConfig.newSecureAccessLog()).
+					RequestTimeout(v.ListenerConfig.RequestTimeout).
+					ConnectionIdleTimeout(v.ListenerConfig.ConnectionIdleTimeout).
+					StreamIdleTimeout(v.ListenerConfig.StreamIdleTimeout).
+					DelayedCloseTimeout(v.ListenerConfig.DelayedCloseTimeout).
+					MaxConnectionDuration(v.ListenerConfig.MaxConnectionDuration).
+					ConnectionShutdownGracePeriod(v.ListenerConfig.ConnectionShutdownGracePeriod).
+					AllowChunkedLength(v.ListenerConfig.AllowChunkedLength).
+					NumTrustedHops(v.ListenerConfig.XffNumTrustedHops).
+					AddFilter(envoy_v3.GlobalRateLimitFilter(envoyGlobalRateLimitConfig(v.RateLimitConfig))).
+					Get()
+			}
 
-			filters = envoy_v3.Filters(cm)
+			filters = envoy_v3.Filters(httpConnectionManager(envoy_v3.CodecForVersions(v.DefaultHTTPVersions...), vh.ListenerName))
 
 			alpnProtos = envoy_v3.ProtoNamesForVersions(v.DefaultHTTPVersions...)
 		} else {
-			filters = envoy_v3.Filters(
+			if vh.TCPProxy.IPFilterPolicy != nil {
+				filters = envoy_v3.Filters(
+					envoy_v3.IPFilter(vh.ListenerName, vh.TCPProxy.IPFilterPolicy),
+				)
+			}
+
+			filters = append(filters,
 				envoy_v3.TCPProxy(vh.ListenerName,
 					vh.TCPProxy,
 					v.ListenerConfig.newSecureAccessLog()),
@@ -514,6 +590,19 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			// backend in its ServerHello.
 		}
 
+		// A plain TCP listener has a single filter chain without
+		// TLS or SNI, which proxies all of its connections.
+		if tcpListener, ok := v.TCPListeners[vh.ListenerName]; ok {
+			v.listeners[tcpListener.Name] = envoy_v3.Listener(
+				tcpListener.Name,
+				tcpListener.Address,
+				tcpListener.Port,
+				proxyProtocol(v.UseProxyProto || tcpListener.UseProxyProto),
+				filters...,
+			)
+			return
+		}
+
 		var downstreamTLS *envoy_tls_v3.DownstreamTlsContext
 
 		// Secret is provided when TLS is terminated and nil when TLS passthrough is used.
@@ -521,17 +610,50 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			// Choose the higher of the configured or requested TLS version.
 			vers := max(v.ListenerConfig.minTLSVersion(), envoy_v3.ParseTLSVersion(vh.MinTLSVersion))
 
+			// The maximum TLS version defaults to 1.3, and cannot
+			// be lower than the minimum.
+			maxVers := envoy_tls_v3.TlsParameters_TLSv1_3
+			if vh.MaxTLSVersion != "" {
+				maxVers = envoy_v3.ParseTLSVersion(vh.MaxTLSVersion)
+				if maxVers < vers {
+					maxVers = vers
+				}
+			}
+
+			// The cipher suites of the virtual host replace the
+			// configured cipher suites.
+			cipherSuites := v.ListenerConfig.CipherSuites
+			if len(vh.CipherSuites) > 0 {
+				cipherSuites = vh.CipherSuites
+			}
+
 			downstreamTLS = envoy_v3.DownstreamTLSContext(
-				vh.Secret,
+				append([]*dag.Secret{vh.Secret}, vh.AdditionalSecrets...),
 				vers,
-				v.ListenerConfig.CipherSuites,
+				maxVers,
+				cipherSuites,
 				vh.DownstreamValidation,
 				alpnProtos...)
+			downstreamTLS.OcspStaplePolicy = envoy_v3.ParseOCSPStaplePolicy(vh.OCSPStaplePolicy)
+			if vh.SessionTicketKeys != nil {
+				downstreamTLS.SessionTicketKeysType = envoy_v3.SessionTicketKeys(vh.SessionTicketKeys)
+			}
 		}
 
 		v.listeners[vh.ListenerName].FilterChains = append(v.listeners[vh.ListenerName].FilterChains,
 			envoy_v3.FilterChainTLS(vh.VirtualHost.Name, downstreamTLS, filters))
 
+		// QUIC negotiates its own ALPN protocol, so the
+		// virtual host's TLS context is reused without it.
+		if http3, ok := v.listeners[ENVOY_HTTP3_LISTENER]; ok && servesHTTP3(vh) {
+			quicTLS := proto.Clone(downstreamTLS).(*envoy_tls_v3.DownstreamTlsContext)
+			quicTLS.CommonTlsContext.AlpnProtocols = nil
+
+			http3.FilterChains = append(http3.FilterChains,
+				envoy_v3.FilterChainQUIC(vh.VirtualHost.Name, quicTLS,
+					envoy_v3.Filters(httpConnectionManager(envoy_v3.HTTPVersion3, ENVOY_HTTP3_LISTENER))))
+		}
+
 		// If this VirtualHost has enabled the fallback certificate then set a default
 		// FilterChain which will allow routes with this vhost to accept non-SNI TLS requests.
 		// Note that we don't add the misdirected requests filter on this chain because at this
@@ -541,14 +663,16 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			// Construct the downstreamTLSContext passing the configured fallbackCertificate. The TLS minProtocolVersion will use
 			// the value defined in the Contour Configuration file if defined.
 			downstreamTLS = envoy_v3.DownstreamTLSContext(
-				vh.FallbackCertificate,
+				[]*dag.Secret{vh.FallbackCertificate},
 				v.ListenerConfig.minTLSVersion(),
+				envoy_tls_v3.TlsParameters_TLSv1_3,
 				v.ListenerConfig.CipherSuites,
 				vh.DownstreamValidation,
 				alpnProtos...)
 
 			cm := envoy_v3.HTTPConnectionManagerBuilder().
 				DefaultFilters().
+				AddFilter(v.ipFilterFilter).
 				RouteConfigName(ENVOY_FALLBACK_ROUTECONFIG).
 				MetricsPrefix(vh.ListenerName).
 				AccessLoggers(v.ListenerConfig.newSecureAccessLog()).
@@ -570,8 +694,109 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 				envoy_v3.FilterChainTLSFallback(downstreamTLS, filters))
 		}
 
+	case *dag.UDPProxy:
+		if udpListener, ok := v.UDPListeners[vh.ListenerName]; ok {
+			v.listeners[udpListener.Name] = envoy_v3.UDPListener(
+				udpListener.Name,
+				udpListener.Address,
+				udpListener.Port,
+				vh,
+			)
+		}
 	default:
 		// recurse
 		vertex.Visit(v.visit)
 	}
 }
+
+// secureRouteConfigName returns the name of the route configuration
+// of the secure virtual host. The route configurations of the default
+// HTTPS listener are named "https/<vhost>" for compatibility with
+// previous Contour versions.
+func secureRouteConfigName(vh *dag.SecureVirtualHost) string {
+	if vh.ListenerName == ENVOY_HTTPS_LISTENER {
+		return path.Join("https", vh.VirtualHost.Name)
+	}
+	return path.Join(vh.ListenerName, vh.VirtualHost.Name)
+}
+
+// servesHTTP3 returns true if the secure virtual host is also served
+// over HTTP/3. Envoy must terminate TLS for HTTP/3, client
+// certificates are not validated over QUIC, and QUIC requires
+// TLS 1.3.
+func servesHTTP3(vh *dag.SecureVirtualHost) bool {
+	return vh.ListenerName == ENVOY_HTTPS_LISTENER &&
+		vh.TCPProxy == nil &&
+		vh.Secret != nil &&
+		vh.DownstreamValidation == nil &&
+		vh.MaxTLSVersion != "1.2"
+}
+
+// hasRoute returns true if any route that is reachable from the
+// vertex satisfies the predicate.
+func hasRoute(vertex dag.Vertex, predicate func(*dag.Route) bool) bool {
+	found := false
+
+	var visit func(dag.Vertex)
+	visit = func(v dag.Vertex) {
+		if r, ok := v.(*dag.Route); ok {
+			found = found || predicate(r)
+			return
+		}
+		v.Visit(visit)
+	}
+	vertex.Visit(visit)
+
+	return found
+}
+
+// authorizationFilters returns the `ext_authz` filters for the
+// authorization servers of a secure virtual host. If no route
+// overrides the authorization server of the virtual host, a single
+// filter authorizes all requests. Otherwise there is a filter for
+// each server, and the virtual host and route configuration select
+// which of them authorizes a request.
+func authorizationFilters(vh *dag.SecureVirtualHost) []*http.HttpFilter {
+	if vh.AuthorizationService == nil {
+		return nil
+	}
+
+	servers := map[string]*dag.ExternalAuthorization{}
+
+	var visit func(dag.Vertex)
+	visit = func(v dag.Vertex) {
+		if r, ok := v.(*dag.Route); ok {
+			if r.AuthorizationServer != nil {
+				servers[envoy_v3.AuthorizationServerName(r.AuthorizationServer)] = r.AuthorizationServer
+			}
+			return
+		}
+		v.Visit(visit)
+	}
+	vh.Visit(visit)
+
+	if len(servers) == 0 {
+		return []*http.HttpFilter{
+			envoy_v3.FilterExternalAuthz(&vh.ExternalAuthorization),
+		}
+	}
+
+	servers[envoy_v3.AuthorizationServerName(&vh.ExternalAuthorization)] = &vh.ExternalAuthorization
+
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	filters := []*http.HttpFilter{envoy_v3.FilterAuthzServerSelector()}
+	for _, name := range names {
+		filters = append(filters, envoy_v3.FilterExternalAuthzSelected(servers[name]))
+	}
+
+	return filters
+}
+
+func hasIPFilterPolicy(r *dag.Route) bool {
+	return r.IPFilterPolicy != nil
+}
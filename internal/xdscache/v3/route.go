@@ -14,7 +14,6 @@
 package v3
 
 import (
-	"path"
 	"sort"
 	"sync"
 
@@ -34,6 +33,12 @@ type RouteCache struct {
 	mu     sync.Mutex
 	values map[string]*envoy_route_v3.RouteConfiguration
 	contour.Cond
+
+	// HTTP3AdvertisedPort is the UDP port that clients reach the
+	// HTTP/3 listener on. If set, responses from the secure virtual
+	// hosts that are served over HTTP/3 advertise it in the alt-svc
+	// header.
+	HTTP3AdvertisedPort int
 }
 
 // Update replaces the contents of the cache with the supplied map.
@@ -89,17 +94,19 @@ func (c *RouteCache) Query(names []string) []proto.Message {
 func (*RouteCache) TypeURL() string { return resource.RouteType }
 
 func (c *RouteCache) OnChange(root *dag.DAG) {
-	routes := visitRoutes(root)
+	routes := visitRoutes(root, c.HTTP3AdvertisedPort)
 	c.Update(routes)
 }
 
 type routeVisitor struct {
-	routes map[string]*envoy_route_v3.RouteConfiguration
+	routes    map[string]*envoy_route_v3.RouteConfiguration
+	http3Port int
 }
 
-func visitRoutes(root dag.Vertex) map[string]*envoy_route_v3.RouteConfiguration {
+func visitRoutes(root dag.Vertex, http3Port int) map[string]*envoy_route_v3.RouteConfiguration {
 	// Collect the route configurations for all the routes we can
 	// find. For HTTP hosts, the routes will all be collected on the
+	// route configuration named after their listener, such as the
 	// well-known ENVOY_HTTP_LISTENER, but for HTTPS hosts, we will
 	// generate a per-vhost collection. This lets us keep different
 	// SNI names disjoint when we later configure the listener.
@@ -107,6 +114,7 @@ func visitRoutes(root dag.Vertex) map[string]*envoy_route_v3.RouteConfiguration
 		routes: map[string]*envoy_route_v3.RouteConfiguration{
 			ENVOY_HTTP_LISTENER: envoy_v3.RouteConfiguration(ENVOY_HTTP_LISTENER),
 		},
+		http3Port: http3Port,
 	}
 
 	rv.visit(root)
@@ -169,12 +177,30 @@ func (v *routeVisitor) onVirtualHost(vh *dag.VirtualHost) {
 			}
 			rt.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"] = envoy_v3.LocalRateLimitConfig(route.RateLimitPolicy.Local, "vhost."+vh.Name)
 		}
+		if route.IPFilterPolicy != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig["envoy.filters.http.rbac"] = envoy_v3.RouteIPFilter(route.IPFilterPolicy)
+		}
+		if route.CSRFPolicy != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig["envoy.filters.http.csrf"] = envoy_v3.CSRFConfig(route.CSRFPolicy)
+		}
 		return rt
 
 	}
 
+	// Add the route config of the vhost's listener if not already present.
+	name := vh.ListenerName
+	if _, ok := v.routes[name]; !ok {
+		v.routes[name] = envoy_v3.RouteConfiguration(name)
+	}
+
 	sortRoutes(routes)
-	v.routes[ENVOY_HTTP_LISTENER].VirtualHosts = append(v.routes[ENVOY_HTTP_LISTENER].VirtualHosts, toEnvoyVirtualHost(vh, routes, toEnvoyRoute))
+	v.routes[name].VirtualHosts = append(v.routes[name].VirtualHosts, toEnvoyVirtualHost(vh, routes, toEnvoyRoute))
 }
 
 func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
@@ -219,6 +245,18 @@ func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
 			}
 			rt.TypedPerFilterConfig["envoy.filters.http.local_ratelimit"] = envoy_v3.LocalRateLimitConfig(route.RateLimitPolicy.Local, "vhost."+svh.Name)
 		}
+		if route.IPFilterPolicy != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig["envoy.filters.http.rbac"] = envoy_v3.RouteIPFilter(route.IPFilterPolicy)
+		}
+		if route.CSRFPolicy != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig["envoy.filters.http.csrf"] = envoy_v3.CSRFConfig(route.CSRFPolicy)
+		}
 
 		// If authorization is enabled on this host, we may need to set per-route filter overrides.
 		if svh.AuthorizationService != nil {
@@ -235,20 +273,79 @@ func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
 					}
 					rt.TypedPerFilterConfig["envoy.filters.http.ext_authz"] = envoy_v3.RouteAuthzContext(route.AuthContext)
 				}
+				if route.AuthorizationServer != nil {
+					if rt.TypedPerFilterConfig == nil {
+						rt.TypedPerFilterConfig = map[string]*any.Any{}
+					}
+					rt.TypedPerFilterConfig["envoy.filters.http.header_to_metadata"] = envoy_v3.RouteAuthzServer(route.AuthorizationServer)
+				}
 			}
 		}
 
+		// Routes without a JWT provider have no requirement, so
+		// the jwt_authn filter does not verify their requests.
+		if route.JWTProvider != "" {
+			requirement := route.JWTProvider
+			if route.JWTAllowMissing {
+				requirement = envoy_v3.JWTAllowMissingRequirement(route.JWTProvider)
+			}
+
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig["envoy.filters.http.jwt_authn"] = envoy_v3.RouteJWTRequirement(requirement)
+		}
+
+		if route.BasicAuth != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig["envoy.filters.http.ext_authz"] = envoy_v3.RouteBasicAuth(route.BasicAuth)
+		}
+
 		return rt
 	}
 
 	// Add secure vhost route config if not already present.
-	name := path.Join("https", svh.VirtualHost.Name)
+	name := secureRouteConfigName(svh)
 	if _, ok := v.routes[name]; !ok {
 		v.routes[name] = envoy_v3.RouteConfiguration(name)
 	}
 
 	sortRoutes(routes)
-	v.routes[name].VirtualHosts = append(v.routes[name].VirtualHosts, toEnvoyVirtualHost(&svh.VirtualHost, routes, toEnvoyRoute))
+	evh := toEnvoyVirtualHost(&svh.VirtualHost, routes, toEnvoyRoute)
+
+	// If any route overrides the authorization server, the
+	// virtual host selects the default server for the routes
+	// that don't.
+	if svh.AuthorizationService != nil && hasAuthorizationServerOverride(routes) {
+		if evh.TypedPerFilterConfig == nil {
+			evh.TypedPerFilterConfig = map[string]*any.Any{}
+		}
+		evh.TypedPerFilterConfig["envoy.filters.http.header_to_metadata"] = envoy_v3.RouteAuthzServer(&svh.ExternalAuthorization)
+	}
+
+	// Basic authentication is only checked on the routes that
+	// require it, so it is disabled for the rest of the virtual host.
+	if hasBasicAuth(routes) {
+		if evh.TypedPerFilterConfig == nil {
+			evh.TypedPerFilterConfig = map[string]*any.Any{}
+		}
+		evh.TypedPerFilterConfig["envoy.filters.http.ext_authz"] = envoy_v3.RouteAuthzDisabled()
+	}
+
+	// The HSTS header is only added to responses served over TLS.
+	if svh.HSTSPolicy != nil {
+		evh.ResponseHeadersToAdd = envoy_v3.HSTSHeaders(svh.HSTSPolicy)
+	}
+
+	// Clients discover HTTP/3 from the alt-svc header of
+	// responses served over TCP.
+	if v.http3Port != 0 && servesHTTP3(svh) {
+		evh.ResponseHeadersToAdd = append(evh.ResponseHeadersToAdd, envoy_v3.AltSvcHeaders(v.http3Port)...)
+	}
+
+	v.routes[name].VirtualHosts = append(v.routes[name].VirtualHosts, evh)
 
 	// A fallback route configuration contains routes for all the vhosts that have the fallback certificate enabled.
 	// When a request is received, the default TLS filterchain will accept the connection,
@@ -259,7 +356,12 @@ func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
 			v.routes[ENVOY_FALLBACK_ROUTECONFIG] = envoy_v3.RouteConfiguration(ENVOY_FALLBACK_ROUTECONFIG)
 		}
 
-		v.routes[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts = append(v.routes[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts, toEnvoyVirtualHost(&svh.VirtualHost, routes, toEnvoyRoute))
+		fallback := toEnvoyVirtualHost(&svh.VirtualHost, routes, toEnvoyRoute)
+		if svh.HSTSPolicy != nil {
+			fallback.ResponseHeadersToAdd = envoy_v3.HSTSHeaders(svh.HSTSPolicy)
+		}
+
+		v.routes[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts = append(v.routes[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts, fallback)
 	}
 }
 
@@ -284,9 +386,10 @@ func (v *routeVisitor) visit(vertex dag.Vertex) {
 }
 
 // sortRoutes sorts the given Route slice in place. Routes are ordered
-// first by path match type, path match value via string comparison and
-// then by the length of the HeaderMatch slice (if any). The HeaderMatch
-// slice is also ordered by the matching header name.
+// first by descending priority, then by path match type, path match
+// value via string comparison and then by the length of the HeaderMatch
+// slice (if any). The HeaderMatch slice is also ordered by the matching
+// header name.
 // We sort dag.Route objects before converting to Envoy types to ensure
 // more accurate ordering of route matches. Contour route match types may
 // be implemented by Envoy route match types that change over time, or by
@@ -303,6 +406,24 @@ func sortRoutes(routes []*dag.Route) {
 	sort.Stable(sorter.For(routes))
 }
 
+func hasAuthorizationServerOverride(routes []*dag.Route) bool {
+	for _, r := range routes {
+		if r.AuthorizationServer != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBasicAuth(routes []*dag.Route) bool {
+	for _, r := range routes {
+		if r.BasicAuth != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // toEnvoyVirtualHost converts a DAG virtual host and routes to an Envoy virtual host.
 func toEnvoyVirtualHost(vh *dag.VirtualHost, routes []*dag.Route, toEnvoyRoute func(*dag.Route) *envoy_route_v3.Route) *envoy_route_v3.VirtualHost {
 	var envoyRoutes []*envoy_route_v3.Route
@@ -325,5 +446,12 @@ func toEnvoyVirtualHost(vh *dag.VirtualHost, routes []*dag.Route, toEnvoyRoute f
 		evh.RateLimits = envoy_v3.GlobalRateLimits(vh.RateLimitPolicy.Global.Descriptors)
 	}
 
+	if vh.CSRFPolicy != nil {
+		if evh.TypedPerFilterConfig == nil {
+			evh.TypedPerFilterConfig = map[string]*any.Any{}
+		}
+		evh.TypedPerFilterConfig["envoy.filters.http.csrf"] = envoy_v3.CSRFConfig(vh.CSRFPolicy)
+	}
+
 	return evh
 }
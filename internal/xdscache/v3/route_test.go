@@ -2938,7 +2938,7 @@ func TestRouteVisit(t *testing.T) {
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			root := buildDAGFallback(t, tc.fallbackCertificate, tc.objs...)
-			got := visitRoutes(root)
+			got := visitRoutes(root, 0)
 			protobuf.ExpectEqual(t, tc.want, got)
 		})
 	}
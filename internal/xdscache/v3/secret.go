@@ -100,15 +100,28 @@ func (v *secretVisitor) addSecret(s *dag.Secret) {
 	}
 }
 
+func (v *secretVisitor) addSessionTicketKeys(s *dag.Secret) {
+	name := envoy.SessionTicketKeysSecretname(s)
+	if _, ok := v.secrets[name]; !ok {
+		v.secrets[name] = envoy_v3.SessionTicketKeysSecret(s)
+	}
+}
+
 func (v *secretVisitor) visit(vertex dag.Vertex) {
 	switch obj := vertex.(type) {
 	case *dag.SecureVirtualHost:
 		if obj.Secret != nil {
 			v.addSecret(obj.Secret)
 		}
+		for _, secret := range obj.AdditionalSecrets {
+			v.addSecret(secret)
+		}
 		if obj.FallbackCertificate != nil {
 			v.addSecret(obj.FallbackCertificate)
 		}
+		if obj.SessionTicketKeys != nil {
+			v.addSessionTicketKeys(obj.SessionTicketKeys)
+		}
 	case *dag.Cluster:
 		if obj.ClientCertificate != nil {
 			v.addSecret(obj.ClientCertificate)
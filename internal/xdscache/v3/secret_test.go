@@ -486,7 +486,9 @@ func buildDAG(t *testing.T, objs ...interface{}) *dag.DAG {
 			&dag.IngressProcessor{
 				FieldLogger: fixture.NewTestLogger(t),
 			},
-			&dag.HTTPProxyProcessor{},
+			&dag.HTTPProxyProcessor{
+				Clock: fixture.Clock,
+			},
 			&dag.ListenerProcessor{},
 		},
 	}
@@ -508,6 +510,7 @@ func buildDAGFallback(t *testing.T, fallbackCertificate *types.NamespacedName, o
 				FieldLogger: fixture.NewTestLogger(t),
 			},
 			&dag.HTTPProxyProcessor{
+				Clock:               fixture.Clock,
 				FallbackCertificate: fallbackCertificate,
 			},
 			&dag.ListenerProcessor{},
@@ -17,9 +17,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -207,7 +209,7 @@ type HTTPVersionType string
 
 func (h HTTPVersionType) Validate() error {
 	switch h {
-	case HTTPVersion1, HTTPVersion2:
+	case HTTPVersion1, HTTPVersion2, HTTPVersion3:
 		return nil
 	default:
 		return fmt.Errorf("invalid HTTP version %q", h)
@@ -216,6 +218,7 @@ func (h HTTPVersionType) Validate() error {
 
 const HTTPVersion1 HTTPVersionType = "http/1.1"
 const HTTPVersion2 HTTPVersionType = "http/2"
+const HTTPVersion3 HTTPVersionType = "http/3"
 
 // NamespacedName defines the namespace/name of the Kubernetes resource referred from the configuration file.
 // Used for Contour configuration YAML file parsing, otherwise we could use K8s types.NamespacedName.
@@ -255,6 +258,17 @@ type TLSParameters struct {
 	// cluster.
 	ClientCertificate NamespacedName `yaml:"envoy-client-certificate,omitempty"`
 
+	// SessionTicketKeys defines the namespace/name of the Kubernetes
+	// secret containing the keys that Envoy uses to encrypt and
+	// decrypt TLS session tickets, so that sessions can be resumed
+	// on any Envoy.
+	SessionTicketKeys NamespacedName `yaml:"session-ticket-keys,omitempty"`
+
+	// CertificateExpiryWarning defines how long before a TLS
+	// certificate expires that a warning is added to the status
+	// of the HTTPProxies that serve it. Zero disables the warning.
+	CertificateExpiryWarning time.Duration `yaml:"certificate-expiry-warning,omitempty"`
+
 	// CipherSuites defines the TLS ciphers to be supported by Envoy TLS
 	// listeners when negotiating TLS 1.2. Ciphers are validated against the
 	// set that Envoy supports by default. This parameter should only be used
@@ -263,7 +277,7 @@ type TLSParameters struct {
 	CipherSuites TLSCiphers `yaml:"cipher-suites,omitempty"`
 }
 
-// Validate TLS fallback certificate, client certificate, and cipher suites
+// Validate TLS fallback certificate, client certificate, session ticket keys, certificate expiry warning, and cipher suites
 func (t TLSParameters) Validate() error {
 	// Check TLS secret names.
 	if err := t.FallbackCertificate.Validate(); err != nil {
@@ -274,6 +288,14 @@ func (t TLSParameters) Validate() error {
 		return fmt.Errorf("invalid TLS client certificate: %w", err)
 	}
 
+	if err := t.SessionTicketKeys.Validate(); err != nil {
+		return fmt.Errorf("invalid TLS session ticket keys: %w", err)
+	}
+
+	if t.CertificateExpiryWarning < 0 {
+		return fmt.Errorf("invalid TLS certificate expiry warning %q: must not be negative", t.CertificateExpiryWarning)
+	}
+
 	if err := t.CipherSuites.Validate(); err != nil {
 		return fmt.Errorf("invalid TLS cipher suites: %w", err)
 	}
@@ -430,6 +452,10 @@ type PolicyParameters struct {
 
 	// ResponseHeadersPolicy defines the response headers set/removed on all routes
 	ResponseHeadersPolicy HeadersPolicy `yaml:"response-headers,omitempty"`
+
+	// HSTSPolicy defines the default HTTP Strict Transport Security
+	// policy of HTTPProxy virtual hosts that terminate TLS.
+	HSTSPolicy *HSTSParameters `yaml:"hsts,omitempty"`
 }
 
 // Validate the header parameters.
@@ -440,6 +466,46 @@ func (h PolicyParameters) Validate() error {
 	if err := h.ResponseHeadersPolicy.Validate(); err != nil {
 		return err
 	}
+	if h.HSTSPolicy != nil {
+		if err := h.HSTSPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HSTSParameters defines the Strict-Transport-Security header
+// added to responses served over TLS.
+type HSTSParameters struct {
+	// MaxAge is how long browsers should only access the virtual
+	// host over TLS, e.g. "8760h". It is sent in whole seconds.
+	MaxAge string `yaml:"max-age"`
+
+	// IncludeSubDomains applies the policy to all subdomains of
+	// the virtual host.
+	IncludeSubDomains bool `yaml:"include-subdomains,omitempty"`
+
+	// Preload allows the virtual host to be included in browser
+	// HSTS preload lists.
+	Preload bool `yaml:"preload,omitempty"`
+}
+
+// HSTSPreloadMinMaxAge is the smallest max-age that browser HSTS
+// preload lists accept.
+const HSTSPreloadMinMaxAge = 365 * 24 * time.Hour
+
+// Validate the HSTS parameters.
+func (h HSTSParameters) Validate() error {
+	maxAge, err := time.ParseDuration(h.MaxAge)
+	if err != nil {
+		return fmt.Errorf("invalid HSTS max-age %q: %w", h.MaxAge, err)
+	}
+	if maxAge < 0 {
+		return fmt.Errorf("invalid HSTS max-age %q: must not be negative", h.MaxAge)
+	}
+	if h.Preload && (!h.IncludeSubDomains || maxAge < HSTSPreloadMinMaxAge) {
+		return fmt.Errorf("HSTS preload requires include-subdomains and a max-age of at least %s", HSTSPreloadMinMaxAge)
+	}
 	return nil
 }
 
@@ -470,12 +536,137 @@ type NetworkParameters struct {
 	XffNumTrustedHops uint32 `yaml:"num-trusted-hops"`
 }
 
+// ListenerProtocol is the protocol that an additional listener serves.
+type ListenerProtocol string
+
+const HTTPListenerProtocol ListenerProtocol = "http"
+const HTTPSListenerProtocol ListenerProtocol = "https"
+const TCPListenerProtocol ListenerProtocol = "tcp"
+const UDPListenerProtocol ListenerProtocol = "udp"
+
+func (p ListenerProtocol) Validate() error {
+	switch p {
+	case HTTPListenerProtocol, HTTPSListenerProtocol, TCPListenerProtocol, UDPListenerProtocol:
+		return nil
+	default:
+		return fmt.Errorf("invalid listener protocol %q", p)
+	}
+}
+
+// reservedListenerNames are the names of the listeners and route
+// configurations that Contour defines itself.
+var reservedListenerNames = map[string]bool{
+	"ingress_http":         true,
+	"ingress_https":        true,
+	"ingress_http3":        true,
+	"ingress_fallbackcert": true,
+	"https":                true,
+	"stats-health":         true,
+}
+
+// AdditionalListener defines a named Envoy listener that virtual
+// hosts can bind to besides the default HTTP and HTTPS listeners.
+type AdditionalListener struct {
+	// Name is the name of the listener, which HTTPProxies use to
+	// bind their virtual hosts to it.
+	Name string `yaml:"name"`
+
+	// Address is the address that the listener binds to.
+	// Defaults to 0.0.0.0.
+	Address string `yaml:"address,omitempty"`
+
+	// Port is the port that the listener binds to.
+	Port int `yaml:"port"`
+
+	// Protocol is the protocol that the listener serves, either
+	// "http", "https", "tcp" or "udp".
+	//
+	// A "tcp" listener proxies all of its connections to the
+	// TCPProxy of the single HTTPProxy bound to it, without TLS.
+	// A "udp" listener likewise forwards all of its datagrams to
+	// the UDPProxy of the single HTTPProxy bound to it.
+	Protocol ListenerProtocol `yaml:"protocol"`
+
+	// UseProxyProtocol configures the listener to expect a PROXY
+	// protocol V1 or V2 preamble. It cannot be used with "udp"
+	// listeners.
+	UseProxyProtocol bool `yaml:"use-proxy-protocol,omitempty"`
+}
+
+// Validate verifies that the additional listener is valid.
+func (l AdditionalListener) Validate() error {
+	if l.Name == "" || strings.Contains(l.Name, "/") {
+		return fmt.Errorf("invalid listener name %q", l.Name)
+	}
+
+	if reservedListenerNames[l.Name] {
+		return fmt.Errorf("listener name %q is reserved", l.Name)
+	}
+
+	if l.Port < 1 || l.Port > 65535 {
+		return fmt.Errorf("invalid port %d for listener %q", l.Port, l.Name)
+	}
+
+	if l.Protocol == UDPListenerProtocol && l.UseProxyProtocol {
+		return fmt.Errorf("listener %q cannot use the PROXY protocol over UDP", l.Name)
+	}
+
+	return l.Protocol.Validate()
+}
+
 // ListenerParameters hold various configurable listener values.
 type ListenerParameters struct {
 	// ConnectionBalancer. If the value is exact, the listener will use the exact connection balancer
 	// See https://www.envoyproxy.io/docs/envoy/latest/api-v2/api/v2/listener.proto#envoy-api-msg-listener-connectionbalanceconfig
 	// for more information.
 	ConnectionBalancer string `yaml:"connection-balancer"`
+
+	// HTTP3AdvertisedPort is the UDP port that clients reach
+	// the HTTP/3 listener on, which is advertised in the alt-svc
+	// header of HTTPS responses when HTTP/3 is enabled. It is
+	// usually the port of the Envoy Service rather than of the
+	// listener. Defaults to 443.
+	HTTP3AdvertisedPort int `yaml:"http3-advertised-port,omitempty"`
+
+	// Additional defines the named listeners that virtual hosts
+	// can bind to besides the default HTTP and HTTPS listeners.
+	Additional []AdditionalListener `yaml:"additional,omitempty"`
+}
+
+// Validate verifies that the listener parameters are valid.
+func (p ListenerParameters) Validate() error {
+	if p.HTTP3AdvertisedPort < 0 || p.HTTP3AdvertisedPort > 65535 {
+		return fmt.Errorf("invalid HTTP/3 advertised port %d", p.HTTP3AdvertisedPort)
+	}
+
+	names := map[string]bool{}
+	addresses := map[string]string{}
+	for _, l := range p.Additional {
+		if err := l.Validate(); err != nil {
+			return err
+		}
+
+		if names[l.Name] {
+			return fmt.Errorf("listener name %q is used by multiple listeners", l.Name)
+		}
+		names[l.Name] = true
+
+		host := l.Address
+		if host == "" {
+			host = "0.0.0.0"
+		}
+		address := net.JoinHostPort(host, strconv.Itoa(l.Port))
+		// UDP ports are distinct from TCP ports.
+		if l.Protocol == UDPListenerProtocol {
+			address += "/udp"
+		}
+		if other, ok := addresses[address]; ok {
+			return fmt.Errorf("listeners %q and %q both bind to %s", other, l.Name, address)
+		}
+		addresses[address] = l.Name
+	}
+
+	return nil
 }
 
 // Parameters contains the configuration file parameters for the
@@ -542,9 +733,11 @@ type Parameters struct {
 	// DefaultHTTPVersions defines the default set of HTTPS
 	// versions the proxy should accept. HTTP versions are
 	// strings of the form "HTTP/xx". Supported versions are
-	// "HTTP/1.1" and "HTTP/2".
+	// "HTTP/1.1", "HTTP/2" and "HTTP/3".
 	//
-	// If this field not specified, all supported versions are accepted.
+	// If this field not specified, HTTP/1.1 and HTTP/2 are accepted.
+	// HTTP/3 must be specified explicitly, together with HTTP/1.1 or
+	// HTTP/2, over which clients discover it.
 	DefaultHTTPVersions []HTTPVersionType `yaml:"default-http-versions"`
 
 	// Cluster holds various configurable Envoy cluster values that can
@@ -618,10 +811,20 @@ func (p *Parameters) Validate() error {
 		return err
 	}
 
+	versions := map[HTTPVersionType]bool{}
 	for _, v := range p.DefaultHTTPVersions {
 		if err := v.Validate(); err != nil {
 			return err
 		}
+		versions[v] = true
+	}
+
+	if versions[HTTPVersion3] && !versions[HTTPVersion1] && !versions[HTTPVersion2] {
+		return fmt.Errorf("HTTP/3 requires HTTP/1.1 or HTTP/2 to be accepted too")
+	}
+
+	if err := p.Listener.Validate(); err != nil {
+		return err
 	}
 
 	return nil
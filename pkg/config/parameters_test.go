@@ -145,6 +145,22 @@ func TestValidateHeadersPolicy(t *testing.T) {
 	}.Validate())
 }
 
+func TestValidateHSTSParameters(t *testing.T) {
+	assert.Error(t, HSTSParameters{}.Validate())
+	assert.Error(t, HSTSParameters{MaxAge: "1y"}.Validate())
+	assert.Error(t, HSTSParameters{MaxAge: "-1s"}.Validate())
+	assert.Error(t, HSTSParameters{MaxAge: "8760h", Preload: true}.Validate())
+	assert.Error(t, HSTSParameters{MaxAge: "24h", IncludeSubDomains: true, Preload: true}.Validate())
+
+	assert.NoError(t, HSTSParameters{MaxAge: "0s"}.Validate())
+	assert.NoError(t, HSTSParameters{MaxAge: "24h", IncludeSubDomains: true}.Validate())
+	assert.NoError(t, HSTSParameters{MaxAge: "8760h", IncludeSubDomains: true, Preload: true}.Validate())
+
+	assert.Error(t, PolicyParameters{
+		HSTSPolicy: &HSTSParameters{MaxAge: "forever"},
+	}.Validate())
+}
+
 func TestValidateNamespacedName(t *testing.T) {
 	assert.NoErrorf(t, NamespacedName{}.Validate(), "empty name should be OK")
 	assert.NoError(t, NamespacedName{Name: "name", Namespace: "ns"}.Validate())
@@ -233,6 +249,7 @@ func TestValidateHTTPVersionType(t *testing.T) {
 
 	assert.NoError(t, HTTPVersion1.Validate())
 	assert.NoError(t, HTTPVersion2.Validate())
+	assert.NoError(t, HTTPVersion3.Validate())
 }
 
 func TestValidateTimeoutParams(t *testing.T) {
@@ -292,6 +309,28 @@ func TestTLSParametersValidation(t *testing.T) {
 		},
 	}.Validate())
 
+	// Session ticket keys validation
+	assert.NoError(t, TLSParameters{
+		SessionTicketKeys: NamespacedName{
+			Name:      "ticket-keys",
+			Namespace: "projectcontour",
+		},
+	}.Validate())
+	assert.Error(t, TLSParameters{
+		SessionTicketKeys: NamespacedName{
+			Name:      "ticket-keys",
+			Namespace: "",
+		},
+	}.Validate())
+
+	// Certificate expiry warning validation
+	assert.NoError(t, TLSParameters{
+		CertificateExpiryWarning: 14 * 24 * time.Hour,
+	}.Validate())
+	assert.Error(t, TLSParameters{
+		CertificateExpiryWarning: -time.Hour,
+	}.Validate())
+
 	// Cipher suites validation
 	assert.NoError(t, TLSParameters{
 		CipherSuites: []string{},
@@ -404,6 +443,12 @@ tls:
 
 	check(`
 tls:
+  session-ticket-keys:
+    name: foo
+`)
+
+	check(`
+tls:
   cipher-suites:
   - NOTVALID
 `)
@@ -418,6 +463,71 @@ default-http-versions:
 - http/0.9
 `)
 
+	check(`
+default-http-versions:
+- http/3
+`)
+
+	check(`
+listener:
+  http3-advertised-port: 65536
+`)
+
+	check(`
+listener:
+  additional:
+  - name: ingress_https
+    port: 9443
+    protocol: https
+`)
+
+	check(`
+listener:
+  additional:
+  - name: internal
+    port: 0
+    protocol: http
+`)
+
+	check(`
+listener:
+  additional:
+  - name: internal
+    port: 9080
+    protocol: grpc
+`)
+
+	check(`
+listener:
+  additional:
+  - name: internal
+    port: 9080
+    protocol: http
+  - name: internal
+    port: 9443
+    protocol: https
+`)
+
+	check(`
+listener:
+  additional:
+  - name: internal
+    port: 9080
+    protocol: http
+  - name: admin
+    address: 0.0.0.0
+    port: 9080
+    protocol: http
+`)
+
+	check(`
+listener:
+  additional:
+  - name: dns
+    port: 5353
+    protocol: udp
+    use-proxy-protocol: true
+`)
 }
 
 func TestConfigFileDefaultOverrideImport(t *testing.T) {
@@ -468,6 +578,13 @@ tls:
   - ECDHE-RSA-AES256-GCM-SHA384
 `)
 
+	check(func(t *testing.T, conf *Parameters) {
+		assert.Equal(t, 336*time.Hour, conf.TLS.CertificateExpiryWarning)
+	}, `
+tls:
+  certificate-expiry-warning: 336h
+`)
+
 	check(func(t *testing.T, conf *Parameters) {
 		assert.Equal(t, "foo", conf.LeaderElection.Name)
 		assert.Equal(t, "bar", conf.LeaderElection.Namespace)
@@ -507,6 +624,66 @@ default-http-versions:
 - HTTP/1.1
 `)
 
+	check(func(t *testing.T, conf *Parameters) {
+		assert.ElementsMatch(t,
+			[]HTTPVersionType{HTTPVersion2, HTTPVersion3},
+			conf.DefaultHTTPVersions,
+		)
+		assert.Equal(t, 8443, conf.Listener.HTTP3AdvertisedPort)
+	}, `
+default-http-versions:
+- HTTP/2
+- HTTP/3
+listener:
+  http3-advertised-port: 8443
+`)
+
+	check(func(t *testing.T, conf *Parameters) {
+		assert.Equal(t, []AdditionalListener{{
+			Name:     "internal",
+			Address:  "127.0.0.1",
+			Port:     9080,
+			Protocol: HTTPListenerProtocol,
+		}, {
+			Name:             "public",
+			Port:             9443,
+			Protocol:         HTTPSListenerProtocol,
+			UseProxyProtocol: true,
+		}, {
+			Name:     "mqtt",
+			Port:     1883,
+			Protocol: TCPListenerProtocol,
+		}, {
+			Name:     "dns-tcp",
+			Port:     5353,
+			Protocol: TCPListenerProtocol,
+		}, {
+			Name:     "dns",
+			Port:     5353,
+			Protocol: UDPListenerProtocol,
+		}}, conf.Listener.Additional)
+	}, `
+listener:
+  additional:
+  - name: internal
+    address: 127.0.0.1
+    port: 9080
+    protocol: http
+  - name: public
+    port: 9443
+    protocol: https
+    use-proxy-protocol: true
+  - name: mqtt
+    port: 1883
+    protocol: tcp
+  - name: dns-tcp
+    port: 5353
+    protocol: tcp
+  - name: dns
+    port: 5353
+    protocol: udp
+`)
+
 	check(func(t *testing.T, conf *Parameters) {
 		assert.Equal(t, uint32(1), conf.Network.XffNumTrustedHops)
 	}, `